@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/haadi-coder/bookmark-manager/pkg/client"
+)
+
+// runAdd bookmarks the given URLs, or the ones read from stdin.
+func runAdd(ctx context.Context, c *client.Client, args []string) error {
+	var tags stringsFlag
+	var folder intFlag
+
+	flags := newFlagSet("add")
+	title := flags.String("title", "", "title of the bookmark (default: fetched from the page)")
+	flags.Var(&tags, "tag", "tag of the bookmark, may be repeated")
+	flags.Var(&folder, "folder", "id of the folder to put the bookmark in")
+	format := flags.String("format", formatTable, "output format, table, json or tsv")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	urls, err := inputs(flags.Args())
+	if err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		return usageError("expected a URL to add")
+	}
+	if *title != "" && len(urls) > 1 {
+		return usageError("--title takes a single URL")
+	}
+
+	p, err := newBookmarkPrinter(*format)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, url := range urls {
+		bookmark, err := c.Create(ctx, client.BookmarkInput{
+			URL:      url,
+			Title:    *title,
+			Tags:     tags,
+			FolderID: folder.value,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+
+			failed++
+			fmt.Fprintf(os.Stderr, "failed to add %s: %v\n", url, err)
+			continue
+		}
+
+		printBookmark(p, bookmark)
+	}
+
+	if err := p.flush(); err != nil {
+		return err
+	}
+
+	return batchError(failed, len(urls), "URLs failed")
+}
+
+// runList prints the bookmarks matching the filters, all pages of them.
+func runList(ctx context.Context, c *client.Client, args []string) error {
+	var tags stringsFlag
+	var folder intFlag
+
+	flags := newFlagSet("ls")
+	search := flags.String("search", "", "full-text search query")
+	flags.Var(&tags, "tag", "only bookmarks with this tag, may be repeated")
+	anyTag := flags.Bool("any", false, "match bookmarks with any of the tags instead of all")
+	status := flags.String("status", "", "only bookmarks whose link is ok, redirected or broken")
+	flags.Var(&folder, "folder", "only bookmarks in this folder")
+	limit := flags.Int("limit", 0, "print at most N bookmarks (default: all)")
+	format := flags.String("format", formatTable, "output format, table, json or tsv")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 0 {
+		return usageError("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	switch *status {
+	case "", client.LinkStatusOK, client.LinkStatusRedirected, client.LinkStatusBroken:
+	default:
+		return usageError("unknown status %q, expected ok, redirected or broken", *status)
+	}
+
+	p, err := newBookmarkPrinter(*format)
+	if err != nil {
+		return err
+	}
+
+	opts := client.ListOptions{
+		Search:     *search,
+		Tags:       tags,
+		AnyTag:     *anyTag,
+		FolderID:   folder.value,
+		LinkStatus: *status,
+	}
+	if *limit > 0 {
+		opts.PerPage = min(*limit, 500)
+	}
+
+	var printed int
+	for bookmark, err := range c.All(ctx, opts) {
+		if err != nil {
+			return err
+		}
+
+		printBookmark(p, bookmark)
+
+		printed++
+		if printed == *limit {
+			break
+		}
+	}
+
+	return p.flush()
+}
+
+// runRemove moves the given bookmarks, or the ones read from stdin, to the
+// trash.
+func runRemove(ctx context.Context, c *client.Client, args []string) error {
+	ids, err := inputs(args)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return usageError("expected a bookmark id to remove")
+	}
+
+	parsedIDs := make([]int, 0, len(ids))
+	for _, id := range ids {
+		parsedID, err := strconv.Atoi(id)
+		if err != nil {
+			return usageError("invalid bookmark id %q", id)
+		}
+		parsedIDs = append(parsedIDs, parsedID)
+	}
+
+	var failed int
+	for _, id := range parsedIDs {
+		if err := c.Delete(ctx, id); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+
+			failed++
+			fmt.Fprintf(os.Stderr, "failed to remove %d: %v\n", id, err)
+		}
+	}
+
+	return batchError(failed, len(parsedIDs), "bookmarks failed")
+}
+
+// runOpen opens a bookmark in the browser, found by id or as the best match
+// of a search.
+func runOpen(ctx context.Context, c *client.Client, args []string) error {
+	if len(args) == 0 {
+		return usageError("expected a bookmark id or a search query")
+	}
+	query := strings.Join(args, " ")
+
+	var bookmark *client.Bookmark
+	if id, err := strconv.Atoi(query); err == nil {
+		// There is no lookup by id, bookmarks are walked instead.
+		for b, err := range c.All(ctx, client.ListOptions{PerPage: 500}) {
+			if err != nil {
+				return err
+			}
+			if b.ID == id {
+				bookmark = b
+				break
+			}
+		}
+	} else {
+		page, err := c.List(ctx, client.ListOptions{Search: query, PerPage: 1, SkipCount: true})
+		if err != nil {
+			return err
+		}
+		if len(page.Bookmarks) > 0 {
+			bookmark = page.Bookmarks[0]
+		}
+	}
+
+	if bookmark == nil {
+		return fmt.Errorf("no bookmark matches %q", query)
+	}
+
+	fmt.Println(bookmark.URL)
+
+	return openBrowser(bookmark.URL)
+}
+
+// runExists tells whether the given URLs, or the ones read from stdin, are
+// bookmarked.
+func runExists(ctx context.Context, c *client.Client, args []string) error {
+	flags := newFlagSet("exists")
+	format := flags.String("format", formatTable, "output format, table, json or tsv")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	urls, err := inputs(flags.Args())
+	if err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		return usageError("expected a URL to look up")
+	}
+
+	p, err := newPrinter(*format, os.Stdout, "URL", "ID", "FOUND")
+	if err != nil {
+		return err
+	}
+
+	type result struct {
+		URL   string `json:"url"`
+		ID    int    `json:"id,omitempty"`
+		Found bool   `json:"found"`
+	}
+
+	var missing int
+	for _, url := range urls {
+		id, found, err := c.Exists(ctx, url)
+		if err != nil {
+			return err
+		}
+
+		idField := ""
+		if found {
+			idField = strconv.Itoa(id)
+		} else {
+			missing++
+		}
+
+		p.print(result{URL: url, ID: id, Found: found}, url, idField, strconv.FormatBool(found))
+	}
+
+	if err := p.flush(); err != nil {
+		return err
+	}
+
+	return batchError(missing, len(urls), "URLs aren't bookmarked")
+}
+
+// runExport writes all bookmarks as a Netscape HTML file.
+func runExport(ctx context.Context, c *client.Client, args []string) error {
+	flags := newFlagSet("export")
+	output := flags.String("output", "", "output file (default: stdout)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 0 {
+		return usageError("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	body, err := c.Export(ctx)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	if _, err := io.Copy(w, body); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return nil
+}
+
+func batchError(failed, total int, what string) error {
+	if failed == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %d of %d %s", errPartial, failed, total, what)
+}
+
+// openBrowser opens url with $BROWSER, or the opener of the platform.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+
+	switch browser := os.Getenv("BROWSER"); {
+	case browser != "":
+		cmd = exec.Command(browser, url)
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("open", url)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("no browser found, set BROWSER: %w", err)
+		}
+
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+
+	return cmd.Process.Release()
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// config is read from a YAML file, environment variables take precedence.
+//
+//	server: https://bookmarks.example.com
+//	token: bm_...
+type config struct {
+	Server string `yaml:"server" env:"BM_SERVER" env-default:"http://localhost:8080"`
+
+	// Token is an API token. Username and Password are used instead when
+	// it's empty.
+	Token    string `yaml:"token" env:"BM_TOKEN"`
+	Username string `yaml:"username" env:"BM_USERNAME"`
+	Password string `yaml:"password" env:"BM_PASSWORD"`
+}
+
+// configPath returns the config file named by BM_CONFIG, or bm/config.yaml
+// in the user config directory.
+func configPath() (string, error) {
+	if path := os.Getenv("BM_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "bm", "config.yaml"), nil
+}
+
+func configHint() string {
+	path, err := configPath()
+	if err != nil {
+		return "bm/config.yaml in the user config directory"
+	}
+
+	return path
+}
+
+func loadConfig() (*config, error) {
+	var cfg config
+
+	path, err := configPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find config file: %w", err)
+	}
+
+	// The file is optional, the environment may hold all settings.
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) && os.Getenv("BM_CONFIG") == "" {
+		if err := cleanenv.ReadEnv(&cfg); err != nil {
+			return nil, err
+		}
+
+		return &cfg, nil
+	}
+
+	if err := cleanenv.ReadConfig(path, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	const file = "server: https://file.example.com\ntoken: bm_file\nusername: file-user\n"
+
+	tests := []struct {
+		name string
+		// file is written to BM_CONFIG when set, env is set on top.
+		file string
+		env  map[string]string
+		want config
+	}{
+		{
+			name: "file",
+			file: file,
+			want: config{Server: "https://file.example.com", Token: "bm_file", Username: "file-user"},
+		},
+		{
+			name: "environment over file",
+			file: file,
+			env:  map[string]string{"BM_SERVER": "https://env.example.com", "BM_TOKEN": "bm_env"},
+			want: config{Server: "https://env.example.com", Token: "bm_env", Username: "file-user"},
+		},
+		{
+			name: "environment without file",
+			env:  map[string]string{"BM_TOKEN": "bm_env", "BM_PASSWORD": "secret"},
+			want: config{Server: "http://localhost:8080", Token: "bm_env", Password: "secret"},
+		},
+		{
+			name: "defaults",
+			want: config{Server: "http://localhost:8080"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigEnv(t)
+
+			if tt.file != "" {
+				path := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatalf("failed to write config: %v", err)
+				}
+				t.Setenv("BM_CONFIG", path)
+			}
+
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				t.Fatalf("loadConfig() error = %v", err)
+			}
+			if *cfg != tt.want {
+				t.Errorf("loadConfig() = %+v, want %+v", *cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigDefaultPath(t *testing.T) {
+	setConfigEnv(t)
+
+	path, err := configPath()
+	if err != nil {
+		t.Fatalf("configPath() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("server: https://default.example.com\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.Server != "https://default.example.com" {
+		t.Errorf("loadConfig() server = %q, want the one of the file in the config dir", cfg.Server)
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	setConfigEnv(t)
+	t.Setenv("BM_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+
+	// A file named explicitly has to exist.
+	if _, err := loadConfig(); err == nil {
+		t.Error("loadConfig() error = nil, want an error for the missing BM_CONFIG file")
+	}
+}
+
+// setConfigEnv points the user config directory to an empty directory and
+// unsets the BM_* variables for the test.
+func setConfigEnv(t *testing.T) {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, ".config"))
+
+	for _, key := range []string{"BM_CONFIG", "BM_SERVER", "BM_TOKEN", "BM_USERNAME", "BM_PASSWORD"} {
+		t.Setenv(key, "")
+		if err := os.Unsetenv(key); err != nil {
+			t.Fatalf("failed to unset %s: %v", key, err)
+		}
+	}
+}
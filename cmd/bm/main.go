@@ -0,0 +1,129 @@
+// Command bm is a terminal client for the bookmark-manager API.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/haadi-coder/bookmark-manager/pkg/client"
+)
+
+// Exit codes, the same as the ones of the server binary. A partial failure
+// is a batch where some URLs or ids failed, or weren't bookmarked.
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+	exitPartial = 3
+)
+
+var (
+	errUsage   = errors.New("invalid usage")
+	errPartial = errors.New("partial failure")
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, c *client.Client, args []string) error
+}
+
+var commands = []command{
+	{"add", "add [--title TITLE] [--tag TAG]... [--folder ID] [--format table|json|tsv] [URL...]", runAdd},
+	{"ls", "ls [--search QUERY] [--tag TAG]... [--any] [--status ok|redirected|broken] [--folder ID] [--limit N] [--format table|json|tsv]", runList},
+	{"rm", "rm [ID...]", runRemove},
+	{"open", "open ID | QUERY", runOpen},
+	{"exists", "exists [--format table|json|tsv] [URL...]", runExists},
+	{"export", "export [--output FILE]", runExport},
+}
+
+func main() {
+	os.Exit(execute(os.Args[1:]))
+}
+
+// execute runs the command named by the first argument and returns the exit
+// code.
+func execute(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+	name, args := args[0], args[1:]
+
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage(os.Stdout)
+		return exitOK
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bm: failed to load config: %v\n", err)
+		return exitFailure
+	}
+
+	c, err := client.New(client.Config{
+		BaseURL:  cfg.Server,
+		Token:    cfg.Token,
+		Username: cfg.Username,
+		Password: cfg.Password,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bm: %v\n", err)
+		return exitFailure
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	err = cmd.run(ctx, c, args)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(os.Stderr, "%v\nusage: bm %s\n", err, cmd.usage)
+		return exitUsage
+	case errors.Is(err, errPartial):
+		fmt.Fprintf(os.Stderr, "bm %s: %v\n", cmd.name, err)
+		return exitPartial
+	default:
+		fmt.Fprintf(os.Stderr, "bm %s: %v\n", cmd.name, err)
+		return exitFailure
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: bm <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\n", cmd.usage)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "URLs and ids are read from stdin, one per line, when none are given.")
+	fmt.Fprintf(w, "The server and credentials are read from %s, or from BM_SERVER, BM_TOKEN,\n", configHint())
+	fmt.Fprintln(w, "BM_USERNAME and BM_PASSWORD. BM_CONFIG points at another config file.")
+}
+
+func usageError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
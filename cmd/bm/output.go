@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/haadi-coder/bookmark-manager/pkg/client"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatTSV   = "tsv"
+)
+
+// printer writes rows as an aligned table, tab separated values or a JSON
+// array of the values behind the rows.
+type printer struct {
+	format string
+	w      io.Writer
+	table  *tabwriter.Writer
+	values []any
+}
+
+func newPrinter(format string, w io.Writer, header ...string) (*printer, error) {
+	p := &printer{format: format, w: w}
+
+	switch format {
+	case formatTable:
+		p.table = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(p.table, strings.Join(header, "\t"))
+	case formatJSON, formatTSV:
+	default:
+		return nil, usageError("unknown format %q, expected table, json or tsv", format)
+	}
+
+	return p, nil
+}
+
+func (p *printer) print(value any, row ...string) {
+	switch p.format {
+	case formatTable:
+		fmt.Fprintln(p.table, strings.Join(row, "\t"))
+	case formatTSV:
+		for i, field := range row {
+			// Fields must not break the columns.
+			row[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(field)
+		}
+		fmt.Fprintln(p.w, strings.Join(row, "\t"))
+	case formatJSON:
+		p.values = append(p.values, value)
+	}
+}
+
+func (p *printer) flush() error {
+	switch p.format {
+	case formatTable:
+		return p.table.Flush()
+	case formatJSON:
+		if p.values == nil {
+			p.values = []any{}
+		}
+
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(p.values)
+	}
+
+	return nil
+}
+
+func printBookmark(p *printer, b *client.Bookmark) {
+	p.print(b, strconv.Itoa(b.ID), b.Title, b.URL, strings.Join(b.Tags, ","))
+}
+
+func newBookmarkPrinter(format string) (*printer, error) {
+	return newPrinter(format, os.Stdout, "ID", "TITLE", "URL", "TAGS")
+}
+
+// inputs returns the arguments, or the lines of stdin when there are none.
+// Blank lines are skipped.
+func inputs(args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+
+	var lines []string
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	return lines, nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	return flags
+}
+
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return usageError("%v", err)
+	}
+
+	return nil
+}
+
+// stringsFlag is a flag that may be given several times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// intFlag is an optional integer flag, nil until given.
+type intFlag struct {
+	value *int
+}
+
+func (f *intFlag) String() string {
+	if f.value == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*f.value)
+}
+
+func (f *intFlag) Set(value string) error {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("expected a number, got: %q", value)
+	}
+	f.value = &v
+
+	return nil
+}
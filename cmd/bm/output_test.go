@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestInputs(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+		want  []string
+	}{
+		{
+			name:  "arguments",
+			args:  []string{"https://go.dev", "https://example.com"},
+			stdin: "https://ignored.example.com\n",
+			want:  []string{"https://go.dev", "https://example.com"},
+		},
+		{
+			name:  "stdin",
+			stdin: "https://go.dev\n\n   \n  https://example.com  \n",
+			want:  []string{"https://go.dev", "https://example.com"},
+		},
+		{
+			name:  "stdin without trailing newline",
+			stdin: "1\n2",
+			want:  []string{"1", "2"},
+		},
+		{
+			name: "empty stdin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setStdin(t, tt.stdin)
+
+			got, err := inputs(tt.args)
+			if err != nil {
+				t.Fatalf("inputs() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("inputs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	type row struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: formatTable,
+			want:   "ID  NAME\n1   Go\n22  Rust\n",
+		},
+		{
+			format: formatTSV,
+			want:   "1\tGo\n22\tRust\n",
+		},
+		{
+			format: formatJSON,
+			want:   "[\n  {\n    \"id\": 1,\n    \"name\": \"Go\"\n  },\n  {\n    \"id\": 22,\n    \"name\": \"Rust\"\n  }\n]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			p, err := newPrinter(tt.format, &buf, "ID", "NAME")
+			if err != nil {
+				t.Fatalf("newPrinter() error = %v", err)
+			}
+
+			p.print(row{ID: 1, Name: "Go"}, "1", "Go")
+			p.print(row{ID: 22, Name: "Rust"}, "22", "Rust")
+
+			if err := p.flush(); err != nil {
+				t.Fatalf("flush() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPrinterTSVFields(t *testing.T) {
+	var buf bytes.Buffer
+
+	p, err := newPrinter(formatTSV, &buf)
+	if err != nil {
+		t.Fatalf("newPrinter() error = %v", err)
+	}
+
+	// Tabs and newlines in fields would break the columns.
+	p.print(nil, "1", "Rust\tby\nexample")
+	if err := p.flush(); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+
+	if want := "1\tRust by example\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrinterEmptyJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	p, err := newPrinter(formatJSON, &buf)
+	if err != nil {
+		t.Fatalf("newPrinter() error = %v", err)
+	}
+	if err := p.flush(); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+
+	if buf.String() != "[]\n" {
+		t.Errorf("output = %q, want an empty array", buf.String())
+	}
+}
+
+func TestPrinterUnknownFormat(t *testing.T) {
+	_, err := newPrinter("xml", &bytes.Buffer{})
+	if !errors.Is(err, errUsage) {
+		t.Errorf("newPrinter() error = %v, want a usage error", err)
+	}
+}
+
+// setStdin replaces os.Stdin with a file holding content for the test.
+func setStdin(t *testing.T, content string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write stdin: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open stdin: %v", err)
+	}
+
+	stdin := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		_ = f.Close()
+	})
+}
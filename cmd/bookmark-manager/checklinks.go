@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/haadi-coder/bookmark-manager/internal/config"
+	"github.com/haadi-coder/bookmark-manager/internal/linkcheck"
+)
+
+// runCheckLinks runs a single link checking round, for deployments that
+// schedule it instead of running the checker in the server.
+func runCheckLinks(ctx context.Context, cfg *config.Config, args []string) error {
+	linkCfg := linkCheckConfig(cfg)
+
+	flags := newFlagSet("check-links")
+	flags.IntVar(&linkCfg.BatchSize, "batch", linkCfg.BatchSize, "maximum number of bookmarks to check")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 0 {
+		return usageError("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	if linkCfg.BatchSize < 1 {
+		return usageError("batch must be positive")
+	}
+
+	store, err := newStorage(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init storage: %w", err)
+	}
+	defer closeStorage(store)
+
+	checked, err := linkcheck.NewChecker(linkCfg, store).CheckStale(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check links: %w", err)
+	}
+
+	slog.Info("links sucessfully checked", slog.Int("bookmarks_count", checked))
+
+	return nil
+}
+
+func linkCheckConfig(cfg *config.Config) linkcheck.Config {
+	return linkcheck.Config{
+		Interval:    cfg.LinkCheck.Interval,
+		MaxAge:      cfg.LinkCheck.MaxAge,
+		BatchSize:   cfg.LinkCheck.BatchSize,
+		Concurrency: cfg.LinkCheck.Concurrency,
+		HostDelay:   cfg.LinkCheck.HostDelay,
+		Timeout:     cfg.LinkCheck.Timeout,
+	}
+}
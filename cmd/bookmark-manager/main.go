@@ -2,85 +2,200 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"github.com/haadi-coder/bookmark-manager/internal/api"
 	"github.com/haadi-coder/bookmark-manager/internal/config"
 	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
 	"github.com/haadi-coder/bookmark-manager/internal/storage"
+	"github.com/haadi-coder/bookmark-manager/internal/storage/memory"
+	"github.com/haadi-coder/bookmark-manager/internal/storage/sqlite"
+	"github.com/haadi-coder/bookmark-manager/internal/tracing"
 	"github.com/lmittmann/tint"
 )
 
+// Exit codes, so that cron jobs and CI can tell a bad invocation and a
+// partially failed import apart from other failures.
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+	exitPartial = 3
+)
+
+var (
+	errUsage   = errors.New("invalid usage")
+	errPartial = errors.New("some bookmarks failed")
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, cfg *config.Config, args []string) error
+}
+
+var commands = []command{
+	{"serve", "serve", runServe},
+	{"migrate", "migrate up | down [N] | goto N | status", runMigrate},
+	{"import", "import --user NAME [--format html|json] FILE", runImport},
+	{"export", "export --user NAME [--format html|json] [--output FILE]", runExport},
+	{"backup", "backup [--output FILE]", runBackup},
+	{"restore", "restore FILE", runRestore},
+	{"check-links", "check-links [--batch N]", runCheckLinks},
+}
+
 func main() {
+	os.Exit(execute(os.Args[1:]))
+}
+
+// execute runs the command named by the first argument, serve by default,
+// and returns the exit code.
+func execute(args []string) int {
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage(os.Stdout)
+		return exitOK
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
-		os.Exit(1)
+		return exitFailure
 	}
 
-	setupLogger(cfg)
+	// Only the server logs to stdout, other commands keep it free for
+	// their output.
+	logOutput := os.Stderr
+	if cmd.name == "serve" {
+		logOutput = os.Stdout
+	}
+	setupLogger(cfg, logOutput)
 
-	if err := run(cfg); err != nil {
-		slog.Error("failed to start bookmark-manager", logger.Error(err))
-		os.Exit(1)
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	err = cmd.run(ctx, cfg, args)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(os.Stderr, "%v\nusage: bookmark-manager %s\n", err, cmd.usage)
+		return exitUsage
+	case errors.Is(err, errPartial):
+		slog.Warn("bookmark-manager "+cmd.name+" finished with failures", logger.Error(err))
+		return exitPartial
+	default:
+		slog.Error("bookmark-manager "+cmd.name+" failed", logger.Error(err))
+		return exitFailure
 	}
 }
 
-func run(cfg *config.Config) error {
-	slog.Info("starting bookmark-manager")
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: bookmark-manager <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
 
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer cancel()
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\n", cmd.usage)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Without a command the server is started. Configuration is read from BM_* environment variables.")
+}
+
+func usageError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+// newStorage opens the storage backend selected in the config.
+func newStorage(ctx context.Context, cfg *config.Config) (storage.Storage, error) {
+	switch cfg.Storage.Backend {
+	case storage.BackendSQLite:
+		slog.Info("using sqlite storage", slog.String("path", cfg.Storage.SQLitePath))
+
+		store, err := sqlite.New(cfg.Storage.SQLitePath)
+		if err != nil {
+			return nil, err
+		}
 
-	storage, err := storage.New(cfg.DB.DSN())
+		return store, nil
+	case storage.BackendMemory:
+		slog.Warn("using in-memory storage, data is lost on restart")
+
+		return memory.New(), nil
+	}
+
+	store, err := storage.New(cfg.DB.DSN())
 	if err != nil {
-		return fmt.Errorf("failed to init storage: %w", err)
+		return nil, err
 	}
 
-	defer func() {
-		if closeErr := storage.Close(); closeErr != nil {
-			slog.Error("failed to close database connection", logger.Error(closeErr))
+	if cfg.DB.AutoMigrate {
+		if _, err := store.MigrateUp(ctx); err != nil {
+			_ = store.Close()
+			return nil, fmt.Errorf("failed to migrate schema: %w", err)
 		}
-	}()
-
-	srv := api.NewServer(ctx, &api.ServerConfig{
-		Address:     cfg.HTTP.Address(),
-		Timeout:     cfg.HTTP.Timeout,
-		IdleTimeout: cfg.HTTP.IdleTimeout,
-
-		BookmarkProvider: storage,
-		BookmarkChecker:  storage,
-		BookmarkDeleter:  storage,
-		BookmarkPinger:   storage,
-		BookmarkEditor:   storage,
-		BookmarkCreator:  storage,
-	})
-
-	if err := srv.Run(ctx); err != nil {
-		return fmt.Errorf("failed to run server: %w", err)
 	}
 
-	return nil
+	if err := store.ConfigureSearch(ctx, cfg.Search.Config); err != nil {
+		_ = store.Close()
+		return nil, fmt.Errorf("failed to configure search: %w", err)
+	}
+
+	return store, nil
 }
 
-func setupLogger(cfg *config.Config) {
+// logLevels are the levels of the default logger, the admin listener of the
+// server changes them at runtime.
+var logLevels *logger.Levels
+
+func setupLogger(cfg *config.Config, w io.Writer) {
 	level := slog.LevelInfo
 	if cfg.Debug {
 		level = slog.LevelDebug
 	}
+	if cfg.Log.Level != "" {
+		// Validated with the config.
+		_ = level.UnmarshalText([]byte(cfg.Log.Level))
+	}
+
+	packages, _ := logger.ParseLevels(cfg.Log.Packages)
+	logLevels = logger.NewLevels(level, packages)
 
-	logger := slog.New(tint.NewHandler(
-		os.Stdout,
-		&tint.Options{
-			Level:      level,
+	// Records are filtered by the level handler on top.
+	var h slog.Handler
+	switch cfg.Log.Format {
+	case "json":
+		h = slog.NewJSONHandler(w, nil)
+	default:
+		h = tint.NewHandler(w, &tint.Options{
 			TimeFormat: time.Kitchen,
 			NoColor:    cfg.NoColor,
-		},
-	))
-	slog.SetDefault(logger)
+		})
+	}
+
+	slog.SetDefault(slog.New(logger.NewLevelHandler(tracing.NewLogHandler(h), logLevels)))
 }
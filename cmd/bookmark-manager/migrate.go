@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/haadi-coder/bookmark-manager/internal/config"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+// runMigrate applies the embedded migrations to the Postgres database.
+func runMigrate(ctx context.Context, cfg *config.Config, args []string) error {
+	if cfg.Storage.Backend != storage.BackendPostgres {
+		return fmt.Errorf("migrations only apply to postgres storage, got %s", cfg.Storage.Backend)
+	}
+
+	if len(args) == 0 {
+		return usageError("missing migrate direction")
+	}
+
+	store, err := storage.New(cfg.DB.DSN())
+	if err != nil {
+		return fmt.Errorf("failed to init storage: %w", err)
+	}
+
+	defer func() {
+		if closeErr := store.Close(); closeErr != nil {
+			slog.Error("failed to close database connection", logger.Error(closeErr))
+		}
+	}()
+
+	var applied int
+
+	switch args[0] {
+	case "up":
+		applied, err = store.MigrateUp(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return usageError("invalid number of steps: %s", args[1])
+			}
+		}
+
+		applied, err = store.MigrateDown(ctx, steps)
+	case "goto":
+		if len(args) < 2 {
+			return usageError("missing version")
+		}
+
+		version, parseErr := strconv.ParseUint(args[1], 10, 0)
+		if parseErr != nil {
+			return usageError("invalid version: %s", args[1])
+		}
+
+		applied, err = store.MigrateTo(ctx, uint(version))
+	case "status":
+	default:
+		return usageError("unknown migrate direction %q", args[0])
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if args[0] != "status" {
+		slog.Info("schema migrated", slog.Int("migrations", applied))
+	}
+
+	status, err := store.SchemaStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("version: %d, latest: %d, dirty: %t\n", status.Version, status.Latest, status.Dirty)
+	for _, m := range status.Pending {
+		fmt.Printf("pending: %d_%s\n", m.Version, m.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/api"
+	"github.com/haadi-coder/bookmark-manager/internal/api/handler"
+	"github.com/haadi-coder/bookmark-manager/internal/api/openapi"
+	"github.com/haadi-coder/bookmark-manager/internal/config"
+	"github.com/haadi-coder/bookmark-manager/internal/events"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/linkcheck"
+	"github.com/haadi-coder/bookmark-manager/internal/metadata"
+	"github.com/haadi-coder/bookmark-manager/internal/metrics"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+	"github.com/haadi-coder/bookmark-manager/internal/tracing"
+	"github.com/haadi-coder/bookmark-manager/internal/trash"
+	"github.com/haadi-coder/bookmark-manager/internal/webhook"
+)
+
+// runServe starts the API server together with the background workers.
+func runServe(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) > 0 {
+		return usageError("serve takes no arguments")
+	}
+
+	slog.Info("starting bookmark-manager")
+
+	shutdownTracing, err := tracing.Setup(ctx, tracingConfig(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to init tracing: %w", err)
+	}
+
+	defer func() {
+		// The serve context is cancelled by now, flushing needs one of its own.
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Error("failed to flush traces", logger.Error(err))
+		}
+	}()
+
+	store, err := newStorage(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init storage: %w", err)
+	}
+
+	defer func() {
+		if closeErr := store.Close(); closeErr != nil {
+			slog.Error("failed to close database connection", logger.Error(closeErr))
+		}
+	}()
+
+	// Only Postgres is migrated, the other backends create their schema
+	// themselves.
+	var schemaChecker handler.SchemaChecker
+	if pg, ok := store.(*storage.PostgresStorage); ok {
+		schemaChecker = pg
+	}
+
+	var m *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		m = metrics.New()
+		m.RegisterStats(store)
+
+		if provider, ok := store.(metrics.DBStatsProvider); ok {
+			m.RegisterDBStats(provider)
+		}
+
+		store = m.WrapStorage(store)
+	}
+
+	if address := cfg.Admin.Address(); address != "" {
+		admin := api.NewAdminServer(&api.AdminConfig{
+			Address:   address,
+			Metrics:   m,
+			LogLevels: logLevels,
+		})
+
+		go func() {
+			if err := admin.Run(ctx); err != nil {
+				slog.Error("failed to run admin server", logger.Error(err))
+			}
+		}()
+	}
+
+	tracingEnabled := cfg.Tracing.Exporter != tracing.ExporterNone
+	if tracingEnabled {
+		store = tracing.WrapStorage(store)
+	}
+
+	metadataWorker := metadata.NewWorker(
+		metadata.NewFetcher(cfg.Metadata.Timeout),
+		store,
+		cfg.Metadata.Workers,
+		cfg.Metadata.QueueSize,
+	)
+	go metadataWorker.Run(ctx)
+
+	if cfg.LinkCheck.Enabled {
+		checker := linkcheck.NewChecker(linkCheckConfig(cfg), store)
+		go checker.Run(ctx)
+	}
+
+	go trash.NewPurger(store, cfg.Trash.PurgeInterval, cfg.Trash.Retention).Run(ctx)
+
+	go webhook.NewWorker(webhook.Config{
+		PollInterval: cfg.Webhook.PollInterval,
+		Concurrency:  cfg.Webhook.Concurrency,
+		MaxAttempts:  cfg.Webhook.MaxAttempts,
+		Backoff:      cfg.Webhook.Backoff,
+		MaxBackoff:   cfg.Webhook.MaxBackoff,
+		Timeout:      cfg.Webhook.Timeout,
+	}, store).Run(ctx)
+
+	broker := events.NewBroker()
+	go func() {
+		if err := store.ListenEvents(ctx, broker.Notify); err != nil {
+			slog.Error("failed to listen to events", logger.Error(err))
+		}
+	}()
+
+	var requestValidator handler.RequestValidator
+	if cfg.HTTP.ValidateRequests {
+		v, err := openapi.NewValidator()
+		if err != nil {
+			return fmt.Errorf("failed to init request validation: %w", err)
+		}
+		requestValidator = v
+	}
+
+	srv := api.NewServer(&api.ServerConfig{
+		Address:     cfg.HTTP.Address(),
+		Timeout:     cfg.HTTP.Timeout,
+		IdleTimeout: cfg.HTTP.IdleTimeout,
+
+		RequestTimeout:  cfg.HTTP.RequestTimeout,
+		TransferTimeout: cfg.HTTP.TransferTimeout,
+
+		Metrics:      m,
+		ServeMetrics: cfg.Admin.Address() == "",
+		Tracing:      tracingEnabled,
+
+		RequestValidator: requestValidator,
+
+		BookmarkProvider: store,
+		BookmarkChecker:  store,
+		BookmarkDeleter:  store,
+		BookmarkPinger:   store,
+		SchemaChecker:    schemaChecker,
+		BookmarkEditor:   store,
+		BookmarkCreator:  store,
+		BookmarkImporter: store,
+		TagProvider:      store,
+
+		FolderProvider: store,
+		FolderCreator:  store,
+		FolderEditor:   store,
+		FolderDeleter:  store,
+		BookmarkMover:  store,
+
+		UserProvider: store,
+		UserCreator:  store,
+		AllowSignup:  cfg.Auth.AllowSignup,
+
+		TokenAuthenticator: store,
+		TokenProvider:      store,
+		TokenCreator:       store,
+		TokenRevoker:       store,
+
+		MetadataQueue:     metadataWorker,
+		BookmarkRefresher: metadataWorker,
+
+		LinkReporter: store,
+
+		TrashProvider:    store,
+		BookmarkRestorer: store,
+		BookmarkPurger:   store,
+
+		HistoryProvider:  store,
+		BookmarkReverter: store,
+
+		WebhookProvider:  store,
+		WebhookCreator:   store,
+		WebhookDeleter:   store,
+		DeliveryProvider: store,
+		DeliveryReplayer: store,
+
+		EventProvider:   store,
+		EventSubscriber: broker,
+
+		ChangeProvider: store,
+		BookmarkSyncer: store,
+	})
+
+	if err := srv.Run(ctx); err != nil {
+		return fmt.Errorf("failed to run server: %w", err)
+	}
+
+	return nil
+}
+
+func tracingConfig(cfg *config.Config) tracing.Config {
+	return tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: cfg.Tracing.ServiceName,
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/haadi-coder/bookmark-manager/internal/config"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+	"github.com/haadi-coder/bookmark-manager/internal/transfer"
+)
+
+const (
+	formatHTML = "html"
+	formatJSON = "json"
+
+	// progressStep is how many bookmarks are handled between two progress
+	// lines.
+	progressStep = 100
+)
+
+// runImport imports a Netscape HTML or JSON export for a user.
+func runImport(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := newFlagSet("import")
+	username := flags.String("user", "", "user to import the bookmarks for")
+	format := flags.String("format", "", "file format, html or json (default: by file extension)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageError("expected a single file to import")
+	}
+	path := flags.Arg(0)
+
+	if *format == "" {
+		*format = formatHTML
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			*format = formatJSON
+		}
+	}
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read import file: %w", err)
+	}
+
+	store, err := newStorage(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init storage: %w", err)
+	}
+	defer closeStorage(store)
+
+	userCtx, err := userContext(ctx, store, *username)
+	if err != nil {
+		return err
+	}
+
+	var report *transfer.Report
+
+	switch *format {
+	case formatJSON:
+		var doc transfer.Document
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return fmt.Errorf("failed to parse json export: %w", err)
+		}
+
+		report = transfer.ImportDocument(userCtx, store, &doc, logProgress("import"))
+	default:
+		report, err = transfer.ImportNetscape(userCtx, store, content, logProgress("import"))
+		if err != nil {
+			return err
+		}
+	}
+
+	return checkReport("bookmarks imported", report)
+}
+
+// runExport writes the bookmarks of a user as Netscape HTML or JSON.
+func runExport(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := newFlagSet("export")
+	username := flags.String("user", "", "user to export the bookmarks of")
+	format := flags.String("format", formatHTML, "output format, html or json")
+	output := flags.String("output", "", "output file (default: stdout)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 0 {
+		return usageError("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	store, err := newStorage(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init storage: %w", err)
+	}
+	defer closeStorage(store)
+
+	userCtx, err := userContext(ctx, store, *username)
+	if err != nil {
+		return err
+	}
+
+	doc, err := transfer.Export(userCtx, store)
+	if err != nil {
+		return fmt.Errorf("failed to export bookmarks: %w", err)
+	}
+
+	var content []byte
+	switch *format {
+	case formatJSON:
+		content, err = marshalJSON(doc)
+	default:
+		content, err = transfer.MarshalNetscape(doc)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode export: %w", err)
+	}
+
+	if err := writeOutput(*output, content); err != nil {
+		return err
+	}
+
+	slog.Info("bookmarks sucessfully exported",
+		slog.Int("bookmarks_count", len(doc.Bookmarks)),
+		slog.Int("folders_count", len(doc.Folders)))
+
+	return nil
+}
+
+// runBackup writes the accounts, folders and bookmarks of all users as JSON.
+func runBackup(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := newFlagSet("backup")
+	output := flags.String("output", "", "output file (default: stdout)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 0 {
+		return usageError("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	store, err := newStorage(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init storage: %w", err)
+	}
+	defer closeStorage(store)
+
+	backup, err := transfer.CreateBackup(ctx, store, func(done, total int) {
+		slog.Info("backup progress", slog.Int("users", done), slog.Int("total", total))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	content, err := marshalJSON(backup)
+	if err != nil {
+		return fmt.Errorf("failed to encode backup: %w", err)
+	}
+
+	if err := writeOutput(*output, content); err != nil {
+		return err
+	}
+
+	slog.Info("backup sucessfully created", slog.Int("users_count", len(backup.Users)))
+
+	return nil
+}
+
+// runRestore loads a backup written by runBackup.
+func runRestore(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := newFlagSet("restore")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageError("expected a single backup file")
+	}
+
+	content, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to read backup file: %w", err)
+	}
+
+	var backup transfer.Backup
+	if err := json.Unmarshal(content, &backup); err != nil {
+		return fmt.Errorf("failed to parse backup: %w", err)
+	}
+
+	store, err := newStorage(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init storage: %w", err)
+	}
+	defer closeStorage(store)
+
+	report, err := transfer.RestoreBackup(ctx, store, &backup, logProgress("restore"))
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	return checkReport("backup restored", report)
+}
+
+func marshalJSON(v any) ([]byte, error) {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(content, '\n'), nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	return flags
+}
+
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return usageError("%v", err)
+	}
+
+	return nil
+}
+
+func checkFormat(format string) error {
+	if format != formatHTML && format != formatJSON {
+		return usageError("unknown format %q, expected html or json", format)
+	}
+
+	return nil
+}
+
+func closeStorage(store storage.Storage) {
+	if err := store.Close(); err != nil {
+		slog.Error("failed to close database connection", logger.Error(err))
+	}
+}
+
+// userContext returns ctx carrying the user with the given name, as the
+// storage scopes bookmarks by the authenticated user.
+func userContext(ctx context.Context, store storage.Storage, username string) (context.Context, error) {
+	if username == "" {
+		return nil, usageError("--user is required")
+	}
+
+	user, err := store.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user %s: %w", username, err)
+	}
+
+	return auth.WithUser(ctx, user), nil
+}
+
+// writeOutput writes content to the file at path, or to stdout without one.
+func writeOutput(path string, content []byte) error {
+	if path == "" || path == "-" {
+		if _, err := os.Stdout.Write(content); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	return nil
+}
+
+// logProgress logs every progressStep bookmarks and once all are done.
+func logProgress(action string) transfer.Progress {
+	return func(done, total int) {
+		if done%progressStep == 0 || done == total {
+			slog.Info(action+" progress", slog.Int("done", done), slog.Int("total", total))
+		}
+	}
+}
+
+// checkReport logs the outcome of an import, failing with errPartial when
+// some of the bookmarks couldn't be imported.
+func checkReport(msg string, report *transfer.Report) error {
+	for _, item := range report.Items {
+		if item.Status == transfer.StatusFailed {
+			slog.Warn("bookmark not imported", slog.String("url", item.URL), slog.String("reason", item.Error))
+		}
+	}
+
+	slog.Info(msg,
+		slog.Int("created", report.Created),
+		slog.Int("skipped", report.Skipped),
+		slog.Int("failed", report.Failed))
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%w: %d of %d", errPartial, report.Failed, len(report.Items))
+	}
+
+	return nil
+}
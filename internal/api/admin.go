@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/haadi-coder/bookmark-manager/internal/api/handler"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/metrics"
+)
+
+type AdminConfig struct {
+	Address string
+
+	// Metrics are served on /metrics when set.
+	Metrics   *metrics.Metrics
+	LogLevels *logger.Levels
+}
+
+// NewAdminServer serves the endpoints meant for operators only, on a
+// listener kept away from the public API.
+func NewAdminServer(cfg *AdminConfig) *Server {
+	router := chi.NewRouter()
+	router.NotFound(handler.NotFound)
+	router.MethodNotAllowed(handler.MethodNotAllowed)
+
+	router.Use(middleware.RequestID)
+	router.Use(middleware.Recoverer)
+	router.Use(handler.RequestLogger)
+
+	if cfg.Metrics != nil {
+		router.Handle("/metrics", cfg.Metrics.Handler())
+	}
+
+	router.Get("/log-level", handler.LogLevels(cfg.LogLevels))
+	router.Put("/log-level", handler.SetLogLevel(cfg.LogLevels))
+
+	return &Server{
+		server: &http.Server{
+			Addr:              cfg.Address,
+			Handler:           router,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/request"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type BookmarkSyncer interface {
+	GetBookmark(ctx context.Context, id int) (*model.Bookmark, error)
+	BookmarkExist(ctx context.Context, url string) (int, bool, error)
+	CreateBookmark(ctx context.Context, title, url string, tags []string, folderID *int) (*model.Bookmark, error)
+	EditBookmarkIfUnchanged(ctx context.Context, id int, version int64, title, url string, tags []string) (*model.Bookmark, error)
+	DeleteBookmarkIfUnchanged(ctx context.Context, id int, version int64) error
+}
+
+// ApplySync applies a batch of client changes one by one and reports the
+// outcome of each, a failed change doesn't stop the others.
+func ApplySync(syncer BookmarkSyncer, queue MetadataQueue) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var reqData request.SyncRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqData); err != nil {
+			log(r.Context()).Error("failed to decode request body", logger.Error(err))
+
+			problem(w, r, http.StatusBadRequest, response.CodeMalformedBody, "failed to decode request body")
+			return
+		}
+
+		if err := validate.Struct(reqData); err != nil {
+			log(r.Context()).Error("invalid request", logger.Error(err))
+
+			invalidRequest(w, r, err)
+			return
+		}
+
+		results := make([]response.SyncResult, 0, len(reqData.Changes))
+		for _, change := range reqData.Changes {
+			if r.Context().Err() != nil {
+				break
+			}
+
+			result := applyChange(r.Context(), syncer, queue, change)
+			result.ClientID = change.ClientID
+
+			results = append(results, result)
+		}
+
+		// Changes applied before the deadline are kept, resending them reports
+		// conflicts instead of applying them twice.
+		if contextError(w, r) {
+			return
+		}
+
+		log(r.Context()).Info("sync changes applied", slog.Int("changes count", len(results)))
+
+		render.JSON(w, r, response.Response{
+			Data: results,
+		})
+	}
+}
+
+func applyChange(ctx context.Context, syncer BookmarkSyncer, queue MetadataQueue, change request.SyncChange) response.SyncResult {
+	if change.ID == nil && (change.Deleted || change.URL == "") {
+		return response.SyncResult{Status: response.SyncStatusInvalid, Error: "url is required to create a bookmark"}
+	}
+	if change.ID != nil && !change.Deleted && change.URL == "" {
+		return response.SyncResult{Status: response.SyncStatusInvalid, Error: "url is required to edit a bookmark"}
+	}
+
+	fetchTitle := change.Title == ""
+	if fetchTitle {
+		change.Title = change.URL
+	}
+
+	var (
+		bookmark *model.Bookmark
+		err      error
+	)
+
+	switch {
+	case change.ID == nil:
+		bookmark, err = syncer.CreateBookmark(ctx, change.Title, change.URL, request.NormalizeTags(change.Tags), change.FolderID)
+	case change.Deleted:
+		err = syncer.DeleteBookmarkIfUnchanged(ctx, *change.ID, change.Version)
+	default:
+		bookmark, err = syncer.EditBookmarkIfUnchanged(ctx, *change.ID, change.Version, change.Title, change.URL, request.NormalizeTags(change.Tags))
+	}
+
+	switch {
+	case err == nil:
+		if bookmark != nil && fetchTitle {
+			queue.Enqueue(bookmark.ID, bookmark.URL)
+		}
+
+		return response.SyncResult{Status: response.SyncStatusApplied, Bookmark: bookmark}
+
+	case errors.Is(err, storage.ErrConflict):
+		current, err := syncer.GetBookmark(ctx, *change.ID)
+		if err != nil {
+			log(ctx).Error("failed to get conflicting bookmark", logger.Error(err))
+			return response.SyncResult{Status: response.SyncStatusConflict, Error: storage.ErrConflict.Error()}
+		}
+
+		return response.SyncResult{Status: response.SyncStatusConflict, Bookmark: current, Error: storage.ErrConflict.Error()}
+
+	case errors.Is(err, storage.ErrExists):
+		result := response.SyncResult{Status: response.SyncStatusConflict, Error: storage.ErrExists.Error()}
+
+		if id, found, err := syncer.BookmarkExist(ctx, change.URL); err == nil && found {
+			if current, err := syncer.GetBookmark(ctx, id); err == nil {
+				result.Bookmark = current
+			}
+		}
+
+		return result
+
+	case errors.Is(err, storage.ErrNotFound):
+		return response.SyncResult{Status: response.SyncStatusNotFound, Error: storage.ErrNotFound.Error()}
+
+	case errors.Is(err, storage.ErrFolderNotFound):
+		return response.SyncResult{Status: response.SyncStatusInvalid, Error: storage.ErrFolderNotFound.Error()}
+
+	default:
+		log(ctx).Error("failed to apply sync change", logger.Error(err))
+		return response.SyncResult{Status: response.SyncStatusFailed, Error: "failed to apply change"}
+	}
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+const (
+	authRealm    = `Basic realm="bookmark-manager", charset="UTF-8"`
+	bearerPrefix = "Bearer "
+)
+
+type UserProvider interface {
+	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
+}
+
+type TokenAuthenticator interface {
+	AuthenticateToken(ctx context.Context, tokenHash string) (*model.User, *model.Token, error)
+}
+
+// Authenticate is a middleware that checks either a Bearer API token or HTTP
+// Basic credentials, and puts the authenticated user with the scopes granted
+// to the request into the request context.
+func Authenticate(users UserProvider, tokens TokenAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var user *model.User
+			var scopes []string
+			var err error
+
+			if header := r.Header.Get("Authorization"); strings.HasPrefix(header, bearerPrefix) {
+				user, scopes, err = authenticateToken(r.Context(), tokens, strings.TrimPrefix(header, bearerPrefix))
+			} else {
+				user, scopes, err = authenticatePassword(r, users)
+			}
+
+			if errors.Is(err, errUnauthorized) {
+				unauthorized(w, r)
+				return
+			}
+			if err != nil {
+				log(r.Context()).Error("failed to authenticate request", logger.Error(err))
+
+				internalError(w, r, "failed to authenticate")
+				return
+			}
+
+			ctx := auth.WithScopes(auth.WithUser(r.Context(), user), scopes)
+			ctx = logger.WithContext(ctx, logger.FromContext(ctx).With(slog.Int("user_id", user.ID)))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope is a middleware that rejects authenticated requests lacking
+// the given scope.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !auth.HasScope(r.Context(), scope) {
+				log(r.Context()).Info("insufficient scope", slog.String("scope", scope))
+
+				problem(w, r, http.StatusForbidden, response.CodeInsufficientScope, "insufficient scope: "+scope+" is required")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+var errUnauthorized = errors.New("unauthorized")
+
+func authenticateToken(ctx context.Context, tokens TokenAuthenticator, token string) (*model.User, []string, error) {
+	user, t, err := tokens.AuthenticateToken(ctx, auth.HashToken(token))
+	if errors.Is(err, storage.ErrTokenNotFound) {
+		log(ctx).Info("unknown or revoked token")
+
+		return nil, nil, errUnauthorized
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, t.Scopes, nil
+}
+
+func authenticatePassword(r *http.Request, users UserProvider) (*model.User, []string, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, nil, errUnauthorized
+	}
+
+	user, err := users.GetUserByUsername(r.Context(), username)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		log(r.Context()).Info("unknown user", slog.String("username", username))
+
+		return nil, nil, errUnauthorized
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !auth.CheckPassword(user.PasswordHash, password) {
+		log(r.Context()).Info("wrong password", slog.String("username", username))
+
+		return nil, nil, errUnauthorized
+	}
+
+	return user, auth.PasswordScopes, nil
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", authRealm)
+
+	problem(w, r, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type HistoryProvider interface {
+	GetBookmarkHistory(ctx context.Context, id int) ([]*model.Revision, error)
+}
+
+func BookmarkHistory(provider HistoryProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		parsedId, err := strconv.Atoi(id)
+		if err != nil {
+			log(r.Context()).Error("failed to convert id to integer", logger.Error(err))
+
+			invalidParam(w, r, "id")
+			return
+		}
+
+		result, err := provider.GetBookmarkHistory(r.Context(), parsedId)
+		if errors.Is(err, storage.ErrNotFound) {
+			log(r.Context()).Info(storage.ErrNotFound.Error(), slog.String("id", id))
+
+			errorProblem(w, r, http.StatusNotFound, storage.ErrNotFound)
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to get bookmark history from db", logger.Error(err))
+
+			internalError(w, r, "failed to get bookmark history")
+			return
+		}
+
+		log(r.Context()).Info("got bookmark history", slog.String("id", id), slog.Int("revisions count", len(result)))
+
+		render.JSON(w, r, response.Response{
+			Data: result,
+		})
+	}
+}
@@ -2,43 +2,91 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/render"
 
 	"github.com/haadi-coder/bookmark-manager/internal/api/request"
 	"github.com/haadi-coder/bookmark-manager/internal/api/response"
 	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
-	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
 )
 
 type BookmarkProvider interface {
-	GetBookmarks(ctx context.Context, limit, offset int, search string) ([]*model.Bookmark, int, error)
+	GetBookmarks(ctx context.Context, filter storage.BookmarkFilter) (*storage.BookmarkPage, error)
 }
 
-func Bookmarks(ctx context.Context, provider BookmarkProvider) http.HandlerFunc {
+func Bookmarks(provider BookmarkProvider) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		opts, err := request.ParseListOptions(r)
+		if errors.Is(err, request.ErrInvalidCursor) {
+			problem(w, r, http.StatusBadRequest, response.CodeInvalidCursor, "invalid cursor")
+			return
+		}
 		if err != nil {
-			slog.Error("failed to parse query params. Default params was applied", logger.Error(err))
+			log(r.Context()).Error("failed to parse query params. Default params was applied", logger.Error(err))
 		}
 
-		result, totalCount, err := provider.GetBookmarks(ctx, opts.Perpage, opts.Offset(), opts.Search)
+		page, err := provider.GetBookmarks(r.Context(), storage.BookmarkFilter{
+			Limit:      opts.Perpage,
+			Offset:     opts.Offset(),
+			Cursor:     opts.Cursor,
+			SkipCount:  opts.SkipCount,
+			Search:     opts.Search,
+			FolderID:   opts.FolderID,
+			LinkStatus: opts.LinkStatus,
+			Tags:       opts.Tags,
+			AnyTag:     opts.TagMode == request.TagModeAny,
+		})
 		if err != nil {
-			slog.Error("failed to get bookmarks from db", logger.Error(err))
+			log(r.Context()).Error("failed to get bookmarks from db", logger.Error(err))
 
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to get bookmarks"))
+			internalError(w, r, "failed to get bookmarks")
 			return
 		}
 
-		slog.Info("got bookmarks", slog.Any("bookmarks count", len(result)))
+		log(r.Context()).Info("got bookmarks", slog.Any("bookmarks count", len(page.Bookmarks)))
+
+		next := request.EncodeCursor(page.NextCursor)
+		prev := request.EncodeCursor(page.PrevCursor)
+
+		var links []string
+		if next != "" {
+			links = append(links, fmt.Sprintf(`<%s>; rel="next"`, cursorURL(r, next)))
+		}
+		if prev != "" {
+			links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, cursorURL(r, prev)))
+		}
+		if len(links) > 0 {
+			w.Header().Set("Link", strings.Join(links, ", "))
+		}
+
+		if page.Total != nil {
+			w.Header().Set("X-Total", strconv.Itoa(*page.Total))
+		}
 
-		w.Header().Set("X-Total", strconv.Itoa(totalCount))
-		render.JSON(w, r, response.Response{
-			Data: result,
+		render.JSON(w, r, response.Page{
+			Data:       page.Bookmarks,
+			Total:      page.Total,
+			NextCursor: next,
+			PrevCursor: prev,
 		})
 	}
 }
+
+// cursorURL returns the current request URL pointing at another page.
+func cursorURL(r *http.Request, cursor string) string {
+	query := r.URL.Query()
+	query.Del("page")
+	query.Set("cursor", cursor)
+
+	u := *r.URL
+	u.RawQuery = query.Encode()
+
+	return u.RequestURI()
+}
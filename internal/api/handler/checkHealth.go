@@ -6,28 +6,62 @@ import (
 
 	"github.com/go-chi/render"
 	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
 )
 
 type Pinger interface {
 	Ping(ctx context.Context) error
 }
 
-func CheckHealth(pinger Pinger) http.HandlerFunc {
+type SchemaChecker interface {
+	SchemaStatus(ctx context.Context) (*storage.SchemaStatus, error)
+}
+
+// CheckHealth reports whether the database is reachable. With a schema
+// checker it also fails while the schema is behind the migrations the
+// binary was built with.
+func CheckHealth(pinger Pinger, schemaChecker SchemaChecker) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		dbStatus := "up"
+		resp := response.HealthResponse{
+			Status: "up",
+			Checks: response.HealthChecks{
+				Postgres: "up",
+			},
+		}
+
 		if err := pinger.Ping(r.Context()); err != nil {
-			dbStatus = "down"
+			resp.Status = "down"
+			resp.Checks.Postgres = "down"
 		}
 
-		if dbStatus == "down" {
+		if schemaChecker != nil && resp.Status == "up" {
+			resp.Checks.Schema = "up"
+
+			status, err := schemaChecker.SchemaStatus(r.Context())
+			switch {
+			case err != nil:
+				resp.Checks.Schema = "down"
+			case status.Behind():
+				resp.Checks.Schema = "behind"
+			}
+
+			if status != nil {
+				resp.Schema = &response.SchemaHealth{
+					Version: status.Version,
+					Latest:  status.Latest,
+					Dirty:   status.Dirty,
+				}
+			}
+
+			if resp.Checks.Schema != "up" {
+				resp.Status = "down"
+			}
+		}
+
+		if resp.Status == "down" {
 			w.WriteHeader(http.StatusServiceUnavailable)
 		}
 
-		render.JSON(w, r, response.HealthResponse{
-			Status: dbStatus,
-			Checks: response.HealthChecks{
-				Postgres: dbStatus,
-			},
-		})
+		render.JSON(w, r, resp)
 	}
 }
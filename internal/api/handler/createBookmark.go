@@ -8,7 +8,6 @@ import (
 	"net/http"
 
 	"github.com/go-chi/render"
-	"github.com/go-playground/validator"
 	"github.com/haadi-coder/bookmark-manager/internal/api/request"
 	"github.com/haadi-coder/bookmark-manager/internal/api/response"
 	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
@@ -17,45 +16,60 @@ import (
 )
 
 type BookmarkCreator interface {
-	CreateBookmark(ctx context.Context, title, url string) (*model.Bookmark, error)
+	CreateBookmark(ctx context.Context, title, url string, tags []string, folderID *int) (*model.Bookmark, error)
 }
 
-func CreateBookmark(ctx context.Context, creator BookmarkCreator) http.HandlerFunc {
+type MetadataQueue interface {
+	Enqueue(bookmarkID int, url string)
+}
+
+func CreateBookmark(creator BookmarkCreator, queue MetadataQueue) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var reqData request.Request
 		if err := json.NewDecoder(r.Body).Decode(&reqData); err != nil {
-			slog.Error("failed to decode request body", logger.Error(err))
+			log(r.Context()).Error("failed to decode request body", logger.Error(err))
 
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.Error("failed to decode request body"))
+			problem(w, r, http.StatusBadRequest, response.CodeMalformedBody, "failed to decode request body")
 			return
 		}
 
-		if err := validator.New().Struct(reqData); err != nil {
-			slog.Error("invalid request", logger.Error(err))
+		if err := validate.Struct(reqData); err != nil {
+			log(r.Context()).Error("invalid request", logger.Error(err))
 
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.Error("invalid request"))
+			invalidRequest(w, r, err)
 			return
 		}
 
-		new, err := creator.CreateBookmark(ctx, reqData.Title, reqData.URL)
+		fetchTitle := reqData.Title == ""
+		if fetchTitle {
+			reqData.Title = reqData.URL
+		}
+
+		new, err := creator.CreateBookmark(r.Context(), reqData.Title, reqData.URL, request.NormalizeTags(reqData.Tags), reqData.FolderID)
 		if errors.Is(err, storage.ErrExists) {
-			slog.Info(storage.ErrExists.Error(), slog.String("url", reqData.URL))
+			log(r.Context()).Info(storage.ErrExists.Error(), slog.String("url", reqData.URL))
 
-			render.Status(r, http.StatusConflict)
-			render.JSON(w, r, response.Error(storage.ErrExists.Error()))
+			errorProblem(w, r, http.StatusConflict, storage.ErrExists)
+			return
+		}
+		if errors.Is(err, storage.ErrFolderNotFound) {
+			log(r.Context()).Info(storage.ErrFolderNotFound.Error(), slog.Int("folder_id", *reqData.FolderID))
+
+			errorProblem(w, r, http.StatusBadRequest, storage.ErrFolderNotFound)
 			return
 		}
 		if err != nil {
-			slog.Error("failed to create bookmark", logger.Error(err))
+			log(r.Context()).Error("failed to create bookmark", logger.Error(err))
 
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to create bookmark"))
+			internalError(w, r, "failed to create bookmark")
 			return
 		}
 
-		slog.Info("bookmark sucessfully created", slog.Int("id", new.ID))
+		if fetchTitle {
+			queue.Enqueue(new.ID, new.URL)
+		}
+
+		log(r.Context()).Info("bookmark sucessfully created", slog.Int("id", new.ID))
 		render.JSON(w, r, response.Response{
 			Data: new,
 		})
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/request"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type FolderCreator interface {
+	CreateFolder(ctx context.Context, name string, parentID *int) (*model.Folder, error)
+}
+
+func CreateFolder(creator FolderCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var reqData request.FolderRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqData); err != nil {
+			log(r.Context()).Error("failed to decode request body", logger.Error(err))
+
+			problem(w, r, http.StatusBadRequest, response.CodeMalformedBody, "failed to decode request body")
+			return
+		}
+
+		if err := validate.Struct(reqData); err != nil {
+			log(r.Context()).Error("invalid request", logger.Error(err))
+
+			invalidRequest(w, r, err)
+			return
+		}
+
+		new, err := creator.CreateFolder(r.Context(), reqData.Name, reqData.ParentID)
+		if errors.Is(err, storage.ErrFolderNotFound) {
+			log(r.Context()).Info("parent folder not found", slog.Int("parent_id", *reqData.ParentID))
+
+			problem(w, r, http.StatusBadRequest, response.CodeFolderNotFound, "parent folder not found")
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to create folder", logger.Error(err))
+
+			internalError(w, r, "failed to create folder")
+			return
+		}
+
+		log(r.Context()).Info("folder sucessfully created", slog.Int("id", new.ID))
+		render.JSON(w, r, response.Response{
+			Data: new,
+		})
+	}
+}
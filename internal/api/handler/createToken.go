@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/request"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+type TokenCreator interface {
+	CreateToken(ctx context.Context, name, tokenHash string, scopes []string) (*model.Token, error)
+}
+
+func CreateToken(creator TokenCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var reqData request.TokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqData); err != nil {
+			log(r.Context()).Error("failed to decode request body", logger.Error(err))
+
+			problem(w, r, http.StatusBadRequest, response.CodeMalformedBody, "failed to decode request body")
+			return
+		}
+
+		if err := validate.Struct(reqData); err != nil {
+			log(r.Context()).Error("invalid request", logger.Error(err))
+
+			invalidRequest(w, r, err)
+			return
+		}
+
+		token, hash, err := auth.GenerateToken()
+		if err != nil {
+			log(r.Context()).Error("failed to generate token", logger.Error(err))
+
+			internalError(w, r, "failed to create token")
+			return
+		}
+
+		new, err := creator.CreateToken(r.Context(), reqData.Name, hash, reqData.Scopes)
+		if err != nil {
+			log(r.Context()).Error("failed to create token", logger.Error(err))
+
+			internalError(w, r, "failed to create token")
+			return
+		}
+		new.Secret = token
+
+		log(r.Context()).Info("token sucessfully created", slog.Int("id", new.ID))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, response.Response{
+			Data: new,
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/request"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type UserCreator interface {
+	CreateUser(ctx context.Context, username, passwordHash string) (*model.User, error)
+}
+
+func CreateUser(creator UserCreator, allowSignup bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !allowSignup {
+			log(r.Context()).Info("signup attempt while signup is disabled")
+
+			problem(w, r, http.StatusForbidden, response.CodeSignupDisabled, "signup is disabled")
+			return
+		}
+
+		var reqData request.UserRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqData); err != nil {
+			log(r.Context()).Error("failed to decode request body", logger.Error(err))
+
+			problem(w, r, http.StatusBadRequest, response.CodeMalformedBody, "failed to decode request body")
+			return
+		}
+
+		if err := validate.Struct(reqData); err != nil {
+			log(r.Context()).Error("invalid request", logger.Error(err))
+
+			invalidRequest(w, r, err)
+			return
+		}
+
+		hash, err := auth.HashPassword(reqData.Password)
+		if err != nil {
+			log(r.Context()).Error("failed to hash password", logger.Error(err))
+
+			internalError(w, r, "failed to create user")
+			return
+		}
+
+		new, err := creator.CreateUser(r.Context(), reqData.Username, hash)
+		if errors.Is(err, storage.ErrUserExists) {
+			log(r.Context()).Info(storage.ErrUserExists.Error(), slog.String("username", reqData.Username))
+
+			errorProblem(w, r, http.StatusConflict, storage.ErrUserExists)
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to create user", logger.Error(err))
+
+			internalError(w, r, "failed to create user")
+			return
+		}
+
+		log(r.Context()).Info("user sucessfully created", slog.Int("id", new.ID))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, response.Response{
+			Data: new,
+		})
+	}
+}
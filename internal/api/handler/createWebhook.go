@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/request"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/webhook"
+)
+
+type WebhookCreator interface {
+	CreateWebhook(ctx context.Context, url, secret string, events []string) (*model.Webhook, error)
+}
+
+func CreateWebhook(creator WebhookCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var reqData request.WebhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqData); err != nil {
+			log(r.Context()).Error("failed to decode request body", logger.Error(err))
+
+			problem(w, r, http.StatusBadRequest, response.CodeMalformedBody, "failed to decode request body")
+			return
+		}
+
+		if err := validate.Struct(reqData); err != nil {
+			log(r.Context()).Error("invalid request", logger.Error(err))
+
+			invalidRequest(w, r, err)
+			return
+		}
+
+		secret, err := webhook.GenerateSecret()
+		if err != nil {
+			log(r.Context()).Error("failed to generate webhook secret", logger.Error(err))
+
+			internalError(w, r, "failed to create webhook")
+			return
+		}
+
+		new, err := creator.CreateWebhook(r.Context(), reqData.URL, secret, reqData.Events)
+		if err != nil {
+			log(r.Context()).Error("failed to create webhook", logger.Error(err))
+
+			internalError(w, r, "failed to create webhook")
+			return
+		}
+		new.Secret = secret
+
+		log(r.Context()).Info("webhook sucessfully created", slog.Int("id", new.ID))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, response.Response{
+			Data: new,
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+)
+
+func CurrentUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := auth.User(r.Context())
+		if !ok {
+			unauthorized(w, r)
+			return
+		}
+
+		render.JSON(w, r, response.Response{
+			Data: user,
+		})
+	}
+}
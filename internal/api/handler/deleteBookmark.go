@@ -18,35 +18,32 @@ type BookmarkRemover interface {
 	DeleteBookmark(ctx context.Context, id int) error
 }
 
-func DeleteBookmark(ctx context.Context, remover BookmarkRemover) http.HandlerFunc {
+func DeleteBookmark(remover BookmarkRemover) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
 		parsedId, err := strconv.Atoi(id)
 		if err != nil {
-			slog.Error("failed to convert limit to integer", logger.Error(err))
+			log(r.Context()).Error("failed to convert limit to integer", logger.Error(err))
 
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.Error("invalid request"))
+			invalidParam(w, r, "id")
 			return
 		}
 
-		err = remover.DeleteBookmark(ctx, parsedId)
+		err = remover.DeleteBookmark(r.Context(), parsedId)
 		if errors.Is(err, storage.ErrNotFound) {
-			slog.Info(storage.ErrNotFound.Error(), slog.String("id", id))
+			log(r.Context()).Info(storage.ErrNotFound.Error(), slog.String("id", id))
 
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, response.Error(storage.ErrNotFound.Error()))
+			errorProblem(w, r, http.StatusNotFound, storage.ErrNotFound)
 			return
 		}
 		if err != nil {
-			slog.Error("failed to delete bookmark", logger.Error(err))
+			log(r.Context()).Error("failed to delete bookmark", logger.Error(err))
 
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to delete bookmark"))
+			internalError(w, r, "failed to delete bookmark")
 			return
 		}
 
-		slog.Info("bookmark sucessfully deleted", slog.String("id", id))
+		log(r.Context()).Info("bookmark sucessfully deleted", slog.String("id", id))
 		render.JSON(w, r, response.Response{
 			Data: "bookmark sucessfully deleted",
 		})
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type FolderRemover interface {
+	DeleteFolder(ctx context.Context, id int) error
+}
+
+func DeleteFolder(remover FolderRemover) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		parsedId, err := strconv.Atoi(id)
+		if err != nil {
+			log(r.Context()).Error("failed to get id from url", logger.Error(err))
+
+			invalidParam(w, r, "id")
+			return
+		}
+
+		err = remover.DeleteFolder(r.Context(), parsedId)
+		if errors.Is(err, storage.ErrFolderNotFound) {
+			log(r.Context()).Info(storage.ErrFolderNotFound.Error(), slog.String("id", id))
+
+			errorProblem(w, r, http.StatusNotFound, storage.ErrFolderNotFound)
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to delete folder", logger.Error(err))
+
+			internalError(w, r, "failed to delete folder")
+			return
+		}
+
+		log(r.Context()).Info("folder sucessfully deleted", slog.String("id", id))
+		render.JSON(w, r, response.Response{
+			Data: "folder sucessfully deleted",
+		})
+	}
+}
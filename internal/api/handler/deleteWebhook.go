@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type WebhookRemover interface {
+	DeleteWebhook(ctx context.Context, id int) error
+}
+
+func DeleteWebhook(remover WebhookRemover) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		parsedId, err := strconv.Atoi(id)
+		if err != nil {
+			log(r.Context()).Error("failed to get id from url", logger.Error(err))
+
+			invalidParam(w, r, "id")
+			return
+		}
+
+		err = remover.DeleteWebhook(r.Context(), parsedId)
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			log(r.Context()).Info(storage.ErrWebhookNotFound.Error(), slog.String("id", id))
+
+			errorProblem(w, r, http.StatusNotFound, storage.ErrWebhookNotFound)
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to delete webhook", logger.Error(err))
+
+			internalError(w, r, "failed to delete webhook")
+			return
+		}
+
+		log(r.Context()).Info("webhook sucessfully deleted", slog.String("id", id))
+		render.JSON(w, r, response.Response{
+			Data: "webhook sucessfully deleted",
+		})
+	}
+}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
-	"github.com/go-playground/validator"
 	"github.com/haadi-coder/bookmark-manager/internal/api/request"
 	"github.com/haadi-coder/bookmark-manager/internal/api/response"
 	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
@@ -19,56 +18,67 @@ import (
 )
 
 type BookmarkEditor interface {
-	EditBookmark(ctx context.Context, id int, title, url string) (*model.Bookmark, error)
+	// EditBookmark keeps the current tags of the bookmark when tags is nil.
+	EditBookmark(ctx context.Context, id int, title, url string, tags []string) (*model.Bookmark, error)
 }
 
-func EditBookmark(ctx context.Context, editor BookmarkEditor) http.HandlerFunc {
+func EditBookmark(editor BookmarkEditor, queue MetadataQueue) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var reqData request.Request
 
 		if err := json.NewDecoder(r.Body).Decode(&reqData); err != nil {
-			slog.Error("failed to decode request body", logger.Error(err))
+			log(r.Context()).Error("failed to decode request body", logger.Error(err))
 
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.Error("failed to decode request body"))
+			problem(w, r, http.StatusBadRequest, response.CodeMalformedBody, "failed to decode request body")
 			return
 		}
 
-		if err := validator.New().Struct(reqData); err != nil {
-			slog.Error("invalid request", logger.Error(err))
+		if err := validate.Struct(reqData); err != nil {
+			log(r.Context()).Error("invalid request", logger.Error(err))
 
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.Error("invalid request"))
+			invalidRequest(w, r, err)
 			return
 		}
 
 		id := chi.URLParam(r, "id")
 		parsedId, err := strconv.Atoi(id)
 		if err != nil {
-			slog.Error("failed to get id from url", logger.Error(err))
+			log(r.Context()).Error("failed to get id from url", logger.Error(err))
 
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.Error("invalid request"))
+			invalidParam(w, r, "id")
 			return
 		}
 
-		edited, err := editor.EditBookmark(ctx, parsedId, reqData.Title, reqData.URL)
+		fetchTitle := reqData.Title == ""
+		if fetchTitle {
+			reqData.Title = reqData.URL
+		}
+
+		edited, err := editor.EditBookmark(r.Context(), parsedId, reqData.Title, reqData.URL, request.NormalizeTags(reqData.Tags))
 		if errors.Is(err, storage.ErrNotFound) {
-			slog.Info(storage.ErrNotFound.Error(), slog.String("url", reqData.URL))
+			log(r.Context()).Info(storage.ErrNotFound.Error(), slog.String("url", reqData.URL))
 
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, response.Error(storage.ErrNotFound.Error()))
+			errorProblem(w, r, http.StatusNotFound, storage.ErrNotFound)
+			return
+		}
+		if errors.Is(err, storage.ErrExists) {
+			log(r.Context()).Info(storage.ErrExists.Error(), slog.String("url", reqData.URL))
+
+			errorProblem(w, r, http.StatusConflict, storage.ErrExists)
 			return
 		}
 		if err != nil {
-			slog.Error("failed to edit bookmark", logger.Error(err))
+			log(r.Context()).Error("failed to edit bookmark", logger.Error(err))
 
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to edit bookmark"))
+			internalError(w, r, "failed to edit bookmark")
 			return
 		}
 
-		slog.Info("bookmark sucessfully edited", slog.Int("id", edited.ID))
+		if fetchTitle {
+			queue.Enqueue(edited.ID, edited.URL)
+		}
+
+		log(r.Context()).Info("bookmark sucessfully edited", slog.Int("id", edited.ID))
 		render.JSON(w, r, response.Response{
 			Data: edited,
 		})
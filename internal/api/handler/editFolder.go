@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/request"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type FolderEditor interface {
+	// EditFolder renames the folder and moves it, with its whole subtree,
+	// under the given parent. A nil parentID moves it to the root.
+	EditFolder(ctx context.Context, id int, name string, parentID *int) (*model.Folder, error)
+}
+
+func EditFolder(editor FolderEditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var reqData request.FolderRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&reqData); err != nil {
+			log(r.Context()).Error("failed to decode request body", logger.Error(err))
+
+			problem(w, r, http.StatusBadRequest, response.CodeMalformedBody, "failed to decode request body")
+			return
+		}
+
+		if err := validate.Struct(reqData); err != nil {
+			log(r.Context()).Error("invalid request", logger.Error(err))
+
+			invalidRequest(w, r, err)
+			return
+		}
+
+		id := chi.URLParam(r, "id")
+		parsedId, err := strconv.Atoi(id)
+		if err != nil {
+			log(r.Context()).Error("failed to get id from url", logger.Error(err))
+
+			invalidParam(w, r, "id")
+			return
+		}
+
+		edited, err := editor.EditFolder(r.Context(), parsedId, reqData.Name, reqData.ParentID)
+		if errors.Is(err, storage.ErrFolderNotFound) {
+			log(r.Context()).Info(storage.ErrFolderNotFound.Error(), slog.String("id", id))
+
+			errorProblem(w, r, http.StatusNotFound, storage.ErrFolderNotFound)
+			return
+		}
+		if errors.Is(err, storage.ErrFolderCycle) {
+			log(r.Context()).Info(storage.ErrFolderCycle.Error(), slog.String("id", id))
+
+			errorProblem(w, r, http.StatusConflict, storage.ErrFolderCycle)
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to edit folder", logger.Error(err))
+
+			internalError(w, r, "failed to edit folder")
+			return
+		}
+
+		log(r.Context()).Info("folder sucessfully edited", slog.Int("id", edited.ID))
+		render.JSON(w, r, response.Response{
+			Data: edited,
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+const (
+	// eventsBatch caps the events read from storage at once.
+	eventsBatch = 100
+	// heartbeatInterval keeps idle streams from being cut by proxies.
+	heartbeatInterval = 15 * time.Second
+	// retryInterval tells clients how long to wait before reconnecting.
+	retryInterval = 3 * time.Second
+)
+
+type EventProvider interface {
+	GetEventsSince(ctx context.Context, afterID int64, limit int) ([]*model.StoredEvent, error)
+	GetLastEventID(ctx context.Context) (int64, error)
+}
+
+type EventSubscriber interface {
+	Subscribe(userID int) (<-chan struct{}, func())
+}
+
+// Events streams bookmark events as Server-Sent Events. Clients resume after
+// a reconnect with the Last-Event-ID header, or the last_event_id query
+// parameter. Streams end when shutdown is closed.
+func Events(provider EventProvider, subscriber EventSubscriber, shutdown <-chan struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, err := auth.UserID(ctx)
+		if err != nil {
+			log(ctx).Error("failed to get user", logger.Error(err))
+
+			problem(w, r, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+			return
+		}
+
+		// Subscribe before reading the backlog so nothing recorded in between is missed.
+		wake, unsubscribe := subscriber.Subscribe(userID)
+		defer unsubscribe()
+
+		lastID, err := lastEventID(r)
+		if err != nil {
+			lastID, err = provider.GetLastEventID(ctx)
+		}
+		if err != nil {
+			log(ctx).Error("failed to get last event id", logger.Error(err))
+
+			internalError(w, r, "failed to get events")
+			return
+		}
+
+		rc := http.NewResponseController(w)
+
+		// The stream outlives the server's WriteTimeout.
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			log(ctx).Error("failed to clear write deadline", logger.Error(err))
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", retryInterval.Milliseconds()); err != nil {
+			return
+		}
+
+		log(ctx).Info("event stream opened", slog.Int("user_id", userID), slog.Int64("last_event_id", lastID))
+		defer log(ctx).Info("event stream closed", slog.Int("user_id", userID))
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			lastID, err = writeEvents(ctx, w, provider, lastID)
+			if err != nil {
+				log(ctx).Error("failed to stream events", logger.Error(err))
+				return
+			}
+
+			if err := rc.Flush(); err != nil {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-shutdown:
+				return
+			case <-wake:
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// writeEvents writes every event after lastID and returns the id of the last
+// one written.
+func writeEvents(ctx context.Context, w http.ResponseWriter, provider EventProvider, lastID int64) (int64, error) {
+	for {
+		events, err := provider.GetEventsSince(ctx, lastID, eventsBatch)
+		if err != nil {
+			return lastID, err
+		}
+
+		for _, e := range events {
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Event, e.Payload); err != nil {
+				return lastID, fmt.Errorf("failed to write event: %w", err)
+			}
+
+			lastID = e.ID
+		}
+
+		if len(events) < eventsBatch {
+			return lastID, nil
+		}
+	}
+}
+
+func lastEventID(r *http.Request) (int64, error) {
+	id := r.Header.Get("Last-Event-ID")
+	if id == "" {
+		id = r.URL.Query().Get("last_event_id")
+	}
+
+	return strconv.ParseInt(id, 10, 64)
+}
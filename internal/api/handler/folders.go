@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+type FolderProvider interface {
+	GetFolders(ctx context.Context) ([]*model.Folder, error)
+}
+
+func Folders(provider FolderProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := provider.GetFolders(r.Context())
+		if err != nil {
+			log(r.Context()).Error("failed to get folders from db", logger.Error(err))
+
+			internalError(w, r, "failed to get folders")
+			return
+		}
+
+		log(r.Context()).Info("got folders", slog.Int("folders_count", len(result)))
+
+		render.JSON(w, r, response.Response{
+			Data: result,
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/transfer"
+)
+
+const (
+	maxImportSize   = 10 << 20
+	importFileField = "file"
+)
+
+type BookmarkImporter interface {
+	ImportBookmark(ctx context.Context, bookmark *model.Bookmark) (*model.Bookmark, error)
+	ImportFolder(ctx context.Context, name string, parentID *int) (*model.Folder, error)
+}
+
+func ImportNetscapeBookmarks(importer BookmarkImporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+		file, _, err := r.FormFile(importFileField)
+		if err != nil {
+			log(r.Context()).Error("failed to read import file from form", logger.Error(err))
+
+			importFileProblem(w, r, err)
+			return
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+
+		content, err := io.ReadAll(file)
+		if err != nil {
+			log(r.Context()).Error("failed to read import file", logger.Error(err))
+
+			importFileProblem(w, r, err)
+			return
+		}
+
+		report, err := transfer.ImportNetscape(r.Context(), importer, content, nil)
+		if err != nil {
+			log(r.Context()).Error("failed to unmarshal netscape bookmarks", logger.Error(err))
+
+			problem(w, r, http.StatusBadRequest, response.CodeInvalidImportFile, "failed to parse netscape bookmarks file")
+			return
+		}
+
+		// Bookmarks imported before the deadline are kept, importing the file
+		// again skips them.
+		if contextError(w, r) {
+			return
+		}
+
+		log(r.Context()).Info("netscape bookmarks imported",
+			slog.Int("created", report.Created),
+			slog.Int("skipped", report.Skipped),
+			slog.Int("failed", report.Failed))
+
+		render.JSON(w, r, response.Response{
+			Data: report,
+		})
+	}
+}
+
+// importFileProblem answers an import file that couldn't be read, with 413
+// when the body exceeds maxImportSize.
+func importFileProblem(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		problem(w, r, http.StatusRequestEntityTooLarge, response.CodeImportTooLarge,
+			fmt.Sprintf("import file exceeds %d bytes", tooLarge.Limit))
+		return
+	}
+
+	problem(w, r, http.StatusBadRequest, response.CodeInvalidImportFile, "failed to read import file")
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+type LinkReporter interface {
+	GetLinkReport(ctx context.Context) (*model.LinkReport, error)
+}
+
+func LinkReport(reporter LinkReporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report, err := reporter.GetLinkReport(r.Context())
+		if err != nil {
+			log(r.Context()).Error("failed to get link report from db", logger.Error(err))
+
+			internalError(w, r, "failed to get link report")
+			return
+		}
+
+		log(r.Context()).Info("got link report", slog.Int("broken", report.Broken))
+
+		render.JSON(w, r, response.Response{
+			Data: report,
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+)
+
+type LevelProvider interface {
+	Level(pkg string) slog.Level
+	Packages() map[string]slog.Level
+}
+
+func LogLevels(provider LevelProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, response.Response{
+			Data: logLevels(provider),
+		})
+	}
+}
+
+func logLevels(provider LevelProvider) response.LogLevels {
+	packages := make(map[string]string)
+	for pkg, level := range provider.Packages() {
+		packages[pkg] = level.String()
+	}
+
+	return response.LogLevels{
+		Level:    provider.Level("").String(),
+		Packages: packages,
+	}
+}
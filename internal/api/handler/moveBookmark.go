@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/request"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type BookmarkMover interface {
+	// MoveBookmark puts the bookmark into the given folder. A nil folderID
+	// moves it to the root.
+	MoveBookmark(ctx context.Context, id int, folderID *int) (*model.Bookmark, error)
+}
+
+func MoveBookmark(mover BookmarkMover) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var reqData request.MoveRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&reqData); err != nil {
+			log(r.Context()).Error("failed to decode request body", logger.Error(err))
+
+			problem(w, r, http.StatusBadRequest, response.CodeMalformedBody, "failed to decode request body")
+			return
+		}
+
+		id := chi.URLParam(r, "id")
+		parsedId, err := strconv.Atoi(id)
+		if err != nil {
+			log(r.Context()).Error("failed to get id from url", logger.Error(err))
+
+			invalidParam(w, r, "id")
+			return
+		}
+
+		moved, err := mover.MoveBookmark(r.Context(), parsedId, reqData.FolderID)
+		if errors.Is(err, storage.ErrNotFound) {
+			log(r.Context()).Info(storage.ErrNotFound.Error(), slog.String("id", id))
+
+			errorProblem(w, r, http.StatusNotFound, storage.ErrNotFound)
+			return
+		}
+		if errors.Is(err, storage.ErrFolderNotFound) {
+			log(r.Context()).Info(storage.ErrFolderNotFound.Error(), slog.Int("folder_id", *reqData.FolderID))
+
+			errorProblem(w, r, http.StatusBadRequest, storage.ErrFolderNotFound)
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to move bookmark", logger.Error(err))
+
+			internalError(w, r, "failed to move bookmark")
+			return
+		}
+
+		log(r.Context()).Info("bookmark sucessfully moved", slog.Int("id", moved.ID))
+		render.JSON(w, r, response.Response{
+			Data: moved,
+		})
+	}
+}
@@ -1,56 +1,51 @@
 package handler
 
 import (
-	"context"
 	"log/slog"
 	"math"
 	"net/http"
 
 	"github.com/go-chi/render"
-	"github.com/haadi-coder/bookmark-manager/internal/api/response"
 	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
-	"github.com/virtualtam/netscape-go"
-	"github.com/virtualtam/netscape-go/types"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+	"github.com/haadi-coder/bookmark-manager/internal/transfer"
 )
 
-func NetscapeBookmarks(ctx context.Context, provider BookmarkProvider) http.HandlerFunc {
+func NetscapeBookmarks(provider BookmarkProvider, folderProvider FolderProvider) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		result, totalCount, err := provider.GetBookmarks(ctx, math.MaxInt32, 0, "")
+		page, err := provider.GetBookmarks(r.Context(), storage.BookmarkFilter{
+			Limit:     math.MaxInt32,
+			SkipCount: true,
+		})
 		if err != nil {
-			slog.Error("failed to get bookmarks from db", logger.Error(err))
+			log(r.Context()).Error("failed to get bookmarks from db", logger.Error(err))
 
-			render.JSON(w, r, response.Error("failed to get bookmarks"))
+			internalError(w, r, "failed to get bookmarks")
 			return
 		}
 
-		doc := types.Document{
-			Title: "Bookmarks",
-			Root: types.Folder{
-				Name:      "Bookmarks",
-				Bookmarks: make([]types.Bookmark, 0, totalCount),
-			},
-		}
+		folders, err := folderProvider.GetFolders(r.Context())
+		if err != nil {
+			log(r.Context()).Error("failed to get folders from db", logger.Error(err))
 
-		for _, bm := range result {
-			doc.Root.Bookmarks = append(doc.Root.Bookmarks, types.Bookmark{
-				Title:     bm.Title,
-				CreatedAt: &bm.CreatedAt,
-				UpdatedAt: &bm.UpdatedAt,
-				Href:      bm.URL,
-			})
+			internalError(w, r, "failed to get folders")
+			return
 		}
 
-		m, err := netscape.Marshal(&doc)
+		m, err := transfer.MarshalNetscape(&transfer.Document{
+			Folders:   folders,
+			Bookmarks: page.Bookmarks,
+		})
 		if err != nil {
-			slog.Error("failed to marshal bookmarks to netscape format", logger.Error(err))
+			log(r.Context()).Error("failed to marshal bookmarks to netscape format", logger.Error(err))
 
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to marshal bookmarks to netscape format"))
+			internalError(w, r, "failed to marshal bookmarks to netscape format")
 			return
 		}
 
-		slog.Info("bookmarks successfully exported to netscape format",
-			slog.Int("bookmarks_count", len(result)),
+		log(r.Context()).Info("bookmarks successfully exported to netscape format",
+			slog.Int("bookmarks_count", len(page.Bookmarks)),
+			slog.Int("folders_count", len(folders)),
 			slog.Int("output_size_bytes", len(m)))
 
 		w.Header().Set("Content-Disposition", `attachment; filename="bookmarks.html"`)
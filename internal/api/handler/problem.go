@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/metadata"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+// validate checks request bodies, naming invalid fields by their JSON name.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
+
+	return v
+}
+
+// errorCodes are the problem codes of the errors handlers answer with.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{storage.ErrNotFound, response.CodeBookmarkNotFound},
+	{storage.ErrExists, response.CodeBookmarkExists},
+	{storage.ErrFolderNotFound, response.CodeFolderNotFound},
+	{storage.ErrFolderCycle, response.CodeFolderCycle},
+	{storage.ErrUserExists, response.CodeUserExists},
+	{storage.ErrTokenNotFound, response.CodeTokenNotFound},
+	{storage.ErrRevisionNotFound, response.CodeRevisionNotFound},
+	{storage.ErrWebhookNotFound, response.CodeWebhookNotFound},
+	{storage.ErrDeliveryNotFound, response.CodeDeliveryNotFound},
+	{metadata.ErrFetch, response.CodeFetchFailed},
+}
+
+// problem answers the request with an application/problem+json error.
+func problem(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	writeProblem(w, r, response.NewProblem(status, code, detail))
+}
+
+// errorProblem answers the request with one of the errors in errorCodes.
+func errorProblem(w http.ResponseWriter, r *http.Request, status int, err error) {
+	code := response.CodeInternal
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			code = c.code
+			break
+		}
+	}
+
+	problem(w, r, status, code, err.Error())
+}
+
+// invalidRequest answers a request body rejected by validate, listing the
+// invalid fields.
+func invalidRequest(w http.ResponseWriter, r *http.Request, err error) {
+	p := response.NewProblem(http.StatusBadRequest, response.CodeValidationFailed, "request validation failed")
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for _, fe := range validationErrors {
+			p.Errors = append(p.Errors, response.FieldError{
+				Field:   fieldPath(fe),
+				Rule:    fe.Tag(),
+				Param:   fe.Param(),
+				Message: fieldMessage(fe),
+			})
+		}
+	}
+
+	writeProblem(w, r, p)
+}
+
+// invalidParam answers a request whose path parameter isn't a valid id.
+func invalidParam(w http.ResponseWriter, r *http.Request, name string) {
+	p := response.NewProblem(http.StatusBadRequest, response.CodeInvalidParameter, "invalid "+name)
+	p.Errors = []response.FieldError{{
+		Field:   name,
+		Rule:    "number",
+		Message: "must be a number",
+	}}
+
+	writeProblem(w, r, p)
+}
+
+// NotFound answers requests to unknown routes.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	problem(w, r, http.StatusNotFound, response.CodeRouteNotFound, "route not found")
+}
+
+// MethodNotAllowed answers requests to known routes with another method.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	problem(w, r, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "method not allowed")
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, p *response.Problem) {
+	p.Instance = r.URL.Path
+
+	render.Status(r, p.Status)
+	w.Header().Set("Content-Type", response.ProblemContentType)
+	w.WriteHeader(p.Status)
+
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		log(r.Context()).Error("failed to write problem", logger.Error(err))
+	}
+}
+
+// fieldPath returns the path of the field in the request body, dropping the
+// name of the request struct.
+func fieldPath(fe validator.FieldError) string {
+	_, path, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+
+	return path
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	unit := "characters"
+	if fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map {
+		unit = "items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "url":
+		return "must be a valid URL"
+	case "min":
+		return fmt.Sprintf("must have at least %s %s", fe.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must have at most %s %s", fe.Param(), unit)
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	default:
+		return fmt.Sprintf("failed the %s rule", fe.Tag())
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type BookmarkPurger interface {
+	PurgeBookmark(ctx context.Context, id int) error
+}
+
+func PurgeBookmark(purger BookmarkPurger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		parsedId, err := strconv.Atoi(id)
+		if err != nil {
+			log(r.Context()).Error("failed to convert id to integer", logger.Error(err))
+
+			invalidParam(w, r, "id")
+			return
+		}
+
+		err = purger.PurgeBookmark(r.Context(), parsedId)
+		if errors.Is(err, storage.ErrNotFound) {
+			log(r.Context()).Info(storage.ErrNotFound.Error(), slog.String("id", id))
+
+			errorProblem(w, r, http.StatusNotFound, storage.ErrNotFound)
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to purge bookmark", logger.Error(err))
+
+			internalError(w, r, "failed to purge bookmark")
+			return
+		}
+
+		log(r.Context()).Info("bookmark sucessfully purged", slog.String("id", id))
+		render.JSON(w, r, response.Response{
+			Data: "bookmark sucessfully purged",
+		})
+	}
+}
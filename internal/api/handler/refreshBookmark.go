@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/metadata"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type BookmarkRefresher interface {
+	RefreshBookmark(ctx context.Context, id int) (*model.Bookmark, error)
+}
+
+func RefreshBookmark(refresher BookmarkRefresher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		parsedId, err := strconv.Atoi(id)
+		if err != nil {
+			log(r.Context()).Error("failed to get id from url", logger.Error(err))
+
+			invalidParam(w, r, "id")
+			return
+		}
+
+		refreshed, err := refresher.RefreshBookmark(r.Context(), parsedId)
+		if errors.Is(err, storage.ErrNotFound) {
+			log(r.Context()).Info(storage.ErrNotFound.Error(), slog.String("id", id))
+
+			errorProblem(w, r, http.StatusNotFound, storage.ErrNotFound)
+			return
+		}
+		if errors.Is(err, metadata.ErrFetch) {
+			log(r.Context()).Info("failed to fetch bookmark page", slog.String("id", id), logger.Error(err))
+
+			errorProblem(w, r, http.StatusBadGateway, metadata.ErrFetch)
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to refresh bookmark", logger.Error(err))
+
+			internalError(w, r, "failed to refresh bookmark")
+			return
+		}
+
+		log(r.Context()).Info("bookmark sucessfully refreshed", slog.Int("id", refreshed.ID))
+		render.JSON(w, r, response.Response{
+			Data: refreshed,
+		})
+	}
+}
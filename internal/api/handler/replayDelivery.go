@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type DeliveryReplayer interface {
+	ReplayDelivery(ctx context.Context, webhookID int, deliveryID int64) (*model.WebhookDelivery, error)
+}
+
+func ReplayDelivery(replayer DeliveryReplayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		parsedId, err := strconv.Atoi(id)
+		if err != nil {
+			log(r.Context()).Error("failed to get id from url", logger.Error(err))
+
+			invalidParam(w, r, "id")
+			return
+		}
+
+		deliveryID := chi.URLParam(r, "deliveryID")
+		parsedDeliveryID, err := strconv.ParseInt(deliveryID, 10, 64)
+		if err != nil {
+			log(r.Context()).Error("failed to get delivery id from url", logger.Error(err))
+
+			invalidParam(w, r, "deliveryID")
+			return
+		}
+
+		delivery, err := replayer.ReplayDelivery(r.Context(), parsedId, parsedDeliveryID)
+		if errors.Is(err, storage.ErrWebhookNotFound) || errors.Is(err, storage.ErrDeliveryNotFound) {
+			log(r.Context()).Info(err.Error(), slog.String("id", id), slog.String("delivery_id", deliveryID))
+
+			errorProblem(w, r, http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to replay webhook delivery", logger.Error(err))
+
+			internalError(w, r, "failed to replay webhook delivery")
+			return
+		}
+
+		log(r.Context()).Info("webhook delivery sucessfully replayed", slog.String("id", id), slog.Int64("delivery_id", delivery.ID))
+
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, response.Response{
+			Data: delivery,
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+)
+
+// RequestLogger is a middleware that puts a logger into the request context,
+// tagging records with the request id and the route. Authenticate adds the
+// user to it.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := slog.Default().Handler()
+
+		// The route is only known once the request has been routed, so it is
+		// added to records when they are handled.
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			h = &routeHandler{Handler: h, rctx: rctx}
+		}
+
+		l := slog.New(h).With(slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		next.ServeHTTP(w, r.WithContext(logger.WithContext(r.Context(), l)))
+	})
+}
+
+type routeHandler struct {
+	slog.Handler
+	rctx *chi.Context
+}
+
+func (h *routeHandler) Handle(ctx context.Context, r slog.Record) error {
+	if pattern := h.rctx.RoutePattern(); pattern != "" {
+		r.AddAttrs(slog.String("route", pattern))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *routeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &routeHandler{Handler: h.Handler.WithAttrs(attrs), rctx: h.rctx}
+}
+
+func (h *routeHandler) WithGroup(name string) slog.Handler {
+	return &routeHandler{Handler: h.Handler.WithGroup(name), rctx: h.rctx}
+}
+
+// log returns the logger of the request for the api package.
+func log(ctx context.Context) *slog.Logger {
+	return logger.For(ctx, "api")
+}
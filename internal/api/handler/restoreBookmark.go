@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type BookmarkRestorer interface {
+	RestoreBookmark(ctx context.Context, id int) (*model.Bookmark, error)
+}
+
+func RestoreBookmark(restorer BookmarkRestorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		parsedId, err := strconv.Atoi(id)
+		if err != nil {
+			log(r.Context()).Error("failed to convert id to integer", logger.Error(err))
+
+			invalidParam(w, r, "id")
+			return
+		}
+
+		bookmark, err := restorer.RestoreBookmark(r.Context(), parsedId)
+		if errors.Is(err, storage.ErrNotFound) {
+			log(r.Context()).Info(storage.ErrNotFound.Error(), slog.String("id", id))
+
+			errorProblem(w, r, http.StatusNotFound, storage.ErrNotFound)
+			return
+		}
+		if errors.Is(err, storage.ErrExists) {
+			log(r.Context()).Info(storage.ErrExists.Error(), slog.String("id", id))
+
+			errorProblem(w, r, http.StatusConflict, storage.ErrExists)
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to restore bookmark", logger.Error(err))
+
+			internalError(w, r, "failed to restore bookmark")
+			return
+		}
+
+		log(r.Context()).Info("bookmark sucessfully restored", slog.String("id", id))
+		render.JSON(w, r, response.Response{
+			Data: bookmark,
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type BookmarkReverter interface {
+	RevertBookmark(ctx context.Context, id, revision int) (*model.Bookmark, error)
+}
+
+func RevertBookmark(reverter BookmarkReverter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		parsedId, err := strconv.Atoi(id)
+		if err != nil {
+			log(r.Context()).Error("failed to convert id to integer", logger.Error(err))
+
+			invalidParam(w, r, "id")
+			return
+		}
+
+		rev := chi.URLParam(r, "rev")
+		parsedRev, err := strconv.Atoi(rev)
+		if err != nil {
+			log(r.Context()).Error("failed to convert revision to integer", logger.Error(err))
+
+			invalidParam(w, r, "rev")
+			return
+		}
+
+		bookmark, err := reverter.RevertBookmark(r.Context(), parsedId, parsedRev)
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrRevisionNotFound) {
+			log(r.Context()).Info(err.Error(), slog.String("id", id), slog.String("revision", rev))
+
+			errorProblem(w, r, http.StatusNotFound, err)
+			return
+		}
+		if errors.Is(err, storage.ErrExists) {
+			log(r.Context()).Info(storage.ErrExists.Error(), slog.String("id", id), slog.String("revision", rev))
+
+			errorProblem(w, r, http.StatusConflict, storage.ErrExists)
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to revert bookmark", logger.Error(err))
+
+			internalError(w, r, "failed to revert bookmark")
+			return
+		}
+
+		log(r.Context()).Info("bookmark sucessfully reverted", slog.String("id", id), slog.String("revision", rev))
+		render.JSON(w, r, response.Response{
+			Data: bookmark,
+		})
+	}
+}
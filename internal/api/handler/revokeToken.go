@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+type TokenRevoker interface {
+	RevokeToken(ctx context.Context, id int) error
+}
+
+func RevokeToken(revoker TokenRevoker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		parsedId, err := strconv.Atoi(id)
+		if err != nil {
+			log(r.Context()).Error("failed to get id from url", logger.Error(err))
+
+			invalidParam(w, r, "id")
+			return
+		}
+
+		err = revoker.RevokeToken(r.Context(), parsedId)
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			log(r.Context()).Info(storage.ErrTokenNotFound.Error(), slog.String("id", id))
+
+			errorProblem(w, r, http.StatusNotFound, storage.ErrTokenNotFound)
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to revoke token", logger.Error(err))
+
+			internalError(w, r, "failed to revoke token")
+			return
+		}
+
+		log(r.Context()).Info("token sucessfully revoked", slog.String("id", id))
+		render.JSON(w, r, response.Response{
+			Data: "token sucessfully revoked",
+		})
+	}
+}
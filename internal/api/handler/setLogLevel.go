@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/request"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+)
+
+type LevelSetter interface {
+	LevelProvider
+	Set(pkg string, level slog.Level)
+	Reset(pkg string)
+}
+
+// SetLogLevel changes a log level at runtime, until the next restart.
+func SetLogLevel(setter LevelSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var reqData request.LogLevelRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqData); err != nil {
+			log(r.Context()).Error("failed to decode request body", logger.Error(err))
+
+			problem(w, r, http.StatusBadRequest, response.CodeMalformedBody, "failed to decode request body")
+			return
+		}
+
+		if reqData.Level == "" && reqData.Package != "" {
+			setter.Reset(reqData.Package)
+
+			log(r.Context()).Info("log level sucessfully reset", slog.String("package", reqData.Package))
+			render.JSON(w, r, response.Response{
+				Data: logLevels(setter),
+			})
+			return
+		}
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(reqData.Level)); err != nil {
+			log(r.Context()).Info("invalid log level", logger.Error(err))
+
+			problem(w, r, http.StatusBadRequest, response.CodeInvalidLogLevel, "invalid log level")
+			return
+		}
+
+		setter.Set(reqData.Package, level)
+
+		log(r.Context()).Info("log level sucessfully changed",
+			slog.String("package", reqData.Package),
+			slog.String("level", level.String()))
+
+		render.JSON(w, r, response.Response{
+			Data: logLevels(setter),
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+const (
+	defaultSyncLimit = 500
+	maxSyncLimit     = 1000
+)
+
+type ChangeProvider interface {
+	GetChanges(ctx context.Context, since int64, limit int) (*model.ChangeSet, error)
+}
+
+func Sync(provider ChangeProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var since int64
+		if token := r.URL.Query().Get("since"); token != "" {
+			parsed, err := strconv.ParseInt(token, 10, 64)
+			if err != nil || parsed < 0 {
+				log(r.Context()).Error("invalid sync token", slog.String("since", token))
+
+				problem(w, r, http.StatusBadRequest, response.CodeInvalidSyncToken, "invalid sync token")
+				return
+			}
+			since = parsed
+		}
+
+		limit := defaultSyncLimit
+		if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 {
+			limit = min(parsed, maxSyncLimit)
+		}
+
+		changes, err := provider.GetChanges(r.Context(), since, limit)
+		if err != nil {
+			log(r.Context()).Error("failed to get changes from db", logger.Error(err))
+
+			internalError(w, r, "failed to get changes")
+			return
+		}
+
+		log(r.Context()).Info("got changes",
+			slog.Int64("since", since),
+			slog.Int("changes count", len(changes.Bookmarks)),
+			slog.Bool("reset", changes.Reset),
+		)
+
+		render.JSON(w, r, response.Changes{
+			Data:    changes.Bookmarks,
+			Token:   strconv.FormatInt(changes.Since, 10),
+			HasMore: changes.HasMore,
+			Reset:   changes.Reset,
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+type TagProvider interface {
+	GetTags(ctx context.Context) ([]*model.Tag, error)
+}
+
+func Tags(provider TagProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := provider.GetTags(r.Context())
+		if err != nil {
+			log(r.Context()).Error("failed to get tags from db", logger.Error(err))
+
+			internalError(w, r, "failed to get tags")
+			return
+		}
+
+		log(r.Context()).Info("got tags", slog.Int("tags_count", len(result)))
+
+		render.JSON(w, r, response.Response{
+			Data: result,
+		})
+	}
+}
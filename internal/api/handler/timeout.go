@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+)
+
+// timeoutGrace is added to the connection deadlines, so a request that ran
+// out of time can still be answered.
+const timeoutGrace = time.Second
+
+// Timeout is a middleware that gives the request a deadline, after which its
+// storage queries are cancelled. The connection deadlines are moved along,
+// so a route may take longer than the server's read and write timeouts.
+func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			deadline := time.Now().Add(timeout + timeoutGrace)
+
+			// Not every writer supports deadlines, the server timeouts apply then.
+			rc := http.NewResponseController(w)
+			_ = rc.SetReadDeadline(deadline)
+			_ = rc.SetWriteDeadline(deadline)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// contextError answers the request with a 504 when it ran out of time, or a
+// 503 when it was cancelled because the client went away. It reports whether
+// the request context was done.
+func contextError(w http.ResponseWriter, r *http.Request) bool {
+	err := r.Context().Err()
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		log(r.Context()).Warn("request timed out")
+
+		problem(w, r, http.StatusGatewayTimeout, response.CodeTimeout, "request timed out")
+		return true
+	}
+
+	log(r.Context()).Info("request cancelled")
+
+	problem(w, r, http.StatusServiceUnavailable, response.CodeCancelled, "request cancelled")
+	return true
+}
+
+// internalError answers a request that failed on the server's side, unless
+// the failure came from the request context being done.
+func internalError(w http.ResponseWriter, r *http.Request, msg string) {
+	if contextError(w, r) {
+		return
+	}
+
+	problem(w, r, http.StatusInternalServerError, response.CodeInternal, msg)
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+type TokenProvider interface {
+	GetTokens(ctx context.Context) ([]*model.Token, error)
+}
+
+func Tokens(provider TokenProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := provider.GetTokens(r.Context())
+		if err != nil {
+			log(r.Context()).Error("failed to get tokens from db", logger.Error(err))
+
+			internalError(w, r, "failed to get tokens")
+			return
+		}
+
+		log(r.Context()).Info("got tokens", slog.Int("tokens_count", len(result)))
+
+		render.JSON(w, r, response.Response{
+			Data: result,
+		})
+	}
+}
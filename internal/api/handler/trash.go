@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+type TrashProvider interface {
+	GetTrash(ctx context.Context) ([]*model.Bookmark, error)
+}
+
+func Trash(provider TrashProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := provider.GetTrash(r.Context())
+		if err != nil {
+			log(r.Context()).Error("failed to get trash from db", logger.Error(err))
+
+			internalError(w, r, "failed to get trash")
+			return
+		}
+
+		log(r.Context()).Info("got trash", slog.Any("bookmarks count", len(result)))
+
+		render.JSON(w, r, response.Response{
+			Data: result,
+		})
+	}
+}
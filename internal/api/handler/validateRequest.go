@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/haadi-coder/bookmark-manager/internal/api/openapi"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+)
+
+type RequestValidator interface {
+	ValidateRequest(r *http.Request) error
+}
+
+// ValidateRequest is a middleware that rejects requests not matching the
+// OpenAPI document before they reach their handler. Routes the document
+// doesn't describe are passed on.
+func ValidateRequest(validator RequestValidator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := validator.ValidateRequest(r)
+			if err == nil || errors.Is(err, openapi.ErrUnknownRoute) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var validationErr *openapi.ValidationError
+			if errors.As(err, &validationErr) {
+				log(r.Context()).Info("request doesn't match the openapi document", logger.Error(err))
+
+				p := response.NewProblem(http.StatusBadRequest, response.CodeValidationFailed, "request validation failed")
+				p.Errors = validationErr.Fields
+				writeProblem(w, r, p)
+				return
+			}
+
+			log(r.Context()).Error("failed to validate request", logger.Error(err))
+
+			internalError(w, r, "failed to validate request")
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+// deliveriesLimit caps the delivery log returned at once.
+const deliveriesLimit = 100
+
+type DeliveryProvider interface {
+	GetWebhookDeliveries(ctx context.Context, webhookID, limit int) ([]*model.WebhookDelivery, error)
+}
+
+func WebhookDeliveries(provider DeliveryProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		parsedId, err := strconv.Atoi(id)
+		if err != nil {
+			log(r.Context()).Error("failed to get id from url", logger.Error(err))
+
+			invalidParam(w, r, "id")
+			return
+		}
+
+		result, err := provider.GetWebhookDeliveries(r.Context(), parsedId, deliveriesLimit)
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			log(r.Context()).Info(storage.ErrWebhookNotFound.Error(), slog.String("id", id))
+
+			errorProblem(w, r, http.StatusNotFound, storage.ErrWebhookNotFound)
+			return
+		}
+		if err != nil {
+			log(r.Context()).Error("failed to get webhook deliveries from db", logger.Error(err))
+
+			internalError(w, r, "failed to get webhook deliveries")
+			return
+		}
+
+		log(r.Context()).Info("got webhook deliveries", slog.String("id", id), slog.Int("deliveries count", len(result)))
+
+		render.JSON(w, r, response.Response{
+			Data: result,
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+type WebhookProvider interface {
+	GetWebhooks(ctx context.Context) ([]*model.Webhook, error)
+}
+
+func Webhooks(provider WebhookProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := provider.GetWebhooks(r.Context())
+		if err != nil {
+			log(r.Context()).Error("failed to get webhooks from db", logger.Error(err))
+
+			internalError(w, r, "failed to get webhooks")
+			return
+		}
+
+		log(r.Context()).Info("got webhooks", slog.Any("webhooks count", len(result)))
+
+		render.JSON(w, r, response.Response{
+			Data: result,
+		})
+	}
+}
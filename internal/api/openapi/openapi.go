@@ -0,0 +1,62 @@
+// Package openapi holds the OpenAPI document of the API. It serves the
+// document with Swagger UI and validates requests against it.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+)
+
+//go:embed openapi.yaml
+var spec []byte
+
+var load = sync.OnceValues(func() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load openapi document: %w", err)
+	}
+
+	return doc, nil
+})
+
+var marshal = sync.OnceValues(func() ([]byte, error) {
+	doc, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(doc)
+})
+
+// Load returns the OpenAPI document of the API.
+func Load() (*openapi3.T, error) {
+	return load()
+}
+
+// Handler serves the OpenAPI document as JSON.
+func Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := marshal()
+		if err != nil {
+			log(r.Context()).Error("failed to marshal openapi document", logger.Error(err))
+
+			http.Error(w, "failed to marshal openapi document", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}
+}
+
+func log(ctx context.Context) *slog.Logger {
+	return logger.For(ctx, "openapi")
+}
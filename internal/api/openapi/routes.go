@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi"
+)
+
+// undocumented are the routes left out of the document on purpose: the
+// Prometheus endpoint and the Swagger UI pages.
+var undocumented = []string{"/metrics", "/api/v1/docs", "/api/v1/docs/*"}
+
+// CheckRoutes compares the routes of the router with the document. It
+// returns the routes the document misses and the operations no route
+// serves.
+func CheckRoutes(routes chi.Routes) ([]string, error) {
+	doc, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	registered := map[string]bool{}
+	err = chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if slices.Contains(undocumented, route) {
+			return nil
+		}
+
+		if trimmed := strings.TrimSuffix(route, "/"); trimmed != "" {
+			route = trimmed
+		}
+		registered[method+" "+route] = true
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk routes: %w", err)
+	}
+
+	documented := map[string]bool{}
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+
+	var mismatches []string
+	for route := range registered {
+		if !documented[route] {
+			mismatches = append(mismatches, "route is not documented: "+route)
+		}
+	}
+	for operation := range documented {
+		if !registered[operation] {
+			mismatches = append(mismatches, "operation has no route: "+operation)
+		}
+	}
+	slices.Sort(mismatches)
+
+	return mismatches, nil
+}
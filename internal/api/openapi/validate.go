@@ -0,0 +1,177 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+)
+
+// ErrUnknownRoute is returned for requests the document doesn't describe,
+// the router answers those.
+var ErrUnknownRoute = errors.New("route is not described by the openapi document")
+
+// ValidationError is returned for requests that don't match the document.
+type ValidationError struct {
+	Fields []response.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+
+	return "request validation failed: " + strings.Join(msgs, ", ")
+}
+
+type Validator struct {
+	router  routers.Router
+	options *openapi3filter.Options
+}
+
+func NewValidator() (*Validator, error) {
+	doc, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to route openapi document: %w", err)
+	}
+
+	return &Validator{
+		router: router,
+		options: &openapi3filter.Options{
+			MultiError: true,
+			// Credentials are checked by the authentication middleware.
+			AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+			SkipSettingDefaults: true,
+		},
+	}, nil
+}
+
+// ValidateRequest checks the parameters and the JSON body of the request.
+// The body is read and replaced, handlers can still decode it.
+func (v *Validator) ValidateRequest(r *http.Request) error {
+	// The document has no trailing slashes, which the router ignores.
+	routed := r
+	if path := strings.TrimSuffix(r.URL.Path, "/"); path != r.URL.Path && path != "" {
+		routed = r.Clone(r.Context())
+		routed.URL.Path = path
+	}
+
+	route, params, err := v.router.FindRoute(routed)
+	if errors.Is(err, routers.ErrPathNotFound) || errors.Is(err, routers.ErrMethodNotAllowed) {
+		return ErrUnknownRoute
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find route: %w", err)
+	}
+
+	// Uploads are left to their handler, which caps their size instead of
+	// reading them whole.
+	options := v.options
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		withoutBody := *options
+		withoutBody.ExcludeRequestBody = true
+		options = &withoutBody
+	}
+
+	err = openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: params,
+		Route:      route,
+		Options:    options,
+	})
+	if err != nil {
+		// Parameters and properties are validated in map order.
+		fields := fieldErrors(err, "")
+		slices.SortStableFunc(fields, func(a, b response.FieldError) int {
+			return strings.Compare(a.Field, b.Field)
+		})
+
+		return &ValidationError{Fields: fields}
+	}
+
+	return nil
+}
+
+// fieldErrors flattens the errors of the validation, field is the parameter
+// or body field they were found in.
+func fieldErrors(err error, field string) []response.FieldError {
+	switch err := err.(type) {
+	case openapi3.MultiError:
+		var fields []response.FieldError
+		for _, err := range err {
+			fields = append(fields, fieldErrors(err, field)...)
+		}
+
+		return fields
+
+	case *openapi3filter.RequestError:
+		field := "body"
+		if err.Parameter != nil {
+			field = err.Parameter.Name
+		}
+
+		if err.Err == nil {
+			return []response.FieldError{{Field: field, Rule: "request", Message: err.Reason}}
+		}
+
+		return fieldErrors(err.Err, field)
+
+	case *openapi3.SchemaError:
+		if path := fieldPath(err.JSONPointer()); path != "" {
+			if field == "body" {
+				field = path
+			} else {
+				field += "." + path
+			}
+		}
+
+		return []response.FieldError{{Field: field, Rule: err.SchemaField, Message: err.Reason}}
+
+	case *openapi3filter.ParseError:
+		if cause := err.RootCause(); cause != nil {
+			var schemaErr *openapi3.SchemaError
+			if errors.As(cause, &schemaErr) {
+				return fieldErrors(schemaErr, field)
+			}
+		}
+
+		return []response.FieldError{{Field: field, Rule: "format", Message: err.Error()}}
+
+	default:
+		return []response.FieldError{{Field: field, Rule: "request", Message: err.Error()}}
+	}
+}
+
+// fieldPath turns a JSON pointer into the field paths used by the handlers,
+// as in tags[1].
+func fieldPath(pointer []string) string {
+	var b strings.Builder
+	for _, p := range pointer {
+		if _, err := strconv.Atoi(p); err == nil {
+			b.WriteString("[" + p + "]")
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(p)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package request
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type cursorPayload struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int       `json:"i"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+// EncodeCursor turns a keyset position into an opaque token for clients.
+func EncodeCursor(c *storage.Cursor) string {
+	if c == nil {
+		return ""
+	}
+
+	data, _ := json.Marshal(cursorPayload{
+		CreatedAt: c.CreatedAt,
+		ID:        c.ID,
+		Backward:  c.Backward,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(token string) (*storage.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var p cursorPayload
+	if err := json.Unmarshal(data, &p); err != nil || p.ID <= 0 || p.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+
+	return &storage.Cursor{
+		CreatedAt: p.CreatedAt,
+		ID:        p.ID,
+		Backward:  p.Backward,
+	}, nil
+}
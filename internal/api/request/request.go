@@ -2,25 +2,100 @@ package request
 
 import (
 	"fmt"
-	"math"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
 )
 
 const (
-	DefaultPerpage = math.MaxInt32
+	DefaultPerpage = 50
+	MaxPerpage     = 500
 	DefaultPage    = 1
+
+	TagModeAll = "all"
+	TagModeAny = "any"
 )
 
 type Request struct {
-	Title string `json:"title" validate:"required"`
-	URL   string `json:"url" validate:"required,url"`
+	URL  string   `json:"url" validate:"required,url"`
+	Tags []string `json:"tags" validate:"omitempty,dive,required,max=64"`
+
+	// Title is optional, the page title is fetched in the background when
+	// it's left empty.
+	Title string `json:"title"`
+
+	// FolderID is only used on creation, existing bookmarks are moved
+	// between folders through a dedicated endpoint.
+	FolderID *int `json:"folder_id"`
+}
+
+type FolderRequest struct {
+	Name     string `json:"name" validate:"required,max=255"`
+	ParentID *int   `json:"parent_id"`
+}
+
+type UserRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=64"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+type TokenRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=read write export"`
+}
+
+type WebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=bookmark.created bookmark.updated bookmark.deleted"`
+}
+
+type SyncRequest struct {
+	Changes []SyncChange `json:"changes" validate:"required,max=500,dive"`
+}
+
+// SyncChange is a change made by a sync client. Leaving ID out creates a
+// bookmark, Version is the version the change was based on, zero skips the
+// conflict check.
+type SyncChange struct {
+	// ClientID is echoed back in the result, so that clients can match
+	// bookmarks they created offline.
+	ClientID string   `json:"client_id" validate:"max=100"`
+	ID       *int     `json:"id"`
+	Version  int64    `json:"version" validate:"min=0"`
+	Deleted  bool     `json:"deleted"`
+	URL      string   `json:"url" validate:"omitempty,url"`
+	Title    string   `json:"title"`
+	Tags     []string `json:"tags" validate:"omitempty,dive,required,max=64"`
+	FolderID *int     `json:"folder_id"`
+}
+
+type MoveRequest struct {
+	FolderID *int `json:"folder_id"`
+}
+
+// LogLevelRequest changes the log level of a package, or the default level
+// when Package is empty. An empty level resets the package to the default.
+type LogLevelRequest struct {
+	Package string `json:"package"`
+	Level   string `json:"level"`
 }
 
 type ListOptions struct {
-	Perpage int
-	Page    int
-	Search  string
+	Perpage    int
+	Page       int
+	Search     string
+	Tags       []string
+	TagMode    string
+	FolderID   *int
+	LinkStatus string
+
+	// Cursor takes precedence over Page when both are given.
+	Cursor    *storage.Cursor
+	SkipCount bool
 }
 
 func (p *ListOptions) Offset() int {
@@ -36,19 +111,70 @@ func ParseListOptions(r *http.Request) (*ListOptions, error) {
 		Perpage: DefaultPerpage,
 		Page:    DefaultPage,
 		Search:  search,
+		Tags:    NormalizeTags(r.URL.Query()["tag"]),
+		TagMode: TagModeAll,
+	}
+
+	if folderID, err := strconv.Atoi(r.URL.Query().Get("folder_id")); err == nil {
+		opts.FolderID = &folderID
+	}
+
+	switch status := r.URL.Query().Get("status"); status {
+	case model.LinkStatusOK, model.LinkStatusRedirected, model.LinkStatusBroken:
+		opts.LinkStatus = status
+	}
+
+	if r.URL.Query().Get("tag_mode") == TagModeAny {
+		opts.TagMode = TagModeAny
+	}
+
+	if count, err := strconv.ParseBool(r.URL.Query().Get("count")); err == nil {
+		opts.SkipCount = !count
 	}
 
-	parsedLimit, err := strconv.Atoi(perPage)
-	if err != nil {
-		return opts, fmt.Errorf("failed to convert limit to integer: %w", err)
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		parsedCursor, err := DecodeCursor(cursor)
+		if err != nil {
+			return opts, err
+		}
+		opts.Cursor = parsedCursor
 	}
-	opts.Perpage = parsedLimit
 
-	parsedPage, err := strconv.Atoi(page)
-	if err != nil {
-		return opts, fmt.Errorf("failed to convert page to integer:%w", err)
+	if perPage != "" {
+		parsedLimit, err := strconv.Atoi(perPage)
+		if err != nil || parsedLimit < 1 {
+			return opts, fmt.Errorf("failed to convert limit to positive integer: %s", perPage)
+		}
+		opts.Perpage = min(parsedLimit, MaxPerpage)
+	}
+
+	if page != "" {
+		parsedPage, err := strconv.Atoi(page)
+		if err != nil || parsedPage < 1 {
+			return opts, fmt.Errorf("failed to convert page to positive integer: %s", page)
+		}
+		opts.Page = parsedPage
 	}
-	opts.Page = parsedPage
 
 	return opts, nil
 }
+
+// NormalizeTags lowercases and trims tags, dropping empty and duplicate ones.
+// A nil slice stays nil so callers can tell "no tags given" from "no tags".
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || slices.Contains(normalized, tag) {
+			continue
+		}
+
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
@@ -0,0 +1,79 @@
+package response
+
+import "net/http"
+
+// ProblemContentType is the media type of error responses, see RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// Codes identify problems for clients, unlike details they never change.
+const (
+	CodeMalformedBody     = "malformed_body"
+	CodeValidationFailed  = "validation_failed"
+	CodeInvalidParameter  = "invalid_parameter"
+	CodeInvalidCursor     = "invalid_cursor"
+	CodeInvalidSyncToken  = "invalid_sync_token"
+	CodeInvalidImportFile = "invalid_import_file"
+	CodeImportTooLarge    = "import_too_large"
+	CodeInvalidLogLevel   = "invalid_log_level"
+
+	CodeUnauthorized      = "unauthorized"
+	CodeInsufficientScope = "insufficient_scope"
+	CodeSignupDisabled    = "signup_disabled"
+
+	CodeRouteNotFound    = "route_not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+
+	CodeBookmarkNotFound = "bookmark_not_found"
+	CodeBookmarkExists   = "bookmark_exists"
+	CodeFolderNotFound   = "folder_not_found"
+	CodeFolderCycle      = "folder_cycle"
+	CodeUserExists       = "user_exists"
+	CodeTokenNotFound    = "token_not_found"
+	CodeRevisionNotFound = "revision_not_found"
+	CodeWebhookNotFound  = "webhook_not_found"
+	CodeDeliveryNotFound = "delivery_not_found"
+	CodeFetchFailed      = "fetch_failed"
+
+	CodeInternal  = "internal_error"
+	CodeTimeout   = "timeout"
+	CodeCancelled = "cancelled"
+)
+
+// Problem describes why a request failed, as application/problem+json.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+
+	// Error repeats Detail for clients reading the former {"error": ...}
+	// responses.
+	//
+	// Deprecated: read Code and Detail instead.
+	Error string `json:"error"`
+}
+
+// FieldError is a single invalid field of the request. Field is the path of
+// the field in the request body, or the name of the path parameter.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// NewProblem returns a problem without a specific type, titled after the
+// status.
+func NewProblem(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+		Error:  detail,
+	}
+}
@@ -1,21 +1,66 @@
 package response
 
 type Response struct {
-	Data  any    `json:"data,omitempty"`
-	Error string `json:"error,omitempty"`
+	Data any `json:"data,omitempty"`
 }
 
-func Error(msg string) *Response {
-	return &Response{
-		Error: msg,
-	}
+// Page is a single page of a cursor paginated list. Total is left out when
+// the client opted out of counting.
+type Page struct {
+	Data       any    `json:"data"`
+	Total      *int   `json:"total,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// Changes is a batch of the delta sync feed. Token is passed back as since
+// to get the next batch.
+type Changes struct {
+	Data    any    `json:"data"`
+	Token   string `json:"token"`
+	HasMore bool   `json:"has_more"`
+	Reset   bool   `json:"reset"`
+}
+
+const (
+	SyncStatusApplied  = "applied"
+	SyncStatusConflict = "conflict"
+	SyncStatusNotFound = "not_found"
+	SyncStatusInvalid  = "invalid"
+	SyncStatusFailed   = "failed"
+)
+
+// SyncResult is the outcome of a single client change. On conflict Bookmark
+// holds the server version.
+type SyncResult struct {
+	ClientID string `json:"client_id,omitempty"`
+	Status   string `json:"status"`
+	Bookmark any    `json:"bookmark,omitempty"`
+	Error    string `json:"error,omitempty"`
 }
 
 type HealthResponse struct {
-	Status string       `json:"status"`
-	Checks HealthChecks `json:"checks"`
+	Status string        `json:"status"`
+	Checks HealthChecks  `json:"checks"`
+	Schema *SchemaHealth `json:"schema,omitempty"`
 }
 
 type HealthChecks struct {
 	Postgres string `json:"postgres"`
+	Schema   string `json:"schema,omitempty"`
+}
+
+// SchemaHealth compares the database schema version to the latest migration
+// known to the binary.
+type SchemaHealth struct {
+	Version uint `json:"version"`
+	Latest  uint `json:"latest"`
+	Dirty   bool `json:"dirty"`
+}
+
+// LogLevels are the default log level and the levels set for single
+// packages.
+type LogLevels struct {
+	Level    string            `json:"level"`
+	Packages map[string]string `json:"packages"`
 }
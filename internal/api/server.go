@@ -13,6 +13,11 @@ import (
 	"github.com/go-chi/httplog/v3"
 	"github.com/go-chi/httprate"
 	"github.com/haadi-coder/bookmark-manager/internal/api/handler"
+	"github.com/haadi-coder/bookmark-manager/internal/api/openapi"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/metrics"
+	"github.com/haadi-coder/bookmark-manager/internal/tracing"
 )
 
 const (
@@ -29,21 +34,94 @@ type ServerConfig struct {
 	Timeout     time.Duration
 	IdleTimeout time.Duration
 
+	// RequestTimeout is the deadline of API requests. Import and export run
+	// with TransferTimeout, event streams without a deadline.
+	RequestTimeout  time.Duration
+	TransferTimeout time.Duration
+
+	// Metrics instruments requests when set. ServeMetrics exposes them on
+	// /metrics, unless they are served on an admin listener.
+	Metrics      *metrics.Metrics
+	ServeMetrics bool
+	// Tracing starts a span for every request, named after its route.
+	Tracing bool
+	// RequestValidator rejects API requests that don't match the OpenAPI
+	// document when set.
+	RequestValidator handler.RequestValidator
+
 	BookmarkProvider handler.BookmarkProvider
 	BookmarkChecker  handler.BookmarkChecker
 	BookmarkDeleter  handler.BookmarkRemover
 	BookmarkPinger   handler.Pinger
+	SchemaChecker    handler.SchemaChecker
 	BookmarkEditor   handler.BookmarkEditor
 	BookmarkCreator  handler.BookmarkCreator
+	BookmarkImporter handler.BookmarkImporter
+	TagProvider      handler.TagProvider
+
+	FolderProvider handler.FolderProvider
+	FolderCreator  handler.FolderCreator
+	FolderEditor   handler.FolderEditor
+	FolderDeleter  handler.FolderRemover
+	BookmarkMover  handler.BookmarkMover
+
+	UserProvider handler.UserProvider
+	UserCreator  handler.UserCreator
+	AllowSignup  bool
+
+	TokenAuthenticator handler.TokenAuthenticator
+	TokenProvider      handler.TokenProvider
+	TokenCreator       handler.TokenCreator
+	TokenRevoker       handler.TokenRevoker
+
+	MetadataQueue     handler.MetadataQueue
+	BookmarkRefresher handler.BookmarkRefresher
+
+	LinkReporter handler.LinkReporter
+
+	TrashProvider    handler.TrashProvider
+	BookmarkRestorer handler.BookmarkRestorer
+	BookmarkPurger   handler.BookmarkPurger
+
+	HistoryProvider  handler.HistoryProvider
+	BookmarkReverter handler.BookmarkReverter
+
+	WebhookProvider  handler.WebhookProvider
+	WebhookCreator   handler.WebhookCreator
+	WebhookDeleter   handler.WebhookRemover
+	DeliveryProvider handler.DeliveryProvider
+	DeliveryReplayer handler.DeliveryReplayer
+
+	EventProvider   handler.EventProvider
+	EventSubscriber handler.EventSubscriber
+
+	ChangeProvider handler.ChangeProvider
+	BookmarkSyncer handler.BookmarkSyncer
 }
 
-func NewServer(ctx context.Context, cfg *ServerConfig) *Server {
+func NewServer(cfg *ServerConfig) *Server {
 	router := chi.NewRouter()
 
+	// Set before the middlewares, which chi would otherwise run twice for
+	// these, and before mounting, so the API routes answer with them too.
+	router.NotFound(handler.NotFound)
+	router.MethodNotAllowed(handler.MethodNotAllowed)
+
+	// Event streams never go idle on their own, they are ended when the
+	// server starts shutting down.
+	streamsCtx, closeStreams := context.WithCancel(context.Background())
+
 	router.Use(middleware.RequestID)
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.RealIP)
-	router.Use(httplog.RequestLogger(slog.Default(), &httplog.Options{
+	if cfg.Tracing {
+		router.Use(tracing.Middleware)
+	}
+	if cfg.Metrics != nil {
+		router.Use(cfg.Metrics.Middleware)
+	}
+	router.Use(handler.RequestLogger)
+	router.Use(httplog.RequestLogger(slog.Default().With(slog.String(logger.PackageKey, "http")), &httplog.Options{
 		Schema: &httplog.Schema{
 			ErrorType:     "err_type",
 			ErrorMessage:  "err_msg",
@@ -51,6 +129,9 @@ func NewServer(ctx context.Context, cfg *ServerConfig) *Server {
 			ResponseBytes: "resp",
 		},
 		RecoverPanics: true,
+		LogExtraAttrs: func(r *http.Request, _ string, _ int) []slog.Attr {
+			return []slog.Attr{slog.String("request_id", middleware.GetReqID(r.Context()))}
+		},
 	}))
 	router.Use(cors.Handler(cors.Options{
 		// Using wildcard "*" is intentional to support local development
@@ -59,11 +140,11 @@ func NewServer(ctx context.Context, cfg *ServerConfig) *Server {
 		// While this weakens CORS security, the trade-off is acceptable in a local/development context.
 		AllowedOrigins: []string{"*"},
 		AllowedMethods: []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type"},
 		ExposedHeaders: []string{"X-Total"},
 	}))
-	router.Use(httprate.Limit(
-		reqLimit,
-		reqWindow,
+
+	rateLimitOptions := []httprate.Option{
 		httprate.WithKeyFuncs(httprate.KeyByEndpoint),
 		httprate.WithResponseHeaders(httprate.ResponseHeaders{
 			Limit:      "X-RateLimit-Limit",
@@ -71,18 +152,103 @@ func NewServer(ctx context.Context, cfg *ServerConfig) *Server {
 			Reset:      "X-RateLimit-Reset",
 			RetryAfter: "Retry-After",
 		}),
-	))
+	}
+	if cfg.Metrics != nil {
+		rateLimitOptions = append(rateLimitOptions, httprate.WithLimitHandler(cfg.Metrics.RateLimited))
+	}
+	router.Use(httprate.Limit(reqLimit, reqWindow, rateLimitOptions...))
+
+	router.With(handler.Timeout(cfg.RequestTimeout)).Get("/health", handler.CheckHealth(cfg.BookmarkPinger, cfg.SchemaChecker))
+	if cfg.Metrics != nil && cfg.ServeMetrics {
+		router.Handle("/metrics", cfg.Metrics.Handler())
+	}
 
-	router.Get("/health", handler.CheckHealth(cfg.BookmarkPinger))
+	authenticate := handler.Authenticate(cfg.UserProvider, cfg.TokenAuthenticator)
+	read := handler.RequireScope(auth.ScopeRead)
+	write := handler.RequireScope(auth.ScopeWrite)
+	export := handler.RequireScope(auth.ScopeExport)
 
 	apiV1Router := chi.NewRouter()
-	apiV1Router.Route("/bookmarks", func(r chi.Router) {
-		r.Get("/", handler.Bookmarks(ctx, cfg.BookmarkProvider))
-		r.Post("/", handler.CreateBookmark(ctx, cfg.BookmarkCreator))
-		r.Patch("/{id}", handler.EditBookmark(ctx, cfg.BookmarkEditor))
-		r.Delete("/{id}", handler.DeleteBookmark(ctx, cfg.BookmarkDeleter))
-		r.Get("/exists", handler.CheckBookmark(ctx, cfg.BookmarkChecker))
-		r.Get("/export/html", handler.NetscapeBookmarks(ctx, cfg.BookmarkProvider))
+	if cfg.RequestValidator != nil {
+		apiV1Router.Use(handler.ValidateRequest(cfg.RequestValidator))
+	}
+
+	apiV1Router.Group(func(r chi.Router) {
+		r.Use(handler.Timeout(cfg.RequestTimeout))
+
+		r.Get("/openapi.json", openapi.Handler())
+		r.Get("/docs", http.RedirectHandler("/api/v1/docs/", http.StatusMovedPermanently).ServeHTTP)
+		r.Handle("/docs/*", http.StripPrefix("/api/v1/docs", openapi.Docs("/api/v1/openapi.json")))
+
+		r.Post("/users", handler.CreateUser(cfg.UserCreator, cfg.AllowSignup))
+
+		r.Group(func(r chi.Router) {
+			r.Use(authenticate)
+
+			r.With(read).Get("/users/me", handler.CurrentUser())
+
+			r.Route("/bookmarks", func(r chi.Router) {
+				r.With(read).Get("/", handler.Bookmarks(cfg.BookmarkProvider))
+				r.With(write).Post("/", handler.CreateBookmark(cfg.BookmarkCreator, cfg.MetadataQueue))
+				r.With(write).Patch("/{id}", handler.EditBookmark(cfg.BookmarkEditor, cfg.MetadataQueue))
+				r.With(write).Delete("/{id}", handler.DeleteBookmark(cfg.BookmarkDeleter))
+				r.With(write).Post("/{id}/move", handler.MoveBookmark(cfg.BookmarkMover))
+				r.With(write).Post("/{id}/refresh", handler.RefreshBookmark(cfg.BookmarkRefresher))
+				r.With(read).Get("/{id}/history", handler.BookmarkHistory(cfg.HistoryProvider))
+				r.With(write).Post("/{id}/revert/{rev}", handler.RevertBookmark(cfg.BookmarkReverter))
+				r.With(read).Get("/exists", handler.CheckBookmark(cfg.BookmarkChecker))
+			})
+
+			r.Route("/folders", func(r chi.Router) {
+				r.With(read).Get("/", handler.Folders(cfg.FolderProvider))
+				r.With(write).Post("/", handler.CreateFolder(cfg.FolderCreator))
+				r.With(write).Patch("/{id}", handler.EditFolder(cfg.FolderEditor))
+				r.With(write).Delete("/{id}", handler.DeleteFolder(cfg.FolderDeleter))
+			})
+
+			r.Route("/trash", func(r chi.Router) {
+				r.With(read).Get("/", handler.Trash(cfg.TrashProvider))
+				r.With(write).Post("/{id}/restore", handler.RestoreBookmark(cfg.BookmarkRestorer))
+				r.With(write).Delete("/{id}", handler.PurgeBookmark(cfg.BookmarkPurger))
+			})
+
+			r.Route("/webhooks", func(r chi.Router) {
+				r.With(read).Get("/", handler.Webhooks(cfg.WebhookProvider))
+				r.With(write).Post("/", handler.CreateWebhook(cfg.WebhookCreator))
+				r.With(write).Delete("/{id}", handler.DeleteWebhook(cfg.WebhookDeleter))
+				r.With(read).Get("/{id}/deliveries", handler.WebhookDeliveries(cfg.DeliveryProvider))
+				r.With(write).Post("/{id}/deliveries/{deliveryID}/replay", handler.ReplayDelivery(cfg.DeliveryReplayer))
+			})
+
+			r.With(read).Get("/sync", handler.Sync(cfg.ChangeProvider))
+			r.With(write).Post("/sync", handler.ApplySync(cfg.BookmarkSyncer, cfg.MetadataQueue))
+			r.With(read).Get("/tags", handler.Tags(cfg.TagProvider))
+			r.With(read).Get("/links/report", handler.LinkReport(cfg.LinkReporter))
+
+			r.Route("/tokens", func(r chi.Router) {
+				r.Use(handler.RequireScope(auth.ScopeTokens))
+
+				r.Get("/", handler.Tokens(cfg.TokenProvider))
+				r.Post("/", handler.CreateToken(cfg.TokenCreator))
+				r.Delete("/{id}", handler.RevokeToken(cfg.TokenRevoker))
+			})
+		})
+	})
+
+	// Import and export walk all bookmarks of the user, so they get a deadline
+	// of their own. The routes take precedence over the /bookmarks subrouter.
+	apiV1Router.Group(func(r chi.Router) {
+		r.Use(handler.Timeout(cfg.TransferTimeout))
+		r.Use(authenticate)
+
+		r.With(export).Get("/bookmarks/export/html", handler.NetscapeBookmarks(cfg.BookmarkProvider, cfg.FolderProvider))
+		r.With(write).Post("/bookmarks/import/html", handler.ImportNetscapeBookmarks(cfg.BookmarkImporter))
+	})
+
+	apiV1Router.Group(func(r chi.Router) {
+		r.Use(authenticate)
+
+		r.With(read).Get("/events", handler.Events(cfg.EventProvider, cfg.EventSubscriber, streamsCtx.Done()))
 	})
 
 	router.Mount("/api/v1", apiV1Router)
@@ -94,12 +260,18 @@ func NewServer(ctx context.Context, cfg *ServerConfig) *Server {
 		WriteTimeout: cfg.Timeout,
 		IdleTimeout:  cfg.IdleTimeout,
 	}
+	s.RegisterOnShutdown(closeStreams)
 
 	return &Server{
 		server: s,
 	}
 }
 
+// ServeHTTP serves a request without listening, as in tests.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.server.Handler.ServeHTTP(w, r)
+}
+
 func (s *Server) Run(ctx context.Context) error {
 	slog.Info("HTTP server starting", slog.String("address", s.server.Addr))
 
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/haadi-coder/bookmark-manager/internal/api/openapi"
+)
+
+// TestRoutesDocumented fails when a route is added or removed without
+// updating the OpenAPI document.
+func TestRoutesDocumented(t *testing.T) {
+	srv := NewServer(&ServerConfig{})
+
+	mismatches, err := openapi.CheckRoutes(srv.server.Handler.(chi.Routes))
+	if err != nil {
+		t.Fatalf("CheckRoutes() error = %v", err)
+	}
+
+	for _, mismatch := range mismatches {
+		t.Error(mismatch)
+	}
+}
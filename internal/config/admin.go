@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// AdminConfig is the listener serving /metrics and the log level endpoint,
+// kept away from the public API. A zero port disables it.
+type AdminConfig struct {
+	Host string `env:"HOST" env-default:"0.0.0.0"`
+	Port int    `env:"PORT" env-default:"0"`
+}
+
+func (c *AdminConfig) Address() string {
+	if c.Port == 0 {
+		return ""
+	}
+
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+func (c *AdminConfig) Validate() error {
+	if c.Port != 0 {
+		if err := ValidatePort(c.Port); err != nil {
+			return fmt.Errorf("failed to validate admin port: %w", err)
+		}
+	}
+
+	return nil
+}
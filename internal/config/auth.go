@@ -0,0 +1,5 @@
+package config
+
+type AuthConfig struct {
+	AllowSignup bool `env:"ALLOW_SIGNUP" env-default:"true"`
+}
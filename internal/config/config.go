@@ -7,10 +7,21 @@ import (
 )
 
 type Config struct {
-	DB      DBConfig   `env-prefix:"BM_DB_"`
-	HTTP    HttpConfig `env-prefix:"BM_HTTP_"`
-	NoColor bool       `env:"BM_NO_COLOR" env-default:"false"`
-	Debug   bool       `env:"BM_DEBUG" env-default:"true"`
+	Storage   StorageConfig
+	DB        DBConfig        `env-prefix:"BM_DB_"`
+	HTTP      HttpConfig      `env-prefix:"BM_HTTP_"`
+	Auth      AuthConfig      `env-prefix:"BM_AUTH_"`
+	Metadata  MetadataConfig  `env-prefix:"BM_METADATA_"`
+	LinkCheck LinkCheckConfig `env-prefix:"BM_LINKCHECK_"`
+	Search    SearchConfig    `env-prefix:"BM_SEARCH_"`
+	Trash     TrashConfig     `env-prefix:"BM_TRASH_"`
+	Webhook   WebhookConfig   `env-prefix:"BM_WEBHOOK_"`
+	Metrics   MetricsConfig   `env-prefix:"BM_METRICS_"`
+	Tracing   TracingConfig   `env-prefix:"BM_TRACING_"`
+	Admin     AdminConfig     `env-prefix:"BM_ADMIN_"`
+	Log       LogConfig       `env-prefix:"BM_LOG_"`
+	NoColor   bool            `env:"BM_NO_COLOR" env-default:"false"`
+	Debug     bool            `env:"BM_DEBUG" env-default:"true"`
 }
 
 func Load() (*Config, error) {
@@ -28,14 +39,52 @@ func Load() (*Config, error) {
 }
 
 func (c *Config) Validate() error {
-	if err := c.DB.Validate(); err != nil {
-		return fmt.Errorf("db validation failed: %w", err)
+	if err := c.Storage.Validate(); err != nil {
+		return fmt.Errorf("storage validation failed: %w", err)
+	}
+
+	if c.Storage.Backend == "postgres" {
+		if err := c.DB.Validate(); err != nil {
+			return fmt.Errorf("db validation failed: %w", err)
+		}
 	}
 
 	if err := c.HTTP.Validate(); err != nil {
 		return fmt.Errorf("http validation failed: %w", err)
 	}
 
+	if err := c.Metadata.Validate(); err != nil {
+		return fmt.Errorf("metadata validation failed: %w", err)
+	}
+
+	if err := c.LinkCheck.Validate(); err != nil {
+		return fmt.Errorf("link check validation failed: %w", err)
+	}
+
+	if err := c.Search.Validate(); err != nil {
+		return fmt.Errorf("search validation failed: %w", err)
+	}
+
+	if err := c.Trash.Validate(); err != nil {
+		return fmt.Errorf("trash validation failed: %w", err)
+	}
+
+	if err := c.Webhook.Validate(); err != nil {
+		return fmt.Errorf("webhook validation failed: %w", err)
+	}
+
+	if err := c.Admin.Validate(); err != nil {
+		return fmt.Errorf("admin validation failed: %w", err)
+	}
+
+	if err := c.Log.Validate(); err != nil {
+		return fmt.Errorf("log validation failed: %w", err)
+	}
+
+	if err := c.Tracing.Validate(); err != nil {
+		return fmt.Errorf("tracing validation failed: %w", err)
+	}
+
 	return nil
 }
 
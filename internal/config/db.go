@@ -10,11 +10,14 @@ import (
 
 type DBConfig struct {
 	Host     string `env:"HOST" env-default:"localhost"`
-	User     string `env:"USER" env-required:"true"`
-	Password string `env:"PASSWORD" env-required:"true"`
+	User     string `env:"USER"`
+	Password string `env:"PASSWORD"`
 	Port     int    `env:"PORT" env-default:"5432"`
 	Name     string `env:"NAME" env-default:"bookmarks"`
 	SSLMode  string `env:"SSL_MODE" env-default:"disable"`
+
+	// AutoMigrate applies pending schema migrations on startup.
+	AutoMigrate bool `env:"AUTO_MIGRATE" env-default:"false"`
 }
 
 func (c *DBConfig) DSN() url.URL {
@@ -28,6 +31,10 @@ func (c *DBConfig) DSN() url.URL {
 }
 
 func (c *DBConfig) Validate() error {
+	if c.User == "" || c.Password == "" {
+		return fmt.Errorf("db user and password are required")
+	}
+
 	if err := ValidatePort(c.Port); err != nil {
 		return fmt.Errorf("failed to validate DB port: %w", err)
 	}
@@ -12,6 +12,15 @@ type HttpConfig struct {
 	Port        int           `env:"PORT" env-default:"8080"`
 	IdleTimeout time.Duration `env:"IDLE_TIMEOUT" env-default:"60s"`
 	Timeout     time.Duration `env:"TIMEOUT" env-default:"4s"`
+
+	// RequestTimeout is the deadline of API requests, after which their
+	// queries are cancelled. Import and export get TransferTimeout instead.
+	RequestTimeout  time.Duration `env:"REQUEST_TIMEOUT" env-default:"4s"`
+	TransferTimeout time.Duration `env:"TRANSFER_TIMEOUT" env-default:"2m"`
+
+	// ValidateRequests rejects API requests that don't match the OpenAPI
+	// document before they reach their handler.
+	ValidateRequests bool `env:"VALIDATE_REQUESTS" env-default:"false"`
 }
 
 func (c *HttpConfig) Address() string {
@@ -23,5 +32,13 @@ func (c *HttpConfig) Validate() error {
 		return fmt.Errorf("failed to validate DB port")
 	}
 
+	if c.RequestTimeout <= 0 {
+		return fmt.Errorf("request timeout must be positive, got: %s", c.RequestTimeout)
+	}
+
+	if c.TransferTimeout <= 0 {
+		return fmt.Errorf("transfer timeout must be positive, got: %s", c.TransferTimeout)
+	}
+
 	return nil
 }
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type LinkCheckConfig struct {
+	Enabled     bool          `env:"ENABLED" env-default:"true"`
+	Interval    time.Duration `env:"INTERVAL" env-default:"1h"`
+	MaxAge      time.Duration `env:"MAX_AGE" env-default:"24h"`
+	BatchSize   int           `env:"BATCH_SIZE" env-default:"500"`
+	Concurrency int           `env:"CONCURRENCY" env-default:"4"`
+	HostDelay   time.Duration `env:"HOST_DELAY" env-default:"1s"`
+	Timeout     time.Duration `env:"TIMEOUT" env-default:"10s"`
+}
+
+func (c *LinkCheckConfig) Validate() error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive, got: %s", c.Interval)
+	}
+
+	if c.BatchSize < 1 {
+		return fmt.Errorf("batch size must be positive, got: %d", c.BatchSize)
+	}
+
+	if c.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be positive, got: %d", c.Concurrency)
+	}
+
+	return nil
+}
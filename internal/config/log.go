@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+)
+
+type LogConfig struct {
+	// Format is either "text", colored for terminals, or "json" for log
+	// shippers.
+	Format string `env:"FORMAT" env-default:"text"`
+	// Level overrides BM_DEBUG when set.
+	Level string `env:"LEVEL"`
+	// Packages sets the levels of single packages, like
+	// "storage=debug,webhook=warn".
+	Packages string `env:"PACKAGES"`
+}
+
+func (c *LogConfig) Validate() error {
+	options := []string{"text", "json"}
+
+	if !slices.Contains(options, c.Format) {
+		return fmt.Errorf("invalid log format: %s", c.Format)
+	}
+
+	if c.Level != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+			return fmt.Errorf("invalid log level: %w", err)
+		}
+	}
+
+	if _, err := logger.ParseLevels(c.Packages); err != nil {
+		return fmt.Errorf("invalid package log levels: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type MetadataConfig struct {
+	Workers   int           `env:"WORKERS" env-default:"2"`
+	QueueSize int           `env:"QUEUE_SIZE" env-default:"100"`
+	Timeout   time.Duration `env:"TIMEOUT" env-default:"10s"`
+}
+
+func (c *MetadataConfig) Validate() error {
+	if c.Workers < 1 {
+		return fmt.Errorf("workers must be positive, got: %d", c.Workers)
+	}
+
+	if c.QueueSize < 1 {
+		return fmt.Errorf("queue size must be positive, got: %d", c.QueueSize)
+	}
+
+	return nil
+}
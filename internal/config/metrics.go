@@ -0,0 +1,7 @@
+package config
+
+type MetricsConfig struct {
+	// Enabled serves /metrics on the admin listener, or on the API when
+	// there is none.
+	Enabled bool `env:"ENABLED" env-default:"true"`
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var searchConfigPattern = regexp.MustCompile(`^[a-z_]+$`)
+
+type SearchConfig struct {
+	// Config is the PostgreSQL text search configuration, e.g. "simple",
+	// "english" or "russian".
+	Config string `env:"CONFIG" env-default:"simple"`
+}
+
+func (c *SearchConfig) Validate() error {
+	if !searchConfigPattern.MatchString(c.Config) {
+		return fmt.Errorf("invalid text search config: %s", c.Config)
+	}
+
+	return nil
+}
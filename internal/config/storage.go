@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+type StorageConfig struct {
+	// Backend is one of "postgres", "sqlite" or "memory".
+	Backend string `env:"BM_STORAGE" env-default:"postgres"`
+	// SQLitePath is the database file of the sqlite backend.
+	SQLitePath string `env:"BM_SQLITE_PATH" env-default:"bookmarks.db"`
+}
+
+func (c *StorageConfig) Validate() error {
+	options := []string{"postgres", "sqlite", "memory"}
+
+	if !slices.Contains(options, c.Backend) {
+		return fmt.Errorf("invalid storage backend: %s", c.Backend)
+	}
+
+	if c.Backend == "sqlite" && c.SQLitePath == "" {
+		return fmt.Errorf("sqlite path is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+type TracingConfig struct {
+	// Exporter is one of "none", "otlp" or "stdout".
+	Exporter string `env:"EXPORTER" env-default:"none"`
+	// Endpoint is the host:port of the OTLP/HTTP collector. Empty falls back
+	// to the standard OTEL_EXPORTER_OTLP_* variables.
+	Endpoint    string  `env:"ENDPOINT"`
+	Insecure    bool    `env:"INSECURE" env-default:"true"`
+	SampleRatio float64 `env:"SAMPLE_RATIO" env-default:"1"`
+	ServiceName string  `env:"SERVICE_NAME" env-default:"bookmark-manager"`
+}
+
+func (c *TracingConfig) Validate() error {
+	options := []string{"none", "otlp", "stdout"}
+
+	if !slices.Contains(options, c.Exporter) {
+		return fmt.Errorf("invalid tracing exporter: %s", c.Exporter)
+	}
+
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("sample ratio must be between 0 and 1, got: %g", c.SampleRatio)
+	}
+
+	if c.ServiceName == "" {
+		return fmt.Errorf("service name is required")
+	}
+
+	return nil
+}
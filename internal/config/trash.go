@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type TrashConfig struct {
+	// Retention is how long deleted bookmarks stay restorable.
+	Retention     time.Duration `env:"RETENTION" env-default:"720h"`
+	PurgeInterval time.Duration `env:"PURGE_INTERVAL" env-default:"1h"`
+}
+
+func (c *TrashConfig) Validate() error {
+	if c.Retention <= 0 {
+		return fmt.Errorf("retention must be positive, got: %s", c.Retention)
+	}
+
+	if c.PurgeInterval <= 0 {
+		return fmt.Errorf("purge interval must be positive, got: %s", c.PurgeInterval)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type WebhookConfig struct {
+	PollInterval time.Duration `env:"POLL_INTERVAL" env-default:"5s"`
+	Concurrency  int           `env:"CONCURRENCY" env-default:"4"`
+	MaxAttempts  int           `env:"MAX_ATTEMPTS" env-default:"8"`
+	Backoff      time.Duration `env:"BACKOFF" env-default:"30s"`
+	MaxBackoff   time.Duration `env:"MAX_BACKOFF" env-default:"6h"`
+	Timeout      time.Duration `env:"TIMEOUT" env-default:"10s"`
+}
+
+func (c *WebhookConfig) Validate() error {
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("poll interval must be positive, got: %s", c.PollInterval)
+	}
+
+	if c.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be positive, got: %d", c.Concurrency)
+	}
+
+	if c.MaxAttempts < 1 {
+		return fmt.Errorf("max attempts must be positive, got: %d", c.MaxAttempts)
+	}
+
+	if c.Backoff <= 0 || c.MaxBackoff < c.Backoff {
+		return fmt.Errorf("backoff must be positive and not above max backoff, got: %s and %s", c.Backoff, c.MaxBackoff)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package events
+
+import (
+	"sync"
+)
+
+// Broker wakes up the event streams of a user whenever the storage reports
+// new events for them.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan struct{}]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[int]map[chan struct{}]struct{}),
+	}
+}
+
+// Notify wakes up the streams of the user, or every stream when userID is
+// zero. It matches the callback of storage.Storage.ListenEvents.
+func (b *Broker) Notify(userID int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if userID != 0 {
+		for ch := range b.subscribers[userID] {
+			signal(ch)
+		}
+		return
+	}
+
+	for _, subscribers := range b.subscribers {
+		for ch := range subscribers {
+			signal(ch)
+		}
+	}
+}
+
+// Subscribe returns a channel that receives a signal whenever the user has
+// new events. The returned function has to be called to unsubscribe.
+func (b *Broker) Subscribe(userID int) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan struct{}]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subscribers[userID], ch)
+		if len(b.subscribers[userID]) == 0 {
+			delete(b.subscribers, userID)
+		}
+	}
+}
+
+// signal wakes up a subscriber without blocking, a pending signal already
+// covers the new events.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrNoUser = errors.New("no authenticated user in context")
+
+type userKey struct{}
+
+// WithUser returns a copy of ctx carrying the authenticated user.
+func WithUser(ctx context.Context, user *model.User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// User returns the authenticated user stored in ctx by WithUser.
+func User(ctx context.Context) (*model.User, bool) {
+	user, ok := ctx.Value(userKey{}).(*model.User)
+
+	return user, ok && user != nil
+}
+
+// UserID returns the id of the authenticated user, failing with ErrNoUser
+// when the context doesn't carry one.
+func UserID(ctx context.Context) (int, error) {
+	user, ok := User(ctx)
+	if !ok {
+		return 0, ErrNoUser
+	}
+
+	return user.ID, nil
+}
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
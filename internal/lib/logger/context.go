@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+type ctxKey struct{}
+
+// WithContext returns a copy of ctx carrying l, for FromContext to return.
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	h := l.Handler()
+	if ch, ok := h.(*contextHandler); ok {
+		h = ch.Handler
+	}
+
+	return context.WithValue(ctx, ctxKey{}, h)
+}
+
+// FromContext returns the logger carried by ctx, or the default one. Its
+// records are handled with ctx, so handlers see the values it carries, like
+// the current trace.
+func FromContext(ctx context.Context) *slog.Logger {
+	h, ok := ctx.Value(ctxKey{}).(slog.Handler)
+	if !ok {
+		h = slog.Default().Handler()
+	}
+
+	return slog.New(&contextHandler{Handler: h, ctx: ctx})
+}
+
+// For returns the logger carried by ctx for the given package, whose records
+// are filtered by the level of the package.
+func For(ctx context.Context, pkg string) *slog.Logger {
+	return FromContext(ctx).With(slog.String(PackageKey, pkg))
+}
+
+type contextHandler struct {
+	slog.Handler
+	ctx context.Context
+}
+
+func (h *contextHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.Handler.Enabled(h.ctx, level)
+}
+
+func (h *contextHandler) Handle(_ context.Context, r slog.Record) error {
+	return h.Handler.Handle(h.ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs), ctx: h.ctx}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name), ctx: h.ctx}
+}
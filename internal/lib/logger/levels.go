@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+)
+
+// PackageKey is the attribute naming the package a record comes from. It
+// selects the level the record is filtered by.
+const PackageKey = "package"
+
+// Levels holds the minimum level of records, by package. Packages without a
+// level of their own use the default one. Levels can be changed at runtime.
+type Levels struct {
+	mu       sync.RWMutex
+	level    slog.Level
+	packages map[string]slog.Level
+}
+
+func NewLevels(level slog.Level, packages map[string]slog.Level) *Levels {
+	l := &Levels{
+		level:    level,
+		packages: make(map[string]slog.Level, len(packages)),
+	}
+	maps.Copy(l.packages, packages)
+
+	return l
+}
+
+// Level returns the level of the package, an empty one gets the default.
+func (l *Levels) Level(pkg string) slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if level, ok := l.packages[pkg]; ok {
+		return level
+	}
+
+	return l.level
+}
+
+// Packages returns the levels set for single packages.
+func (l *Levels) Packages() map[string]slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return maps.Clone(l.packages)
+}
+
+// Set changes the level of the package, an empty one changes the default.
+func (l *Levels) Set(pkg string, level slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if pkg == "" {
+		l.level = level
+		return
+	}
+
+	l.packages[pkg] = level
+}
+
+// Reset makes the package use the default level again.
+func (l *Levels) Reset(pkg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.packages, pkg)
+}
+
+// ParseLevels parses a comma separated list of package=level pairs, like
+// "storage=debug,webhook=warn".
+func ParseLevels(spec string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+
+	for pair := range strings.SplitSeq(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		pkg, name, ok := strings.Cut(pair, "=")
+		if !ok || pkg == "" {
+			return nil, fmt.Errorf("invalid package level %q, expected package=level", pair)
+		}
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(name)); err != nil {
+			return nil, fmt.Errorf("invalid level of package %s: %w", pkg, err)
+		}
+
+		levels[pkg] = level
+	}
+
+	return levels, nil
+}
+
+// LevelHandler drops records below the level of the package they come from,
+// as named by a PackageKey attribute of the logger.
+type LevelHandler struct {
+	slog.Handler
+	levels *Levels
+	pkg    string
+}
+
+func NewLevelHandler(h slog.Handler, levels *Levels) *LevelHandler {
+	return &LevelHandler{Handler: h, levels: levels}
+}
+
+func (h *LevelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.levels.Level(h.pkg)
+}
+
+func (h *LevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	pkg := h.pkg
+	for _, attr := range attrs {
+		if attr.Key == PackageKey {
+			pkg = attr.Value.String()
+		}
+	}
+
+	return &LevelHandler{Handler: h.Handler.WithAttrs(attrs), levels: h.levels, pkg: pkg}
+}
+
+func (h *LevelHandler) WithGroup(name string) slog.Handler {
+	return &LevelHandler{Handler: h.Handler.WithGroup(name), levels: h.levels, pkg: h.pkg}
+}
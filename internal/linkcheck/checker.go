@@ -0,0 +1,203 @@
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/tracing"
+)
+
+const userAgent = "bookmark-manager/1.0 (+link checker)"
+
+type Storage interface {
+	GetBookmarksToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*model.Bookmark, error)
+	SetLinkCheck(ctx context.Context, id int, check model.LinkCheck) error
+}
+
+type Config struct {
+	// Interval is the pause between two checking rounds.
+	Interval time.Duration
+	// MaxAge is how long a check result stays fresh.
+	MaxAge time.Duration
+	// BatchSize caps the number of bookmarks checked in one round.
+	BatchSize int
+	// Concurrency caps the number of hosts checked at the same time.
+	Concurrency int
+	// HostDelay is the pause between two requests to the same host.
+	HostDelay time.Duration
+	Timeout   time.Duration
+}
+
+// Checker periodically verifies that bookmark URLs still resolve.
+type Checker struct {
+	cfg     Config
+	storage Storage
+	client  *http.Client
+}
+
+func NewChecker(cfg Config, storage Storage) *Checker {
+	return &Checker{
+		cfg:     cfg,
+		storage: storage,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: tracing.Transport(),
+		},
+	}
+}
+
+// Run checks links round after round until ctx is cancelled.
+func (c *Checker) Run(ctx context.Context) {
+	log(ctx).Info("link checker starting", slog.Duration("interval", c.cfg.Interval))
+
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := c.CheckStale(ctx); err != nil {
+			log(ctx).Error("failed to check links", logger.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckStale checks every bookmark whose last check is older than MaxAge, up
+// to BatchSize of them, and returns how many were checked.
+func (c *Checker) CheckStale(ctx context.Context) (int, error) {
+	bookmarks, err := c.storage.GetBookmarksToCheck(ctx, time.Now().Add(-c.cfg.MaxAge), c.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(bookmarks) == 0 {
+		return 0, nil
+	}
+
+	byHost := make(map[string][]*model.Bookmark)
+	for _, bm := range bookmarks {
+		host := bm.URL
+		if u, err := url.Parse(bm.URL); err == nil {
+			host = u.Host
+		}
+
+		byHost[host] = append(byHost[host], bm)
+	}
+
+	// Hosts are checked concurrently, while the bookmarks of a single host
+	// are checked one after another to be polite to it.
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, c.cfg.Concurrency)
+	for _, hostBookmarks := range byHost {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() {
+				<-sem
+			}()
+
+			c.checkHost(ctx, hostBookmarks)
+		}()
+	}
+	wg.Wait()
+
+	log(ctx).Info("links checked",
+		slog.Int("bookmarks_count", len(bookmarks)),
+		slog.Int("hosts_count", len(byHost)))
+
+	return len(bookmarks), ctx.Err()
+}
+
+func (c *Checker) checkHost(ctx context.Context, bookmarks []*model.Bookmark) {
+	for i, bm := range bookmarks {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.cfg.HostDelay):
+			}
+		}
+
+		check := c.Check(ctx, bm.URL)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := c.storage.SetLinkCheck(ctx, bm.ID, check); err != nil {
+			log(ctx).Error("failed to save link check", slog.Int("bookmark_id", bm.ID), logger.Error(err))
+		}
+	}
+}
+
+// Check requests the URL and classifies the outcome. Servers that don't
+// support HEAD are retried with GET.
+func (c *Checker) Check(ctx context.Context, rawURL string) model.LinkCheck {
+	check := model.LinkCheck{
+		Status:    model.LinkStatusBroken,
+		CheckedAt: time.Now(),
+	}
+
+	resp, err := c.do(ctx, http.MethodHead, rawURL)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp, err = c.do(ctx, http.MethodGet, rawURL)
+	}
+	if err != nil {
+		log(ctx).Debug("link is unreachable", slog.String("url", rawURL), logger.Error(err))
+		return check
+	}
+
+	status := resp.StatusCode
+	check.HTTPStatus = &status
+	check.FinalURL = resp.Request.URL.String()
+
+	switch {
+	case status >= http.StatusBadRequest:
+		check.Status = model.LinkStatusBroken
+	case check.FinalURL != rawURL:
+		check.Status = model.LinkStatusRedirected
+	default:
+		check.Status = model.LinkStatusOK
+	}
+
+	return check
+}
+
+func (c *Checker) do(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request link: %w", err)
+	}
+
+	// Only the status matters, the body is never read.
+	_ = resp.Body.Close()
+
+	return resp, nil
+}
+
+// log returns the logger carried by ctx for the linkcheck package.
+func log(ctx context.Context) *slog.Logger {
+	return logger.For(ctx, "linkcheck")
+}
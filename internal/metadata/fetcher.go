@@ -0,0 +1,160 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/tracing"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxPageSize = 1 << 20
+	userAgent   = "bookmark-manager/1.0 (+metadata fetcher)"
+)
+
+type Fetcher struct {
+	client *http.Client
+}
+
+func NewFetcher(timeout time.Duration) *Fetcher {
+	return &Fetcher{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: tracing.Transport(),
+		},
+	}
+}
+
+// Fetch downloads the page and extracts its metadata from the HTML head.
+func (f *Fetcher) Fetch(ctx context.Context, pageURL string) (*model.Metadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("failed to fetch page: unexpected status %d", resp.StatusCode)
+	}
+
+	// Relative links are resolved against the final URL, after redirects.
+	base := resp.Request.URL
+	md := &model.Metadata{
+		FaviconURL: resolve(base, "/favicon.ico"),
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return md, nil
+	}
+
+	parseHead(io.LimitReader(resp.Body, maxPageSize), base, md)
+
+	return md, nil
+}
+
+// parseHead fills md from the tags found before the page body starts.
+func parseHead(r io.Reader, base *url.URL, md *model.Metadata) {
+	var ogTitle, ogDescription string
+
+	z := html.NewTokenizer(r)
+loop:
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		token := z.Token()
+		if tt == html.EndTagToken && token.DataAtom == atom.Head {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		switch token.DataAtom {
+		case atom.Body:
+			break loop
+
+		case atom.Title:
+			if md.Title == "" && z.Next() == html.TextToken {
+				md.Title = strings.TrimSpace(string(z.Text()))
+			}
+
+		case atom.Meta:
+			name := strings.ToLower(attr(token, "name"))
+			property := strings.ToLower(attr(token, "property"))
+			content := strings.TrimSpace(attr(token, "content"))
+
+			switch {
+			case name == "description":
+				md.Description = content
+			case property == "og:title":
+				ogTitle = content
+			case property == "og:description":
+				ogDescription = content
+			case property == "og:image":
+				md.ImageURL = resolve(base, content)
+			}
+
+		case atom.Link:
+			rel := strings.Fields(strings.ToLower(attr(token, "rel")))
+			href := attr(token, "href")
+
+			for _, value := range rel {
+				if value == "icon" && href != "" {
+					md.FaviconURL = resolve(base, href)
+				}
+			}
+		}
+	}
+
+	if md.Title == "" {
+		md.Title = ogTitle
+	}
+	if md.Description == "" {
+		md.Description = ogDescription
+	}
+}
+
+func attr(token html.Token, key string) string {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+func resolve(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ""
+	}
+
+	return u.String()
+}
@@ -0,0 +1,155 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+func TestFetch(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        model.Metadata
+	}{
+		{
+			name:        "head tags",
+			contentType: "text/html; charset=utf-8",
+			body: `<html><head>
+				<title> Go </title>
+				<meta name="description" content="The Go programming language">
+				<meta property="og:image" content="https://cdn.example.com/gopher.png">
+				<link rel="shortcut icon" href="https://cdn.example.com/favicon.png">
+				</head><body></body></html>`,
+			want: model.Metadata{
+				Title:       "Go",
+				Description: "The Go programming language",
+				ImageURL:    "https://cdn.example.com/gopher.png",
+				FaviconURL:  "https://cdn.example.com/favicon.png",
+			},
+		},
+		{
+			name:        "relative urls",
+			contentType: "text/html",
+			body: `<head>
+				<meta property="og:image" content="/images/cover.png">
+				<link rel="icon" href="static/icon.svg">
+				</head>`,
+			want: model.Metadata{
+				ImageURL:   "{server}/images/cover.png",
+				FaviconURL: "{server}/docs/static/icon.svg",
+			},
+		},
+		{
+			name:        "open graph fallback",
+			contentType: "text/html",
+			body: `<head>
+				<meta property="og:title" content="OG title">
+				<meta property="og:description" content="OG description">
+				</head>`,
+			want: model.Metadata{
+				Title:       "OG title",
+				Description: "OG description",
+				FaviconURL:  "{server}/favicon.ico",
+			},
+		},
+		{
+			name:        "title before open graph",
+			contentType: "text/html",
+			body:        `<head><meta property="og:title" content="OG title"><title>Title</title></head>`,
+			want: model.Metadata{
+				Title:      "Title",
+				FaviconURL: "{server}/favicon.ico",
+			},
+		},
+		{
+			name:        "tags after head are ignored",
+			contentType: "text/html",
+			body:        `<head></head><body><title>Body title</title></body>`,
+			want: model.Metadata{
+				FaviconURL: "{server}/favicon.ico",
+			},
+		},
+		{
+			name:        "not html",
+			contentType: "application/pdf",
+			body:        `<head><title>Not parsed</title></head>`,
+			want: model.Metadata{
+				FaviconURL: "{server}/favicon.ico",
+			},
+		},
+		{
+			name:        "oversized page",
+			contentType: "text/html",
+			body:        "<head><!--" + strings.Repeat("x", maxPageSize) + "--><title>Too late</title></head>",
+			want: model.Metadata{
+				FaviconURL: "{server}/favicon.ico",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			got, err := NewFetcher(time.Second).Fetch(context.Background(), srv.URL+"/docs/page")
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+
+			want := tt.want
+			want.ImageURL = strings.ReplaceAll(want.ImageURL, "{server}", srv.URL)
+			want.FaviconURL = strings.ReplaceAll(want.FaviconURL, "{server}", srv.URL)
+
+			if *got != want {
+				t.Errorf("Fetch() = %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
+
+func TestFetchRedirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/old", http.RedirectHandler("/new/page", http.StatusMovedPermanently))
+	mux.HandleFunc("/new/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<head><title>Moved</title><meta property="og:image" content="cover.png"></head>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	got, err := NewFetcher(time.Second).Fetch(context.Background(), srv.URL+"/old")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	// Relative links resolve against the URL the redirect ended at.
+	want := model.Metadata{
+		Title:      "Moved",
+		ImageURL:   srv.URL + "/new/cover.png",
+		FaviconURL: srv.URL + "/favicon.ico",
+	}
+	if *got != want {
+		t.Errorf("Fetch() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestFetchErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	if _, err := NewFetcher(time.Second).Fetch(context.Background(), srv.URL); err == nil {
+		t.Fatal("Fetch() error = nil, want an error for status 404")
+	}
+}
@@ -0,0 +1,7 @@
+package metadata
+
+import (
+	"errors"
+)
+
+var ErrFetch = errors.New("failed to fetch page metadata")
@@ -0,0 +1,111 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+type Storage interface {
+	GetBookmark(ctx context.Context, id int) (*model.Bookmark, error)
+	SetBookmarkMetadata(ctx context.Context, id int, metadata model.Metadata) error
+}
+
+type job struct {
+	bookmarkID int
+	url        string
+}
+
+// Worker fetches page metadata of bookmarks in the background.
+type Worker struct {
+	fetcher *Fetcher
+	storage Storage
+	workers int
+	jobs    chan job
+}
+
+func NewWorker(fetcher *Fetcher, storage Storage, workers, queueSize int) *Worker {
+	return &Worker{
+		fetcher: fetcher,
+		storage: storage,
+		workers: workers,
+		jobs:    make(chan job, queueSize),
+	}
+}
+
+// Enqueue schedules fetching metadata for the bookmark. It never blocks, the
+// job is dropped when the queue is full.
+func (w *Worker) Enqueue(bookmarkID int, url string) {
+	select {
+	case w.jobs <- job{bookmarkID: bookmarkID, url: url}:
+	default:
+		log(context.Background()).Warn("metadata queue is full, job dropped", slog.Int("bookmark_id", bookmarkID))
+	}
+}
+
+// Run processes queued jobs until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	log(ctx).Info("metadata worker starting", slog.Int("workers", w.workers))
+
+	var wg sync.WaitGroup
+	for range w.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j := <-w.jobs:
+					if err := w.process(ctx, j.bookmarkID, j.url); err != nil {
+						log(ctx).Error("failed to fetch bookmark metadata",
+							slog.Int("bookmark_id", j.bookmarkID),
+							logger.Error(err))
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// RefreshBookmark fetches metadata of the bookmark right away and returns the
+// updated bookmark. ctx has to carry the bookmark owner.
+func (w *Worker) RefreshBookmark(ctx context.Context, id int) (*model.Bookmark, error) {
+	bm, err := w.storage.GetBookmark(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.process(ctx, bm.ID, bm.URL); err != nil {
+		return nil, err
+	}
+
+	return w.storage.GetBookmark(ctx, id)
+}
+
+func (w *Worker) process(ctx context.Context, bookmarkID int, url string) error {
+	md, err := w.fetcher.Fetch(ctx, url)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFetch, err)
+	}
+
+	if err := w.storage.SetBookmarkMetadata(ctx, bookmarkID, *md); err != nil {
+		return err
+	}
+
+	log(ctx).Info("bookmark metadata fetched", slog.Int("bookmark_id", bookmarkID))
+
+	return nil
+}
+
+// log returns the logger carried by ctx for the metadata package.
+func log(ctx context.Context) *slog.Logger {
+	return logger.For(ctx, "metadata")
+}
@@ -0,0 +1,61 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+type fakeStorage struct {
+	bookmark *model.Bookmark
+	metadata *model.Metadata
+}
+
+func (s *fakeStorage) GetBookmark(ctx context.Context, id int) (*model.Bookmark, error) {
+	return s.bookmark, nil
+}
+
+func (s *fakeStorage) SetBookmarkMetadata(ctx context.Context, id int, metadata model.Metadata) error {
+	s.metadata = &metadata
+	return nil
+}
+
+func TestRefreshBookmark(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<head><title>Fetched</title></head>`))
+	}))
+	defer srv.Close()
+
+	storage := &fakeStorage{bookmark: &model.Bookmark{ID: 1, URL: srv.URL}}
+	worker := NewWorker(NewFetcher(time.Second), storage, 1, 1)
+
+	if _, err := worker.RefreshBookmark(context.Background(), 1); err != nil {
+		t.Fatalf("RefreshBookmark() error = %v", err)
+	}
+
+	if storage.metadata == nil || storage.metadata.Title != "Fetched" {
+		t.Errorf("stored metadata = %+v, want title %q", storage.metadata, "Fetched")
+	}
+}
+
+func TestRefreshBookmarkFetchError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	storage := &fakeStorage{bookmark: &model.Bookmark{ID: 1, URL: srv.URL}}
+	worker := NewWorker(NewFetcher(time.Second), storage, 1, 1)
+
+	_, err := worker.RefreshBookmark(context.Background(), 1)
+	if !errors.Is(err, ErrFetch) {
+		t.Fatalf("RefreshBookmark() error = %v, want ErrFetch", err)
+	}
+	if storage.metadata != nil {
+		t.Errorf("stored metadata = %+v, want none", storage.metadata)
+	}
+}
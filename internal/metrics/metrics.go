@@ -0,0 +1,269 @@
+// Package metrics exposes Prometheus metrics of the HTTP API, the storage
+// and the background jobs.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "bookmark_manager"
+
+// statsTimeout bounds the queries behind the business gauges, so a slow
+// database doesn't stall scrapes.
+const statsTimeout = 5 * time.Second
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	rateLimited     *prometheus.CounterVec
+
+	storageDuration *prometheus.HistogramVec
+
+	metadataFetches  prometheus.Counter
+	linkChecks       *prometheus.CounterVec
+	trashPurged      prometheus.Counter
+	eventsDispatched prometheus.Counter
+	deliveries       *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route pattern and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route pattern and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "rate_limited_total",
+			Help:      "HTTP requests rejected by the rate limiter.",
+		}, []string{"method"}),
+
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "operation_duration_seconds",
+			Help:      "Storage operation latency by method.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"method"}),
+
+		metadataFetches: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "metadata",
+			Name:      "fetches_total",
+			Help:      "Page metadata stored for bookmarks.",
+		}),
+		linkChecks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "linkcheck",
+			Name:      "checks_total",
+			Help:      "Link checks by resulting status.",
+		}, []string{"status"}),
+		trashPurged: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "trash",
+			Name:      "purged_total",
+			Help:      "Bookmarks purged from the trash after the retention period.",
+		}),
+		eventsDispatched: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "webhook",
+			Name:      "events_dispatched_total",
+			Help:      "Outbox events turned into webhook deliveries.",
+		}),
+		deliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "webhook",
+			Name:      "delivery_attempts_total",
+			Help:      "Webhook delivery attempts by outcome.",
+		}, []string{"outcome"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.rateLimited,
+		m.storageDuration,
+		m.metadataFetches,
+		m.linkChecks,
+		m.trashPurged,
+		m.eventsDispatched,
+		m.deliveries,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware counts requests and measures their latency. Routes are labeled
+// by their chi pattern, so ids in paths don't blow up the label values.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		labels := prometheus.Labels{
+			"method": r.Method,
+			"route":  route,
+			"status": strconv.Itoa(status),
+		}
+		m.requests.With(labels).Inc()
+		m.requestDuration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+// RateLimited answers requests rejected by the rate limiter and counts them.
+func (m *Metrics) RateLimited(w http.ResponseWriter, r *http.Request) {
+	m.rateLimited.WithLabelValues(r.Method).Inc()
+
+	http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+}
+
+type DBStatsProvider interface {
+	Stats() sql.DBStats
+}
+
+// RegisterDBStats exposes the connection pool statistics of the storage.
+func (m *Metrics) RegisterDBStats(provider DBStatsProvider) {
+	gauge := func(name, help string, value func(sql.DBStats) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      name,
+			Help:      help,
+		}, func() float64 {
+			return value(provider.Stats())
+		})
+	}
+
+	counter := func(name, help string, value func(sql.DBStats) float64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      name,
+			Help:      help,
+		}, func() float64 {
+			return value(provider.Stats())
+		})
+	}
+
+	m.registry.MustRegister(
+		gauge("max_open_connections", "Maximum number of open connections.",
+			func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }),
+		gauge("open_connections", "Established connections, both in use and idle.",
+			func(s sql.DBStats) float64 { return float64(s.OpenConnections) }),
+		gauge("in_use_connections", "Connections currently in use.",
+			func(s sql.DBStats) float64 { return float64(s.InUse) }),
+		gauge("idle_connections", "Idle connections.",
+			func(s sql.DBStats) float64 { return float64(s.Idle) }),
+		counter("wait_count_total", "Connections waited for.",
+			func(s sql.DBStats) float64 { return float64(s.WaitCount) }),
+		counter("wait_duration_seconds_total", "Time blocked waiting for a new connection.",
+			func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }),
+		counter("max_idle_closed_total", "Connections closed due to SetMaxIdleConns.",
+			func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }),
+		counter("max_idle_time_closed_total", "Connections closed due to SetConnMaxIdleTime.",
+			func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) }),
+		counter("max_lifetime_closed_total", "Connections closed due to SetConnMaxLifetime.",
+			func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }),
+	)
+}
+
+type StatsProvider interface {
+	GetStats(ctx context.Context) (*model.Stats, error)
+}
+
+// RegisterStats exposes business gauges, counted across all users on every
+// scrape.
+func (m *Metrics) RegisterStats(provider StatsProvider) {
+	m.registry.MustRegister(&statsCollector{provider: provider})
+}
+
+var (
+	usersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "users"),
+		"Registered users.", nil, nil)
+	bookmarksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "bookmarks"),
+		"Bookmarks of all users, by state.", []string{"state"}, nil)
+	brokenLinksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "broken_links"),
+		"Live bookmarks whose last link check failed.", nil, nil)
+)
+
+type statsCollector struct {
+	provider StatsProvider
+}
+
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- usersDesc
+	ch <- bookmarksDesc
+	ch <- brokenLinksDesc
+}
+
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), statsTimeout)
+	defer cancel()
+
+	stats, err := c.provider.GetStats(ctx)
+	if err != nil {
+		log(ctx).Error("failed to collect stats", logger.Error(err))
+
+		ch <- prometheus.NewInvalidMetric(usersDesc, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(usersDesc, prometheus.GaugeValue, float64(stats.Users))
+	ch <- prometheus.MustNewConstMetric(bookmarksDesc, prometheus.GaugeValue, float64(stats.Bookmarks), "live")
+	ch <- prometheus.MustNewConstMetric(bookmarksDesc, prometheus.GaugeValue, float64(stats.Trashed), "trashed")
+	ch <- prometheus.MustNewConstMetric(brokenLinksDesc, prometheus.GaugeValue, float64(stats.BrokenLinks))
+}
+
+// log returns the logger carried by ctx for the metrics package.
+func log(ctx context.Context) *slog.Logger {
+	return logger.For(ctx, "metrics")
+}
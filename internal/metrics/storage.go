@@ -0,0 +1,333 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+var _ storage.Storage = (*Storage)(nil)
+
+// Storage measures the latency of every storage operation and counts the
+// outcome of background jobs as they record it.
+type Storage struct {
+	storage.Storage
+	metrics *Metrics
+}
+
+// WrapStorage instruments the given storage.
+func (m *Metrics) WrapStorage(s storage.Storage) *Storage {
+	return &Storage{
+		Storage: s,
+		metrics: m,
+	}
+}
+
+func (s *Storage) observe(method string, start time.Time) {
+	s.metrics.storageDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+func (s *Storage) GetBookmarks(ctx context.Context, filter storage.BookmarkFilter) (*storage.BookmarkPage, error) {
+	defer s.observe("GetBookmarks", time.Now())
+
+	return s.Storage.GetBookmarks(ctx, filter)
+}
+
+func (s *Storage) GetBookmark(ctx context.Context, id int) (*model.Bookmark, error) {
+	defer s.observe("GetBookmark", time.Now())
+
+	return s.Storage.GetBookmark(ctx, id)
+}
+
+func (s *Storage) CreateBookmark(ctx context.Context, title, url string, tags []string, folderID *int) (*model.Bookmark, error) {
+	defer s.observe("CreateBookmark", time.Now())
+
+	return s.Storage.CreateBookmark(ctx, title, url, tags, folderID)
+}
+
+func (s *Storage) ImportBookmark(ctx context.Context, bookmark *model.Bookmark) (*model.Bookmark, error) {
+	defer s.observe("ImportBookmark", time.Now())
+
+	return s.Storage.ImportBookmark(ctx, bookmark)
+}
+
+func (s *Storage) EditBookmark(ctx context.Context, id int, title, url string, tags []string) (*model.Bookmark, error) {
+	defer s.observe("EditBookmark", time.Now())
+
+	return s.Storage.EditBookmark(ctx, id, title, url, tags)
+}
+
+func (s *Storage) EditBookmarkIfUnchanged(ctx context.Context, id int, version int64, title, url string, tags []string) (*model.Bookmark, error) {
+	defer s.observe("EditBookmarkIfUnchanged", time.Now())
+
+	return s.Storage.EditBookmarkIfUnchanged(ctx, id, version, title, url, tags)
+}
+
+func (s *Storage) DeleteBookmark(ctx context.Context, id int) error {
+	defer s.observe("DeleteBookmark", time.Now())
+
+	return s.Storage.DeleteBookmark(ctx, id)
+}
+
+func (s *Storage) DeleteBookmarkIfUnchanged(ctx context.Context, id int, version int64) error {
+	defer s.observe("DeleteBookmarkIfUnchanged", time.Now())
+
+	return s.Storage.DeleteBookmarkIfUnchanged(ctx, id, version)
+}
+
+func (s *Storage) BookmarkExist(ctx context.Context, url string) (int, bool, error) {
+	defer s.observe("BookmarkExist", time.Now())
+
+	return s.Storage.BookmarkExist(ctx, url)
+}
+
+func (s *Storage) MoveBookmark(ctx context.Context, id int, folderID *int) (*model.Bookmark, error) {
+	defer s.observe("MoveBookmark", time.Now())
+
+	return s.Storage.MoveBookmark(ctx, id, folderID)
+}
+
+func (s *Storage) GetTags(ctx context.Context) ([]*model.Tag, error) {
+	defer s.observe("GetTags", time.Now())
+
+	return s.Storage.GetTags(ctx)
+}
+
+func (s *Storage) GetFolders(ctx context.Context) ([]*model.Folder, error) {
+	defer s.observe("GetFolders", time.Now())
+
+	return s.Storage.GetFolders(ctx)
+}
+
+func (s *Storage) CreateFolder(ctx context.Context, name string, parentID *int) (*model.Folder, error) {
+	defer s.observe("CreateFolder", time.Now())
+
+	return s.Storage.CreateFolder(ctx, name, parentID)
+}
+
+func (s *Storage) ImportFolder(ctx context.Context, name string, parentID *int) (*model.Folder, error) {
+	defer s.observe("ImportFolder", time.Now())
+
+	return s.Storage.ImportFolder(ctx, name, parentID)
+}
+
+func (s *Storage) EditFolder(ctx context.Context, id int, name string, parentID *int) (*model.Folder, error) {
+	defer s.observe("EditFolder", time.Now())
+
+	return s.Storage.EditFolder(ctx, id, name, parentID)
+}
+
+func (s *Storage) DeleteFolder(ctx context.Context, id int) error {
+	defer s.observe("DeleteFolder", time.Now())
+
+	return s.Storage.DeleteFolder(ctx, id)
+}
+
+func (s *Storage) CreateUser(ctx context.Context, username, passwordHash string) (*model.User, error) {
+	defer s.observe("CreateUser", time.Now())
+
+	return s.Storage.CreateUser(ctx, username, passwordHash)
+}
+
+func (s *Storage) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
+	defer s.observe("GetUserByUsername", time.Now())
+
+	return s.Storage.GetUserByUsername(ctx, username)
+}
+
+func (s *Storage) GetTokens(ctx context.Context) ([]*model.Token, error) {
+	defer s.observe("GetTokens", time.Now())
+
+	return s.Storage.GetTokens(ctx)
+}
+
+func (s *Storage) CreateToken(ctx context.Context, name, tokenHash string, scopes []string) (*model.Token, error) {
+	defer s.observe("CreateToken", time.Now())
+
+	return s.Storage.CreateToken(ctx, name, tokenHash, scopes)
+}
+
+func (s *Storage) RevokeToken(ctx context.Context, id int) error {
+	defer s.observe("RevokeToken", time.Now())
+
+	return s.Storage.RevokeToken(ctx, id)
+}
+
+func (s *Storage) AuthenticateToken(ctx context.Context, tokenHash string) (*model.User, *model.Token, error) {
+	defer s.observe("AuthenticateToken", time.Now())
+
+	return s.Storage.AuthenticateToken(ctx, tokenHash)
+}
+
+func (s *Storage) GetTrash(ctx context.Context) ([]*model.Bookmark, error) {
+	defer s.observe("GetTrash", time.Now())
+
+	return s.Storage.GetTrash(ctx)
+}
+
+func (s *Storage) RestoreBookmark(ctx context.Context, id int) (*model.Bookmark, error) {
+	defer s.observe("RestoreBookmark", time.Now())
+
+	return s.Storage.RestoreBookmark(ctx, id)
+}
+
+func (s *Storage) PurgeBookmark(ctx context.Context, id int) error {
+	defer s.observe("PurgeBookmark", time.Now())
+
+	return s.Storage.PurgeBookmark(ctx, id)
+}
+
+func (s *Storage) GetBookmarkHistory(ctx context.Context, id int) ([]*model.Revision, error) {
+	defer s.observe("GetBookmarkHistory", time.Now())
+
+	return s.Storage.GetBookmarkHistory(ctx, id)
+}
+
+func (s *Storage) RevertBookmark(ctx context.Context, id, revision int) (*model.Bookmark, error) {
+	defer s.observe("RevertBookmark", time.Now())
+
+	return s.Storage.RevertBookmark(ctx, id, revision)
+}
+
+func (s *Storage) GetLinkReport(ctx context.Context) (*model.LinkReport, error) {
+	defer s.observe("GetLinkReport", time.Now())
+
+	return s.Storage.GetLinkReport(ctx)
+}
+
+func (s *Storage) GetWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	defer s.observe("GetWebhooks", time.Now())
+
+	return s.Storage.GetWebhooks(ctx)
+}
+
+func (s *Storage) CreateWebhook(ctx context.Context, url, secret string, events []string) (*model.Webhook, error) {
+	defer s.observe("CreateWebhook", time.Now())
+
+	return s.Storage.CreateWebhook(ctx, url, secret, events)
+}
+
+func (s *Storage) DeleteWebhook(ctx context.Context, id int) error {
+	defer s.observe("DeleteWebhook", time.Now())
+
+	return s.Storage.DeleteWebhook(ctx, id)
+}
+
+func (s *Storage) GetWebhookDeliveries(ctx context.Context, webhookID, limit int) ([]*model.WebhookDelivery, error) {
+	defer s.observe("GetWebhookDeliveries", time.Now())
+
+	return s.Storage.GetWebhookDeliveries(ctx, webhookID, limit)
+}
+
+func (s *Storage) ReplayDelivery(ctx context.Context, webhookID int, deliveryID int64) (*model.WebhookDelivery, error) {
+	defer s.observe("ReplayDelivery", time.Now())
+
+	return s.Storage.ReplayDelivery(ctx, webhookID, deliveryID)
+}
+
+func (s *Storage) GetEventsSince(ctx context.Context, afterID int64, limit int) ([]*model.StoredEvent, error) {
+	defer s.observe("GetEventsSince", time.Now())
+
+	return s.Storage.GetEventsSince(ctx, afterID, limit)
+}
+
+func (s *Storage) GetLastEventID(ctx context.Context) (int64, error) {
+	defer s.observe("GetLastEventID", time.Now())
+
+	return s.Storage.GetLastEventID(ctx)
+}
+
+func (s *Storage) GetChanges(ctx context.Context, since int64, limit int) (*model.ChangeSet, error) {
+	defer s.observe("GetChanges", time.Now())
+
+	return s.Storage.GetChanges(ctx, since, limit)
+}
+
+func (s *Storage) GetUsers(ctx context.Context) ([]*model.User, error) {
+	defer s.observe("GetUsers", time.Now())
+
+	return s.Storage.GetUsers(ctx)
+}
+
+func (s *Storage) GetBookmarksToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*model.Bookmark, error) {
+	defer s.observe("GetBookmarksToCheck", time.Now())
+
+	return s.Storage.GetBookmarksToCheck(ctx, checkedBefore, limit)
+}
+
+func (s *Storage) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	defer s.observe("ClaimDeliveries", time.Now())
+
+	return s.Storage.ClaimDeliveries(ctx, limit, lease)
+}
+
+func (s *Storage) SetBookmarkMetadata(ctx context.Context, id int, metadata model.Metadata) error {
+	defer s.observe("SetBookmarkMetadata", time.Now())
+
+	if err := s.Storage.SetBookmarkMetadata(ctx, id, metadata); err != nil {
+		return err
+	}
+
+	s.metrics.metadataFetches.Inc()
+
+	return nil
+}
+
+func (s *Storage) SetLinkCheck(ctx context.Context, id int, check model.LinkCheck) error {
+	defer s.observe("SetLinkCheck", time.Now())
+
+	if err := s.Storage.SetLinkCheck(ctx, id, check); err != nil {
+		return err
+	}
+
+	s.metrics.linkChecks.WithLabelValues(check.Status).Inc()
+
+	return nil
+}
+
+func (s *Storage) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	defer s.observe("PurgeTrash", time.Now())
+
+	purged, err := s.Storage.PurgeTrash(ctx, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	s.metrics.trashPurged.Add(float64(purged))
+
+	return purged, nil
+}
+
+func (s *Storage) DispatchEvents(ctx context.Context, limit int) (int, error) {
+	defer s.observe("DispatchEvents", time.Now())
+
+	dispatched, err := s.Storage.DispatchEvents(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	s.metrics.eventsDispatched.Add(float64(dispatched))
+
+	return dispatched, nil
+}
+
+func (s *Storage) SetDeliveryResult(ctx context.Context, id int64, result model.DeliveryResult) error {
+	defer s.observe("SetDeliveryResult", time.Now())
+
+	if err := s.Storage.SetDeliveryResult(ctx, id, result); err != nil {
+		return err
+	}
+
+	outcome := "retry"
+	switch {
+	case result.Succeeded:
+		outcome = "succeeded"
+	case result.NextAttemptAt == nil:
+		outcome = "failed"
+	}
+	s.metrics.deliveries.WithLabelValues(outcome).Inc()
+
+	return nil
+}
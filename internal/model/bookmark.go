@@ -5,9 +5,42 @@ import (
 )
 
 type Bookmark struct {
-	ID        int       `json:"id"`
-	Title     string    `json:"title"`
-	URL       string    `json:"url"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int      `json:"id"`
+	Title       string   `json:"title"`
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	ImageURL    string   `json:"image_url"`
+	FaviconURL  string   `json:"favicon_url"`
+	Tags        []string `json:"tags"`
+	FolderID    *int     `json:"folder_id"`
+
+	// MetadataFetchedAt is set once the page metadata has been fetched.
+	MetadataFetchedAt *time.Time `json:"metadata_fetched_at"`
+
+	// LinkStatus is empty until the link checker has visited the URL.
+	LinkStatus          string     `json:"link_status"`
+	HTTPStatus          *int       `json:"http_status"`
+	FinalURL            string     `json:"final_url"`
+	LastCheckedAt       *time.Time `json:"last_checked_at"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// Version is the change sequence number of the latest change, sync
+	// clients send it back to detect conflicting edits.
+	Version int64 `json:"version"`
+
+	// Score and Snippet are only set for search results.
+	Score   *float64 `json:"score,omitempty"`
+	Snippet string   `json:"snippet,omitempty"`
+}
+
+// Metadata describes a web page as found in its HTML head.
+type Metadata struct {
+	Title       string
+	Description string
+	ImageURL    string
+	FaviconURL  string
 }
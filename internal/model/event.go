@@ -0,0 +1,27 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	EventBookmarkCreated = "bookmark.created"
+	EventBookmarkUpdated = "bookmark.updated"
+	EventBookmarkDeleted = "bookmark.deleted"
+)
+
+// Event is a bookmark lifecycle event, as posted to webhooks and streamed
+// to live clients.
+type Event struct {
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// StoredEvent is an Event read back from the outbox.
+type StoredEvent struct {
+	ID      int64
+	Event   string
+	Payload json.RawMessage
+}
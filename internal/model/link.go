@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	LinkStatusOK         = "ok"
+	LinkStatusRedirected = "redirected"
+	LinkStatusBroken     = "broken"
+)
+
+// LinkCheck is the outcome of checking whether a bookmark URL still resolves.
+type LinkCheck struct {
+	Status     string
+	HTTPStatus *int
+	FinalURL   string
+	CheckedAt  time.Time
+}
+
+type LinkReport struct {
+	OK            int        `json:"ok"`
+	Redirected    int        `json:"redirected"`
+	Broken        int        `json:"broken"`
+	Unchecked     int        `json:"unchecked"`
+	LastCheckedAt *time.Time `json:"last_checked_at"`
+}
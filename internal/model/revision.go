@@ -0,0 +1,57 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	RevisionCreated  = "created"
+	RevisionEdited   = "edited"
+	RevisionDeleted  = "deleted"
+	RevisionRestored = "restored"
+	RevisionReverted = "reverted"
+)
+
+// Revision is a snapshot of a bookmark taken on every change.
+type Revision struct {
+	Revision  int       `json:"revision"`
+	Action    string    `json:"action"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Changes holds the fields that differ from the previous revision.
+	Changes []Change `json:"changes"`
+}
+
+type Change struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// Diff returns the field-level changes from prev to r. A nil prev means r is
+// the first revision, every field is reported as new.
+func (r *Revision) Diff(prev *Revision) []Change {
+	changes := []Change{}
+
+	if prev == nil {
+		prev = &Revision{}
+	}
+
+	if prev.Title != r.Title {
+		changes = append(changes, Change{Field: "title", Old: prev.Title, New: r.Title})
+	}
+
+	if prev.URL != r.URL {
+		changes = append(changes, Change{Field: "url", Old: prev.URL, New: r.URL})
+	}
+
+	if !slices.Equal(prev.Tags, r.Tags) {
+		changes = append(changes, Change{Field: "tags", Old: prev.Tags, New: r.Tags})
+	}
+
+	return changes
+}
@@ -0,0 +1,9 @@
+package model
+
+// Stats counts what is stored across all users.
+type Stats struct {
+	Users       int
+	Bookmarks   int
+	Trashed     int
+	BrokenLinks int
+}
@@ -0,0 +1,12 @@
+package model
+
+// ChangeSet is a batch of bookmark changes for sync clients. Trashed
+// bookmarks are tombstones, they carry DeletedAt.
+type ChangeSet struct {
+	Bookmarks []*Bookmark
+	// Since is the change sequence number to resume from.
+	Since   int64
+	HasMore bool
+	// Reset tells the client to drop its copy, the changes start over.
+	Reset bool
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+)
+
+type Token struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+
+	// Secret is the plain token. It's only known right after creation,
+	// storage keeps just its hash.
+	Secret string `json:"token,omitempty"`
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Secret signs the deliveries. It's only returned right after creation.
+	Secret string `json:"secret,omitempty"`
+}
+
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	WebhookID      int        `json:"webhook_id"`
+	EventID        int64      `json:"event_id"`
+	Event          string     `json:"event"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at"`
+	ResponseStatus *int       `json:"response_status"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// URL, Secret and Payload are only loaded for the delivery worker.
+	URL     string          `json:"-"`
+	Secret  string          `json:"-"`
+	Payload json.RawMessage `json:"-"`
+}
+
+// DeliveryResult is the outcome of a single delivery attempt.
+type DeliveryResult struct {
+	ResponseStatus *int
+	Error          string
+	// NextAttemptAt is nil once the delivery succeeded or ran out of attempts.
+	NextAttemptAt *time.Time
+	Succeeded     bool
+}
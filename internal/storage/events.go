@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/lib/pq"
+)
+
+// GetEventsSince returns up to limit events of the user recorded after the
+// given event id, oldest first.
+//
+// Resuming after an id is safe because recordEvent takes a per-user advisory
+// lock before inserting: the events of a user are committed in id order, a
+// reader never sees an id while a lower one of the same user is still in
+// flight.
+func (s *PostgresStorage) GetEventsSince(ctx context.Context, afterID int64, limit int) ([]*model.StoredEvent, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select("id", "event", "payload").
+		From("outbox_events").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Gt{"id": afterID}).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	events := []*model.StoredEvent{}
+	for rows.Next() {
+		var e model.StoredEvent
+
+		if err := rows.Scan(&e.ID, &e.Event, &e.Payload); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+
+		events = append(events, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate events rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetLastEventID returns the id of the latest event of the user, or 0 when
+// there is none yet.
+func (s *PostgresStorage) GetLastEventID(ctx context.Context) (int64, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+
+	err = sq.
+		Select("COALESCE(MAX(id), 0)").
+		From("outbox_events").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db).
+		QueryRowContext(ctx).
+		Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last event id: %w", err)
+	}
+
+	return id, nil
+}
+
+// eventsChannel is notified by a trigger on every recorded event.
+const eventsChannel = "bookmark_events"
+
+// listenerPingInterval is how often the listener connection is checked while idle.
+const listenerPingInterval = time.Minute
+
+type eventNotification struct {
+	ID     int64 `json:"id"`
+	UserID int   `json:"user_id"`
+}
+
+// ListenEvents listens to event notifications on a dedicated connection, so
+// that events recorded by any instance reach the streams of this one.
+func (s *PostgresStorage) ListenEvents(ctx context.Context, notify func(userID int)) error {
+	listener := pq.NewListener(s.dsn.String(), time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log(ctx).Error("event listener connection problem", logger.Error(err))
+		}
+	})
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	if err := listener.Listen(eventsChannel); err != nil {
+		return fmt.Errorf("failed to listen to %s: %w", eventsChannel, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case n := <-listener.Notify:
+			// A nil notification follows a reconnect, events may have been
+			// missed in between.
+			if n == nil {
+				notify(0)
+				continue
+			}
+
+			var payload eventNotification
+			if err := json.Unmarshal([]byte(n.Extra), &payload); err != nil {
+				log(ctx).Error("failed to decode event notification", logger.Error(err))
+				continue
+			}
+
+			notify(payload.UserID)
+
+		case <-time.After(listenerPingInterval):
+			go func() {
+				_ = listener.Ping()
+			}()
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+func (s *PostgresStorage) GetFolders(ctx context.Context) ([]*model.Folder, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select("id", "name", "parent_id", "created_at", "updated_at").
+		From("folders").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("name", "id").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folders rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	folders := []*model.Folder{}
+	for rows.Next() {
+		var f model.Folder
+
+		if err := rows.Scan(&f.ID, &f.Name, &f.ParentID, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan folder: %w", err)
+		}
+
+		folders = append(folders, &f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate folders rows: %w", err)
+	}
+
+	return folders, nil
+}
+
+func (s *PostgresStorage) CreateFolder(ctx context.Context, name string, parentID *int) (*model.Folder, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkFolderOwner(ctx, s.db, userID, parentID); err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Insert("folders").
+		Columns("name", "parent_id", "user_id").
+		Values(name, parentID, userID).
+		Suffix("RETURNING id, name, parent_id, created_at, updated_at").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	var f model.Folder
+	if err := stmt.QueryRowContext(ctx).Scan(&f.ID, &f.Name, &f.ParentID, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		if isPqError(err, foreignKeyViolation) {
+			return nil, ErrFolderNotFound
+		}
+
+		return nil, fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	return &f, nil
+}
+
+// ImportFolder returns the folder with the given name under the given parent,
+// creating it if there is none, so repeated imports don't duplicate folders.
+func (s *PostgresStorage) ImportFolder(ctx context.Context, name string, parentID *int) (*model.Folder, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select("id", "name", "parent_id", "created_at", "updated_at").
+		From("folders").
+		Where(sq.Eq{"name": name, "parent_id": parentID, "user_id": userID}).
+		OrderBy("id").
+		Limit(1).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	var f model.Folder
+	err = stmt.QueryRowContext(ctx).Scan(&f.ID, &f.Name, &f.ParentID, &f.CreatedAt, &f.UpdatedAt)
+	if err == nil {
+		return &f, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to find folder: %w", err)
+	}
+
+	return s.CreateFolder(ctx, name, parentID)
+}
+
+func (s *PostgresStorage) EditFolder(ctx context.Context, id int, name string, parentID *int) (*model.Folder, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := checkFolderOwner(ctx, tx, userID, parentID); err != nil {
+		return nil, err
+	}
+
+	if parentID != nil {
+		var cycle bool
+
+		err := tx.QueryRowContext(ctx, `
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM folders WHERE id = $1
+				UNION ALL
+				SELECT f.id FROM folders f JOIN subtree s ON f.parent_id = s.id
+			)
+			SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2)`,
+			id, *parentID,
+		).Scan(&cycle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check folder subtree: %w", err)
+		}
+
+		if cycle {
+			return nil, ErrFolderCycle
+		}
+	}
+
+	stmt := sq.
+		Update("folders").
+		Set("name", name).
+		Set("parent_id", parentID).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		Suffix("RETURNING id, name, parent_id, created_at, updated_at").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx)
+
+	var f model.Folder
+	if err := stmt.QueryRowContext(ctx).Scan(&f.ID, &f.Name, &f.ParentID, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) || isPqError(err, foreignKeyViolation) {
+			return nil, ErrFolderNotFound
+		}
+
+		return nil, fmt.Errorf("failed to edit folder: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &f, nil
+}
+
+// DeleteFolder removes the folder together with its subfolders. Bookmarks
+// stored in them are moved to the root.
+func (s *PostgresStorage) DeleteFolder(ctx context.Context, id int) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	stmt := sq.
+		Delete("folders").
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	result, err := stmt.ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete folder: %w", err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get check deletion: %w", err)
+	}
+
+	if rowAffected == 0 {
+		return ErrFolderNotFound
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) MoveBookmark(ctx context.Context, id int, folderID *int) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := checkFolderOwner(ctx, tx, userID, folderID); err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Update("bookmarks").
+		Set("folder_id", folderID).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": id, "user_id": userID, "deleted_at": nil}).
+		Suffix(bookmarkReturning).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx)
+
+	var bm model.Bookmark
+	if err := stmt.QueryRowContext(ctx).Scan(bookmarkFields(&bm)...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if isPqError(err, foreignKeyViolation) {
+			return nil, ErrFolderNotFound
+		}
+
+		return nil, fmt.Errorf("failed to move bookmark: %w", err)
+	}
+
+	tags, err := getBookmarkTags(ctx, tx, bm.ID)
+	if err != nil {
+		return nil, err
+	}
+	bm.Tags = tags
+
+	if err := recordEvent(ctx, tx, userID, model.EventBookmarkUpdated, &bm); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &bm, nil
+}
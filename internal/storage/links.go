@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+// GetBookmarksToCheck returns bookmarks of all users that were never checked
+// or were last checked before the given time, least recently checked first.
+func (s *PostgresStorage) GetBookmarksToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*model.Bookmark, error) {
+	stmt := sq.
+		Select("id", "url").
+		From("bookmarks").
+		Where(sq.Eq{"deleted_at": nil}).
+		Where(sq.Or{
+			sq.Eq{"last_checked_at": nil},
+			sq.Lt{"last_checked_at": checkedBefore},
+		}).
+		OrderBy("last_checked_at NULLS FIRST", "id").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookmarks to check: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	bookmarks := []*model.Bookmark{}
+	for rows.Next() {
+		var bm model.Bookmark
+
+		if err := rows.Scan(&bm.ID, &bm.URL); err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark: %w", err)
+		}
+
+		bookmarks = append(bookmarks, &bm)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate bookmarks rows: %w", err)
+	}
+
+	return bookmarks, nil
+}
+
+// SetLinkCheck records the result of a link check. Consecutive failures are
+// counted up for broken links and reset otherwise.
+func (s *PostgresStorage) SetLinkCheck(ctx context.Context, id int, check model.LinkCheck) error {
+	failures := sq.Expr("0")
+	if check.Status == model.LinkStatusBroken {
+		failures = sq.Expr("consecutive_failures + 1")
+	}
+
+	stmt := sq.
+		Update("bookmarks").
+		Set("link_status", check.Status).
+		Set("http_status", check.HTTPStatus).
+		Set("final_url", check.FinalURL).
+		Set("last_checked_at", check.CheckedAt).
+		Set("consecutive_failures", failures).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("failed to set link check: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) GetLinkReport(ctx context.Context) (*model.LinkReport, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select().
+		Column(sq.Expr("COUNT(*) FILTER (WHERE link_status = ?)", model.LinkStatusOK)).
+		Column(sq.Expr("COUNT(*) FILTER (WHERE link_status = ?)", model.LinkStatusRedirected)).
+		Column(sq.Expr("COUNT(*) FILTER (WHERE link_status = ?)", model.LinkStatusBroken)).
+		Column("COUNT(*) FILTER (WHERE link_status IS NULL)").
+		Column("MAX(last_checked_at)").
+		From("bookmarks").
+		Where(sq.Eq{"user_id": userID, "deleted_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	var report model.LinkReport
+	err = stmt.QueryRowContext(ctx).Scan(&report.OK, &report.Redirected, &report.Broken, &report.Unchecked, &report.LastCheckedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link report: %w", err)
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,408 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+// GetBookmarks lists bookmarks like the PostgreSQL storage does, except that
+// search is a plain case-insensitive match of every word of the query, so
+// results carry no score or snippet.
+func (s *Storage) GetBookmarks(ctx context.Context, filter storage.BookmarkFilter) (*storage.BookmarkPage, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var words []string
+	if filter.Search != "" {
+		words = strings.Fields(strings.ToLower(filter.Search))
+	}
+
+	matches := []*bookmark{}
+	for _, b := range s.bookmarks {
+		if b.userID != userID || b.DeletedAt != nil {
+			continue
+		}
+
+		if filter.FolderID != nil && !sameInt(b.FolderID, filter.FolderID) {
+			continue
+		}
+
+		if filter.LinkStatus != "" && b.LinkStatus != filter.LinkStatus {
+			continue
+		}
+
+		if !matchesSearch(b, words) || !matchesTags(b, filter.Tags, filter.AnyTag) {
+			continue
+		}
+
+		matches = append(matches, b)
+	}
+
+	slices.SortFunc(matches, func(a, b *bookmark) int {
+		if a.newer(b.CreatedAt, b.ID) {
+			return -1
+		}
+		return 1
+	})
+
+	var total *int
+	if !filter.SkipCount {
+		count := len(matches)
+		total = &count
+	}
+
+	cursor := filter.Cursor
+	if filter.Search != "" {
+		cursor = nil
+	}
+
+	switch {
+	case cursor != nil && cursor.Backward:
+		i := slices.IndexFunc(matches, func(b *bookmark) bool {
+			return !b.newer(cursor.CreatedAt, cursor.ID)
+		})
+		if i >= 0 {
+			matches = matches[:i]
+		}
+
+		matches = slices.Clone(matches)
+		slices.Reverse(matches)
+	case cursor != nil:
+		i := slices.IndexFunc(matches, func(b *bookmark) bool {
+			return !b.newer(cursor.CreatedAt, cursor.ID) && !(b.CreatedAt.Equal(cursor.CreatedAt) && b.ID == cursor.ID)
+		})
+		if i < 0 {
+			i = len(matches)
+		}
+
+		matches = matches[i:]
+	default:
+		matches = matches[min(filter.Offset, len(matches)):]
+	}
+
+	// One extra bookmark tells whether there is a further page.
+	matches = matches[:min(filter.Limit+1, len(matches))]
+
+	bookmarks := make([]*model.Bookmark, 0, len(matches))
+	for _, b := range matches {
+		bookmarks = append(bookmarks, b.export())
+	}
+
+	return storage.NewBookmarkPage(filter, bookmarks, total), nil
+}
+
+func (s *Storage) GetBookmark(ctx context.Context, id int) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.liveBookmark(userID, id)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return b.export(), nil
+}
+
+func (s *Storage) CreateBookmark(ctx context.Context, title, url string, tags []string, folderID *int) (*model.Bookmark, error) {
+	now := time.Now()
+
+	return s.createBookmark(ctx, &model.Bookmark{
+		Title:     title,
+		URL:       url,
+		Tags:      tags,
+		FolderID:  folderID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// ImportBookmark creates a bookmark keeping its original timestamps.
+func (s *Storage) ImportBookmark(ctx context.Context, bookmark *model.Bookmark) (*model.Bookmark, error) {
+	return s.createBookmark(ctx, &model.Bookmark{
+		Title:     bookmark.Title,
+		URL:       bookmark.URL,
+		Tags:      bookmark.Tags,
+		FolderID:  bookmark.FolderID,
+		CreatedAt: bookmark.CreatedAt,
+		UpdatedAt: bookmark.UpdatedAt,
+	})
+}
+
+func (s *Storage) createBookmark(ctx context.Context, bm *model.Bookmark) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ownsFolder(userID, bm.FolderID) {
+		return nil, storage.ErrFolderNotFound
+	}
+
+	if s.urlTaken(userID, bm.URL, 0) {
+		return nil, storage.ErrExists
+	}
+
+	s.lastBookmarkID++
+
+	bm.ID = s.lastBookmarkID
+	bm.Tags = sortedTags(bm.Tags)
+	bm.FolderID = copyInt(bm.FolderID)
+	bm.Version = s.nextChangeSeq()
+
+	b := &bookmark{Bookmark: *bm, userID: userID}
+	s.bookmarks[b.ID] = b
+
+	s.recordRevision(b, model.RevisionCreated)
+
+	if err := s.recordEvent(userID, model.EventBookmarkCreated, b.export()); err != nil {
+		return nil, err
+	}
+
+	return b.export(), nil
+}
+
+func (s *Storage) EditBookmark(ctx context.Context, id int, title, url string, tags []string) (*model.Bookmark, error) {
+	return s.editBookmark(ctx, id, 0, title, url, tags)
+}
+
+// EditBookmarkIfUnchanged edits the bookmark only if it wasn't changed since
+// the given version, failing with ErrConflict otherwise.
+func (s *Storage) EditBookmarkIfUnchanged(ctx context.Context, id int, version int64, title, url string, tags []string) (*model.Bookmark, error) {
+	return s.editBookmark(ctx, id, version, title, url, tags)
+}
+
+// editBookmark edits the bookmark, checking its version unless it's zero.
+func (s *Storage) editBookmark(ctx context.Context, id int, version int64, title, url string, tags []string) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.liveBookmark(userID, id)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	if version > 0 && b.Version > version {
+		return nil, storage.ErrConflict
+	}
+
+	if s.urlTaken(userID, url, id) {
+		return nil, storage.ErrExists
+	}
+
+	b.Title = title
+	b.URL = url
+	if tags != nil {
+		b.Tags = sortedTags(tags)
+	}
+	b.UpdatedAt = time.Now()
+	b.Version = s.nextChangeSeq()
+
+	s.recordRevision(b, model.RevisionEdited)
+
+	if err := s.recordEvent(userID, model.EventBookmarkUpdated, b.export()); err != nil {
+		return nil, err
+	}
+
+	return b.export(), nil
+}
+
+// DeleteBookmark moves the bookmark to the trash, it's purged for good by
+// PurgeBookmark or once the trash retention period is over.
+func (s *Storage) DeleteBookmark(ctx context.Context, id int) error {
+	return s.deleteBookmark(ctx, id, 0)
+}
+
+// DeleteBookmarkIfUnchanged deletes the bookmark only if it wasn't changed
+// since the given version, failing with ErrConflict otherwise.
+func (s *Storage) DeleteBookmarkIfUnchanged(ctx context.Context, id int, version int64) error {
+	return s.deleteBookmark(ctx, id, version)
+}
+
+// deleteBookmark deletes the bookmark, checking its version unless it's zero.
+func (s *Storage) deleteBookmark(ctx context.Context, id int, version int64) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.liveBookmark(userID, id)
+	if !ok {
+		return storage.ErrNotFound
+	}
+
+	if version > 0 && b.Version > version {
+		return storage.ErrConflict
+	}
+
+	now := time.Now()
+	b.DeletedAt = &now
+	b.Version = s.nextChangeSeq()
+
+	s.recordRevision(b, model.RevisionDeleted)
+
+	return s.recordEvent(userID, model.EventBookmarkDeleted, map[string]int{"id": id})
+}
+
+func (s *Storage) BookmarkExist(ctx context.Context, url string) (int, bool, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, b := range s.bookmarks {
+		if b.userID == userID && b.DeletedAt == nil && b.URL == url {
+			return b.ID, true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+func (s *Storage) MoveBookmark(ctx context.Context, id int, folderID *int) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ownsFolder(userID, folderID) {
+		return nil, storage.ErrFolderNotFound
+	}
+
+	b, ok := s.liveBookmark(userID, id)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	b.FolderID = copyInt(folderID)
+	b.UpdatedAt = time.Now()
+	b.Version = s.nextChangeSeq()
+
+	if err := s.recordEvent(userID, model.EventBookmarkUpdated, b.export()); err != nil {
+		return nil, err
+	}
+
+	return b.export(), nil
+}
+
+func (s *Storage) GetTags(ctx context.Context) ([]*model.Tag, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, b := range s.bookmarks {
+		if b.userID != userID || b.DeletedAt != nil {
+			continue
+		}
+
+		for _, tag := range b.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := []*model.Tag{}
+	for name, count := range counts {
+		tags = append(tags, &model.Tag{Name: name, Count: count})
+	}
+
+	slices.SortFunc(tags, func(a, b *model.Tag) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return tags, nil
+}
+
+// liveBookmark returns the bookmark of the user unless it's in the trash.
+func (s *Storage) liveBookmark(userID, id int) (*bookmark, bool) {
+	b, ok := s.bookmarks[id]
+	if !ok || b.userID != userID || b.DeletedAt != nil {
+		return nil, false
+	}
+
+	return b, true
+}
+
+// urlTaken tells whether the user already has a live bookmark for the URL,
+// other than the one with the given id.
+func (s *Storage) urlTaken(userID int, url string, exceptID int) bool {
+	for _, b := range s.bookmarks {
+		if b.userID == userID && b.DeletedAt == nil && b.URL == url && b.ID != exceptID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesSearch(b *bookmark, words []string) bool {
+	text := strings.ToLower(b.Title + " " + b.Description + " " + b.URL)
+
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesTags tells whether the bookmark carries all of the tags, or any of
+// them with anyTag set.
+func matchesTags(b *bookmark, tags []string, anyTag bool) bool {
+	if len(tags) == 0 {
+		return true
+	}
+
+	for _, tag := range tags {
+		found := slices.Contains(b.Tags, tag)
+
+		if anyTag && found {
+			return true
+		}
+		if !anyTag && !found {
+			return false
+		}
+	}
+
+	return !anyTag
+}
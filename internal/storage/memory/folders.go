@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+func (s *Storage) GetFolders(ctx context.Context) ([]*model.Folder, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	folders := []*model.Folder{}
+	for _, f := range s.folders {
+		if f.userID == userID {
+			folders = append(folders, f.export())
+		}
+	}
+
+	slices.SortFunc(folders, func(a, b *model.Folder) int {
+		if a.Name != b.Name {
+			return strings.Compare(a.Name, b.Name)
+		}
+		return a.ID - b.ID
+	})
+
+	return folders, nil
+}
+
+func (s *Storage) CreateFolder(ctx context.Context, name string, parentID *int) (*model.Folder, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.createFolder(userID, name, parentID)
+}
+
+// ImportFolder returns the folder with the given name under the given parent,
+// creating it if there is none, so repeated imports don't duplicate folders.
+func (s *Storage) ImportFolder(ctx context.Context, name string, parentID *int) (*model.Folder, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found *folder
+	for _, f := range s.folders {
+		if f.userID == userID && f.Name == name && sameInt(f.ParentID, parentID) && (found == nil || f.ID < found.ID) {
+			found = f
+		}
+	}
+
+	if found != nil {
+		return found.export(), nil
+	}
+
+	return s.createFolder(userID, name, parentID)
+}
+
+func (s *Storage) createFolder(userID int, name string, parentID *int) (*model.Folder, error) {
+	if !s.ownsFolder(userID, parentID) {
+		return nil, storage.ErrFolderNotFound
+	}
+
+	s.lastFolderID++
+	now := time.Now()
+
+	f := &folder{
+		Folder: model.Folder{
+			ID:        s.lastFolderID,
+			Name:      name,
+			ParentID:  copyInt(parentID),
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		userID: userID,
+	}
+	s.folders[f.ID] = f
+
+	return f.export(), nil
+}
+
+func (s *Storage) EditFolder(ctx context.Context, id int, name string, parentID *int) (*model.Folder, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ownsFolder(userID, parentID) {
+		return nil, storage.ErrFolderNotFound
+	}
+
+	// The new parent must not be the folder itself or one of its subfolders.
+	for p := parentID; p != nil; p = s.folders[*p].ParentID {
+		if *p == id {
+			return nil, storage.ErrFolderCycle
+		}
+	}
+
+	f, ok := s.folders[id]
+	if !ok || f.userID != userID {
+		return nil, storage.ErrFolderNotFound
+	}
+
+	f.Name = name
+	f.ParentID = copyInt(parentID)
+	f.UpdatedAt = time.Now()
+
+	return f.export(), nil
+}
+
+// DeleteFolder removes the folder together with its subfolders. Bookmarks
+// stored in them are moved to the root.
+func (s *Storage) DeleteFolder(ctx context.Context, id int) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f, ok := s.folders[id]; !ok || f.userID != userID {
+		return storage.ErrFolderNotFound
+	}
+
+	subtree := []int{id}
+	for i := 0; i < len(subtree); i++ {
+		for _, f := range s.folders {
+			if f.ParentID != nil && *f.ParentID == subtree[i] {
+				subtree = append(subtree, f.ID)
+			}
+		}
+	}
+
+	for _, folderID := range subtree {
+		delete(s.folders, folderID)
+	}
+
+	for _, b := range s.bookmarks {
+		if b.FolderID != nil && slices.Contains(subtree, *b.FolderID) {
+			b.FolderID = nil
+			b.Version = s.nextChangeSeq()
+		}
+	}
+
+	return nil
+}
+
+// ownsFolder makes sure the folder, when one is given, belongs to the user.
+func (s *Storage) ownsFolder(userID int, folderID *int) bool {
+	if folderID == nil {
+		return true
+	}
+
+	f, ok := s.folders[*folderID]
+	return ok && f.userID == userID
+}
+
+func (f *folder) export() *model.Folder {
+	folder := f.Folder
+	folder.ParentID = copyInt(f.ParentID)
+
+	return &folder
+}
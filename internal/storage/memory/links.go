@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+// SetBookmarkMetadata stores the fetched page metadata of the bookmark. The
+// title is only replaced while it's still the URL placeholder, so titles
+// chosen by the user are kept.
+//
+// Changes the owner can see are recorded like edits: a new title adds a
+// revision, and any change sends a bookmark.updated event.
+func (s *Storage) SetBookmarkMetadata(ctx context.Context, id int, metadata model.Metadata) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.bookmarks[id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+
+	before := b.Bookmark
+
+	if b.Title == b.URL && metadata.Title != "" {
+		b.Title = metadata.Title
+	}
+
+	now := time.Now()
+
+	b.Description = metadata.Description
+	b.ImageURL = metadata.ImageURL
+	b.FaviconURL = metadata.FaviconURL
+	b.MetadataFetchedAt = &now
+	b.Version = s.nextChangeSeq()
+
+	// Bookmarks in the trash are updated silently, they're restored as they
+	// are now.
+	if b.DeletedAt != nil {
+		return nil
+	}
+
+	if b.Title != before.Title {
+		s.recordRevision(b, model.RevisionEdited)
+	}
+
+	if b.Title != before.Title || b.Description != before.Description ||
+		b.ImageURL != before.ImageURL || b.FaviconURL != before.FaviconURL {
+		return s.recordEvent(b.userID, model.EventBookmarkUpdated, b.export())
+	}
+
+	return nil
+}
+
+// GetBookmarksToCheck returns bookmarks of all users that were never checked
+// or were last checked before the given time, least recently checked first.
+func (s *Storage) GetBookmarksToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*model.Bookmark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := []*bookmark{}
+	for _, b := range s.bookmarks {
+		if b.DeletedAt == nil && (b.LastCheckedAt == nil || b.LastCheckedAt.Before(checkedBefore)) {
+			due = append(due, b)
+		}
+	}
+
+	slices.SortFunc(due, func(a, b *bookmark) int {
+		switch {
+		case a.LastCheckedAt == nil && b.LastCheckedAt != nil:
+			return -1
+		case a.LastCheckedAt != nil && b.LastCheckedAt == nil:
+			return 1
+		case a.LastCheckedAt != nil && !a.LastCheckedAt.Equal(*b.LastCheckedAt):
+			return a.LastCheckedAt.Compare(*b.LastCheckedAt)
+		}
+		return a.ID - b.ID
+	})
+
+	bookmarks := []*model.Bookmark{}
+	for _, b := range due[:min(limit, len(due))] {
+		bookmarks = append(bookmarks, &model.Bookmark{ID: b.ID, URL: b.URL})
+	}
+
+	return bookmarks, nil
+}
+
+// SetLinkCheck records the result of a link check. Consecutive failures are
+// counted up for broken links and reset otherwise.
+func (s *Storage) SetLinkCheck(ctx context.Context, id int, check model.LinkCheck) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.bookmarks[id]
+	if !ok {
+		return nil
+	}
+
+	checkedAt := check.CheckedAt
+
+	b.LinkStatus = check.Status
+	b.HTTPStatus = copyInt(check.HTTPStatus)
+	b.FinalURL = check.FinalURL
+	b.LastCheckedAt = &checkedAt
+
+	if check.Status == model.LinkStatusBroken {
+		b.ConsecutiveFailures++
+	} else {
+		b.ConsecutiveFailures = 0
+	}
+
+	return nil
+}
+
+func (s *Storage) GetLinkReport(ctx context.Context) (*model.LinkReport, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var report model.LinkReport
+	for _, b := range s.bookmarks {
+		if b.userID != userID || b.DeletedAt != nil {
+			continue
+		}
+
+		switch b.LinkStatus {
+		case model.LinkStatusOK:
+			report.OK++
+		case model.LinkStatusRedirected:
+			report.Redirected++
+		case model.LinkStatusBroken:
+			report.Broken++
+		case "":
+			report.Unchecked++
+		}
+
+		if b.LastCheckedAt != nil && (report.LastCheckedAt == nil || b.LastCheckedAt.After(*report.LastCheckedAt)) {
+			report.LastCheckedAt = b.LastCheckedAt
+		}
+	}
+
+	return &report, nil
+}
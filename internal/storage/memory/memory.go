@@ -0,0 +1,203 @@
+// Package memory keeps everything in process memory. Nothing survives a
+// restart, it's meant for demos, development and running the API without a
+// database.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+var _ storage.Storage = (*Storage)(nil)
+
+type bookmark struct {
+	model.Bookmark
+	userID int
+}
+
+type folder struct {
+	model.Folder
+	userID int
+}
+
+type token struct {
+	model.Token
+	userID int
+	hash   string
+}
+
+type webhook struct {
+	model.Webhook
+	userID int
+	secret string
+}
+
+type event struct {
+	model.StoredEvent
+	userID     int
+	dispatched bool
+}
+
+// Storage is an in-memory storage.Storage. Every method takes the same lock,
+// which plays the part of database transactions.
+type Storage struct {
+	mu sync.Mutex
+
+	users      []*model.User
+	tokens     []*token
+	bookmarks  map[int]*bookmark
+	revisions  map[int][]*model.Revision
+	folders    map[int]*folder
+	webhooks   map[int]*webhook
+	events     []*event
+	deliveries []*model.WebhookDelivery
+
+	// resets holds the change sequence number of the latest purged bookmark
+	// of each user, see storage.Storage.GetChanges.
+	resets map[int]int64
+
+	lastUserID     int
+	lastTokenID    int
+	lastBookmarkID int
+	lastFolderID   int
+	lastWebhookID  int
+	lastDeliveryID int64
+	lastChangeSeq  int64
+
+	listeners      map[int]func(userID int)
+	lastListenerID int
+}
+
+func New() *Storage {
+	return &Storage{
+		bookmarks: make(map[int]*bookmark),
+		revisions: make(map[int][]*model.Revision),
+		folders:   make(map[int]*folder),
+		webhooks:  make(map[int]*webhook),
+		resets:    make(map[int]int64),
+		listeners: make(map[int]func(int)),
+	}
+}
+
+func (s *Storage) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *Storage) Close() error {
+	return nil
+}
+
+// ListenEvents calls notify for every event recorded until ctx is cancelled.
+// Events never leave the process, so there is nothing to miss.
+func (s *Storage) ListenEvents(ctx context.Context, notify func(userID int)) error {
+	s.mu.Lock()
+	s.lastListenerID++
+	id := s.lastListenerID
+	s.listeners[id] = notify
+	s.mu.Unlock()
+
+	<-ctx.Done()
+
+	s.mu.Lock()
+	delete(s.listeners, id)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// nextChangeSeq numbers a bookmark change, see model.Bookmark.Version.
+func (s *Storage) nextChangeSeq() int64 {
+	s.lastChangeSeq++
+	return s.lastChangeSeq
+}
+
+// recordRevision snapshots the current state of the bookmark as its next revision.
+func (s *Storage) recordRevision(b *bookmark, action string) {
+	revisions := s.revisions[b.ID]
+
+	s.revisions[b.ID] = append(revisions, &model.Revision{
+		Revision:  len(revisions) + 1,
+		Action:    action,
+		Title:     b.Title,
+		URL:       b.URL,
+		Tags:      slices.Clone(b.Tags),
+		CreatedAt: time.Now(),
+	})
+}
+
+// recordEvent adds a bookmark lifecycle event to the outbox and notifies the
+// event listeners.
+func (s *Storage) recordEvent(userID int, name string, data any) error {
+	payload, err := json.Marshal(model.Event{
+		Event:      name,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	s.events = append(s.events, &event{
+		StoredEvent: model.StoredEvent{
+			ID:      int64(len(s.events) + 1),
+			Event:   name,
+			Payload: payload,
+		},
+		userID: userID,
+	})
+
+	for _, notify := range s.listeners {
+		notify(userID)
+	}
+
+	return nil
+}
+
+// export returns a copy of the bookmark that is safe to hand out.
+func (b *bookmark) export() *model.Bookmark {
+	bm := b.Bookmark
+	bm.Tags = slices.Clone(b.Tags)
+
+	return &bm
+}
+
+// newer tells whether the bookmark comes before the given position in the
+// bookmarks list, which is ordered by creation time and id, newest first.
+func (b *bookmark) newer(createdAt time.Time, id int) bool {
+	if b.CreatedAt.Equal(createdAt) {
+		return b.ID > id
+	}
+
+	return b.CreatedAt.After(createdAt)
+}
+
+func sortedTags(tags []string) []string {
+	sorted := append([]string{}, tags...)
+	slices.Sort(sorted)
+
+	return slices.Compact(sorted)
+}
+
+func copyInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+
+	c := *v
+	return &c
+}
+
+func sameInt(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
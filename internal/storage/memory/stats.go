@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+// GetStats counts users and bookmarks of all users.
+func (s *Storage) GetStats(ctx context.Context) (*model.Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := model.Stats{
+		Users: len(s.users),
+	}
+
+	for _, bm := range s.bookmarks {
+		switch {
+		case bm.DeletedAt != nil:
+			stats.Trashed++
+		case bm.LinkStatus == model.LinkStatusBroken:
+			stats.Bookmarks++
+			stats.BrokenLinks++
+		default:
+			stats.Bookmarks++
+		}
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+// GetTrash returns the deleted bookmarks of the user, most recently deleted first.
+func (s *Storage) GetTrash(ctx context.Context) ([]*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trashed := []*bookmark{}
+	for _, b := range s.bookmarks {
+		if b.userID == userID && b.DeletedAt != nil {
+			trashed = append(trashed, b)
+		}
+	}
+
+	slices.SortFunc(trashed, func(a, b *bookmark) int {
+		if !a.DeletedAt.Equal(*b.DeletedAt) {
+			return b.DeletedAt.Compare(*a.DeletedAt)
+		}
+		return b.ID - a.ID
+	})
+
+	bookmarks := make([]*model.Bookmark, 0, len(trashed))
+	for _, b := range trashed {
+		bookmarks = append(bookmarks, b.export())
+	}
+
+	return bookmarks, nil
+}
+
+// RestoreBookmark takes the bookmark out of the trash. It fails with
+// ErrExists when the URL was saved again in the meantime.
+func (s *Storage) RestoreBookmark(ctx context.Context, id int) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.bookmarks[id]
+	if !ok || b.userID != userID || b.DeletedAt == nil {
+		return nil, storage.ErrNotFound
+	}
+
+	if s.urlTaken(userID, b.URL, id) {
+		return nil, storage.ErrExists
+	}
+
+	b.DeletedAt = nil
+	b.Version = s.nextChangeSeq()
+
+	s.recordRevision(b, model.RevisionRestored)
+
+	if err := s.recordEvent(userID, model.EventBookmarkUpdated, b.export()); err != nil {
+		return nil, err
+	}
+
+	return b.export(), nil
+}
+
+// PurgeBookmark permanently deletes a bookmark from the trash.
+func (s *Storage) PurgeBookmark(ctx context.Context, id int) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.bookmarks[id]
+	if !ok || b.userID != userID || b.DeletedAt == nil {
+		return storage.ErrNotFound
+	}
+
+	s.purge(b)
+
+	return nil
+}
+
+// PurgeTrash permanently deletes bookmarks of all users that were moved to
+// the trash before the given time and returns how many were deleted.
+func (s *Storage) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for _, b := range s.bookmarks {
+		if b.DeletedAt != nil && b.DeletedAt.Before(deletedBefore) {
+			s.purge(b)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// purge deletes the bookmark with its history and makes sync clients that
+// saw it start over.
+func (s *Storage) purge(b *bookmark) {
+	delete(s.bookmarks, b.ID)
+	delete(s.revisions, b.ID)
+
+	s.resets[b.userID] = max(s.resets[b.userID], b.Version)
+}
+
+// GetBookmarkHistory returns the revisions of the bookmark, newest first,
+// each with its changes from the revision before it.
+func (s *Storage) GetBookmarkHistory(ctx context.Context, id int) ([]*model.Revision, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.bookmarks[id]
+	if !ok || b.userID != userID || len(s.revisions[id]) == 0 {
+		return nil, storage.ErrNotFound
+	}
+
+	revisions := make([]*model.Revision, 0, len(s.revisions[id]))
+	var prev *model.Revision
+
+	for _, r := range s.revisions[id] {
+		rev := *r
+		rev.Tags = slices.Clone(r.Tags)
+		rev.Changes = rev.Diff(prev)
+		prev = &rev
+
+		revisions = append(revisions, &rev)
+	}
+
+	slices.Reverse(revisions)
+
+	return revisions, nil
+}
+
+// RevertBookmark restores the title, url and tags the bookmark had at the
+// given revision, recording it as a new revision.
+func (s *Storage) RevertBookmark(ctx context.Context, id, revision int) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.liveBookmark(userID, id)
+	if !ok || revision < 1 || revision > len(s.revisions[id]) {
+		return nil, storage.ErrRevisionNotFound
+	}
+
+	rev := s.revisions[id][revision-1]
+
+	if s.urlTaken(userID, rev.URL, id) {
+		return nil, storage.ErrExists
+	}
+
+	b.Title = rev.Title
+	b.URL = rev.URL
+	b.Tags = slices.Clone(rev.Tags)
+	b.UpdatedAt = time.Now()
+	b.Version = s.nextChangeSeq()
+
+	s.recordRevision(b, model.RevisionReverted)
+
+	if err := s.recordEvent(userID, model.EventBookmarkUpdated, b.export()); err != nil {
+		return nil, err
+	}
+
+	return b.export(), nil
+}
@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+// tokenUsageResolution limits how often last_used_at is updated, matching
+// the PostgreSQL storage.
+const tokenUsageResolution = time.Minute
+
+func (s *Storage) CreateUser(ctx context.Context, username, passwordHash string) (*model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Username == username {
+			return nil, storage.ErrUserExists
+		}
+	}
+
+	s.lastUserID++
+
+	u := &model.User{
+		ID:           s.lastUserID,
+		Username:     username,
+		PasswordHash: passwordHash,
+		CreatedAt:    time.Now(),
+	}
+	s.users = append(s.users, u)
+
+	user := *u
+	return &user, nil
+}
+
+func (s *Storage) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Username == username {
+			user := *u
+			return &user, nil
+		}
+	}
+
+	return nil, storage.ErrUserNotFound
+}
+
+// GetUsers returns all users in the order they signed up.
+func (s *Storage) GetUsers(ctx context.Context) ([]*model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := make([]*model.User, 0, len(s.users))
+	for _, u := range s.users {
+		user := *u
+		users = append(users, &user)
+	}
+
+	return users, nil
+}
+
+func (s *Storage) GetTokens(ctx context.Context) ([]*model.Token, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens := []*model.Token{}
+	for _, t := range slices.Backward(s.tokens) {
+		if t.userID == userID {
+			tokens = append(tokens, t.export())
+		}
+	}
+
+	return tokens, nil
+}
+
+func (s *Storage) CreateToken(ctx context.Context, name, tokenHash string, scopes []string) (*model.Token, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastTokenID++
+
+	t := &token{
+		Token: model.Token{
+			ID:        s.lastTokenID,
+			Name:      name,
+			Scopes:    slices.Clone(scopes),
+			CreatedAt: time.Now(),
+		},
+		userID: userID,
+		hash:   tokenHash,
+	}
+	s.tokens = append(s.tokens, t)
+
+	return t.export(), nil
+}
+
+func (s *Storage) RevokeToken(ctx context.Context, id int) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tokens {
+		if t.ID == id && t.userID == userID && t.RevokedAt == nil {
+			now := time.Now()
+			t.RevokedAt = &now
+
+			return nil
+		}
+	}
+
+	return storage.ErrTokenNotFound
+}
+
+// AuthenticateToken finds the owner of an active token by the token hash and
+// records that the token was used.
+func (s *Storage) AuthenticateToken(ctx context.Context, tokenHash string) (*model.User, *model.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tokens {
+		if t.hash != tokenHash || t.RevokedAt != nil {
+			continue
+		}
+
+		if t.LastUsedAt == nil || time.Since(*t.LastUsedAt) > tokenUsageResolution {
+			now := time.Now()
+			t.LastUsedAt = &now
+		}
+
+		for _, u := range s.users {
+			if u.ID == t.userID {
+				user := *u
+				return &user, t.export(), nil
+			}
+		}
+	}
+
+	return nil, nil, storage.ErrTokenNotFound
+}
+
+func (t *token) export() *model.Token {
+	token := t.Token
+	token.Scopes = slices.Clone(t.Scopes)
+
+	return &token
+}
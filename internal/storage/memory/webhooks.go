@@ -0,0 +1,364 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+func (s *Storage) GetWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	webhooks := []*model.Webhook{}
+	for _, wh := range s.webhooks {
+		if wh.userID == userID {
+			webhooks = append(webhooks, wh.export())
+		}
+	}
+
+	slices.SortFunc(webhooks, func(a, b *model.Webhook) int {
+		return b.ID - a.ID
+	})
+
+	return webhooks, nil
+}
+
+func (s *Storage) CreateWebhook(ctx context.Context, url, secret string, events []string) (*model.Webhook, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastWebhookID++
+
+	wh := &webhook{
+		Webhook: model.Webhook{
+			ID:        s.lastWebhookID,
+			URL:       url,
+			Events:    slices.Clone(events),
+			CreatedAt: time.Now(),
+		},
+		userID: userID,
+		secret: secret,
+	}
+	s.webhooks[wh.ID] = wh
+
+	return wh.export(), nil
+}
+
+func (s *Storage) DeleteWebhook(ctx context.Context, id int) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if wh, ok := s.webhooks[id]; !ok || wh.userID != userID {
+		return storage.ErrWebhookNotFound
+	}
+
+	delete(s.webhooks, id)
+
+	s.deliveries = slices.DeleteFunc(s.deliveries, func(d *model.WebhookDelivery) bool {
+		return d.WebhookID == id
+	})
+
+	return nil
+}
+
+// GetWebhookDeliveries returns the delivery log of the webhook, newest first.
+func (s *Storage) GetWebhookDeliveries(ctx context.Context, webhookID, limit int) ([]*model.WebhookDelivery, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if wh, ok := s.webhooks[webhookID]; !ok || wh.userID != userID {
+		return nil, storage.ErrWebhookNotFound
+	}
+
+	deliveries := []*model.WebhookDelivery{}
+	for _, d := range slices.Backward(s.deliveries) {
+		if len(deliveries) == limit {
+			break
+		}
+
+		if d.WebhookID == webhookID {
+			delivery := *d
+			deliveries = append(deliveries, &delivery)
+		}
+	}
+
+	return deliveries, nil
+}
+
+// ReplayDelivery schedules the event of a past delivery to be sent again. The
+// original delivery is kept in the log untouched.
+func (s *Storage) ReplayDelivery(ctx context.Context, webhookID int, deliveryID int64) (*model.WebhookDelivery, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if wh, ok := s.webhooks[webhookID]; !ok || wh.userID != userID {
+		return nil, storage.ErrWebhookNotFound
+	}
+
+	for _, d := range s.deliveries {
+		if d.ID == deliveryID && d.WebhookID == webhookID {
+			replay := *s.addDelivery(webhookID, s.events[d.EventID-1])
+			return &replay, nil
+		}
+	}
+
+	return nil, storage.ErrDeliveryNotFound
+}
+
+// DispatchEvents fans out up to limit pending outbox events into deliveries
+// for the webhooks subscribed to them and returns how many events it handled.
+func (s *Storage) DispatchEvents(ctx context.Context, limit int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	webhooks := make([]*webhook, 0, len(s.webhooks))
+	for _, wh := range s.webhooks {
+		webhooks = append(webhooks, wh)
+	}
+
+	slices.SortFunc(webhooks, func(a, b *webhook) int {
+		return a.ID - b.ID
+	})
+
+	dispatched := 0
+	for _, e := range s.events {
+		if dispatched == limit {
+			break
+		}
+
+		if e.dispatched {
+			continue
+		}
+
+		for _, wh := range webhooks {
+			if wh.userID == e.userID && slices.Contains(wh.Events, e.Event) {
+				s.addDelivery(wh.ID, e)
+			}
+		}
+
+		e.dispatched = true
+		dispatched++
+	}
+
+	return dispatched, nil
+}
+
+// ClaimDeliveries returns up to limit deliveries that are due, pushing their
+// next attempt by lease so that concurrent workers don't pick them up twice.
+func (s *Storage) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	due := []*model.WebhookDelivery{}
+	for _, d := range s.deliveries {
+		if d.Status == model.DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+
+	slices.SortStableFunc(due, func(a, b *model.WebhookDelivery) int {
+		return a.NextAttemptAt.Compare(*b.NextAttemptAt)
+	})
+
+	leasedUntil := now.Add(lease)
+
+	deliveries := []*model.WebhookDelivery{}
+	for _, d := range due[:min(limit, len(due))] {
+		d.NextAttemptAt = &leasedUntil
+
+		delivery := *d
+		delivery.URL = s.webhooks[d.WebhookID].URL
+		delivery.Secret = s.webhooks[d.WebhookID].secret
+		delivery.Payload = s.events[d.EventID-1].Payload
+
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, nil
+}
+
+// SetDeliveryResult records the outcome of a delivery attempt.
+func (s *Storage) SetDeliveryResult(ctx context.Context, id int64, result model.DeliveryResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.deliveries, func(d *model.WebhookDelivery) bool {
+		return d.ID == id
+	})
+	if i < 0 {
+		return nil
+	}
+
+	d := s.deliveries[i]
+	now := time.Now()
+
+	switch {
+	case result.Succeeded:
+		d.Status = model.DeliverySucceeded
+	case result.NextAttemptAt == nil:
+		d.Status = model.DeliveryFailed
+	}
+
+	d.Attempts++
+	d.LastAttemptAt = &now
+	d.ResponseStatus = copyInt(result.ResponseStatus)
+	d.Error = result.Error
+
+	if result.NextAttemptAt != nil {
+		next := *result.NextAttemptAt
+		d.NextAttemptAt = &next
+	}
+
+	return nil
+}
+
+// addDelivery schedules the event to be sent to the webhook right away.
+func (s *Storage) addDelivery(webhookID int, e *event) *model.WebhookDelivery {
+	s.lastDeliveryID++
+	now := time.Now()
+
+	d := &model.WebhookDelivery{
+		ID:            s.lastDeliveryID,
+		WebhookID:     webhookID,
+		EventID:       e.ID,
+		Event:         e.Event,
+		Status:        model.DeliveryPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+	}
+	s.deliveries = append(s.deliveries, d)
+
+	return d
+}
+
+// GetEventsSince returns up to limit events of the user recorded after the
+// given event id, oldest first.
+func (s *Storage) GetEventsSince(ctx context.Context, afterID int64, limit int) ([]*model.StoredEvent, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := []*model.StoredEvent{}
+	for _, e := range s.events[min(max(afterID, 0), int64(len(s.events))):] {
+		if len(events) == limit {
+			break
+		}
+
+		if e.userID == userID {
+			stored := e.StoredEvent
+			events = append(events, &stored)
+		}
+	}
+
+	return events, nil
+}
+
+// GetLastEventID returns the id of the latest event of the user, or 0 when
+// there is none yet.
+func (s *Storage) GetLastEventID(ctx context.Context) (int64, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range slices.Backward(s.events) {
+		if e.userID == userID {
+			return e.ID, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// GetChanges returns up to limit bookmarks of the user changed after the
+// given change sequence number, trashed ones included as tombstones. When
+// bookmarks were purged since then, Reset is set and changes are listed from
+// the beginning.
+func (s *Storage) GetChanges(ctx context.Context, since int64, limit int) (*model.ChangeSet, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes := &model.ChangeSet{}
+
+	if since > 0 && since < s.resets[userID] {
+		changes.Reset = true
+		since = 0
+	}
+
+	changed := []*bookmark{}
+	for _, b := range s.bookmarks {
+		if b.userID == userID && b.Version > since {
+			changed = append(changed, b)
+		}
+	}
+
+	slices.SortFunc(changed, func(a, b *bookmark) int {
+		return int(a.Version - b.Version)
+	})
+
+	changes.HasMore = len(changed) > limit
+	changed = changed[:min(limit, len(changed))]
+
+	changes.Bookmarks = make([]*model.Bookmark, 0, len(changed))
+	for _, b := range changed {
+		changes.Bookmarks = append(changes.Bookmarks, b.export())
+	}
+
+	changes.Since = since
+	if len(changed) > 0 {
+		changes.Since = changed[len(changed)-1].Version
+	}
+
+	return changes, nil
+}
+
+func (wh *webhook) export() *model.Webhook {
+	webhook := wh.Webhook
+	webhook.Events = slices.Clone(wh.Events)
+
+	return &webhook
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+// SetBookmarkMetadata stores the fetched page metadata of the bookmark. The
+// title is only replaced while it's still the URL placeholder, so titles
+// chosen by the user are kept. It's called by background jobs and therefore
+// isn't scoped to a user.
+//
+// Changes the owner can see are recorded like edits: a new title adds a
+// revision, and any change sends a bookmark.updated event.
+func (s *PostgresStorage) SetBookmarkMetadata(ctx context.Context, id int, metadata model.Metadata) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var userID int
+	var before model.Bookmark
+
+	err = sq.
+		Select("user_id", "url", "title", "description", "image_url", "favicon_url", "deleted_at").
+		From("bookmarks").
+		Where(sq.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx).
+		QueryRowContext(ctx).
+		Scan(&userID, &before.URL, &before.Title, &before.Description, &before.ImageURL, &before.FaviconURL, &before.DeletedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+
+		return fmt.Errorf("failed to get bookmark: %w", err)
+	}
+
+	title := before.Title
+	if title == before.URL && metadata.Title != "" {
+		title = metadata.Title
+	}
+
+	var bm model.Bookmark
+
+	err = sq.
+		Update("bookmarks").
+		Set("title", title).
+		Set("description", metadata.Description).
+		Set("image_url", metadata.ImageURL).
+		Set("favicon_url", metadata.FaviconURL).
+		Set("metadata_fetched_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": id}).
+		Suffix(bookmarkReturning).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx).
+		QueryRowContext(ctx).
+		Scan(bookmarkFields(&bm)...)
+	if err != nil {
+		return fmt.Errorf("failed to set bookmark metadata: %w", err)
+	}
+
+	// Bookmarks in the trash are updated silently, they're restored as they
+	// are now.
+	if before.DeletedAt == nil {
+		if bm.Title != before.Title {
+			if err := recordRevision(ctx, tx, id, model.RevisionEdited); err != nil {
+				return err
+			}
+		}
+
+		if metadataChanged(&before, &bm) {
+			bm.Tags, err = getBookmarkTags(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+
+			if err := recordEvent(ctx, tx, userID, model.EventBookmarkUpdated, &bm); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// metadataChanged tells whether a metadata update changed a field the
+// bookmark owner can see.
+func metadataChanged(before, after *model.Bookmark) bool {
+	return before.Title != after.Title ||
+		before.Description != after.Description ||
+		before.ImageURL != after.ImageURL ||
+		before.FaviconURL != after.FaviconURL
+}
@@ -0,0 +1,321 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/haadi-coder/bookmark-manager/migrations"
+	"github.com/lib/pq"
+)
+
+// migrationsTable keeps the layout of golang-migrate, so databases migrated
+// by the migrate container carry on from their version.
+const migrationsTable = "schema_migrations"
+
+var migrationFileRe = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version uint
+	Name    string
+
+	up   string
+	down string
+}
+
+// SchemaStatus describes the version of the database schema compared to the
+// migrations embedded in the binary.
+type SchemaStatus struct {
+	Version uint
+	Latest  uint
+	Dirty   bool
+	Pending []*Migration
+}
+
+// Behind reports whether the schema is missing migrations the binary expects.
+func (s *SchemaStatus) Behind() bool {
+	return s.Dirty || s.Version < s.Latest
+}
+
+var loadMigrations = sync.OnceValues(func() ([]*Migration, error) {
+	entries, err := fs.ReadDir(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		match := migrationFileRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse migration version %s: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(migrations.FS, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[m.Version] = m
+		}
+
+		if match[3] == "up" {
+			m.up = string(content)
+		} else {
+			m.down = string(content)
+		}
+	}
+
+	all := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("migration %d has no up script", m.Version)
+		}
+
+		all = append(all, m)
+	}
+
+	slices.SortFunc(all, func(a, b *Migration) int {
+		return int(a.Version) - int(b.Version)
+	})
+
+	return all, nil
+})
+
+// SchemaStatus reads the schema version without changing anything, a
+// database that was never migrated is at version 0.
+func (s *PostgresStorage) SchemaStatus(ctx context.Context) (*SchemaStatus, error) {
+	all, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	version, dirty, err := schemaVersion(ctx, s.db)
+	if err != nil {
+		var pqErr *pq.Error
+		if !errors.As(err, &pqErr) || pqErr.Code != undefinedTable {
+			return nil, err
+		}
+	}
+
+	status := SchemaStatus{
+		Version: version,
+		Dirty:   dirty,
+		Pending: []*Migration{},
+	}
+
+	for _, m := range all {
+		status.Latest = m.Version
+
+		if m.Version > version {
+			status.Pending = append(status.Pending, m)
+		}
+	}
+
+	return &status, nil
+}
+
+// MigrateUp applies all pending migrations and returns how many were applied.
+func (s *PostgresStorage) MigrateUp(ctx context.Context) (int, error) {
+	return s.migrate(ctx, func(current uint, all []*Migration) (uint, error) {
+		if len(all) == 0 {
+			return current, nil
+		}
+
+		return max(current, all[len(all)-1].Version), nil
+	})
+}
+
+// MigrateDown rolls back the given number of applied migrations.
+func (s *PostgresStorage) MigrateDown(ctx context.Context, steps int) (int, error) {
+	return s.migrate(ctx, func(current uint, all []*Migration) (uint, error) {
+		if current == 0 {
+			return 0, nil
+		}
+
+		i := slices.IndexFunc(all, func(m *Migration) bool { return m.Version == current })
+		if i < 0 {
+			return 0, fmt.Errorf("%w: %d", ErrUnknownMigration, current)
+		}
+
+		if i-steps < 0 {
+			return 0, nil
+		}
+
+		return all[i-steps].Version, nil
+	})
+}
+
+// MigrateTo migrates up or down to the given version, 0 rolls back
+// everything.
+func (s *PostgresStorage) MigrateTo(ctx context.Context, version uint) (int, error) {
+	return s.migrate(ctx, func(current uint, all []*Migration) (uint, error) {
+		if version != 0 && !slices.ContainsFunc(all, func(m *Migration) bool { return m.Version == version }) {
+			return 0, fmt.Errorf("%w: %d", ErrUnknownMigration, version)
+		}
+
+		return version, nil
+	})
+}
+
+// migrate moves the schema from its current version to the one chosen by
+// target. It holds an advisory lock for the whole run, so replicas starting
+// at once wait for each other instead of applying the same migration twice.
+// Every migration runs in its own transaction together with the version
+// update.
+func (s *PostgresStorage) migrate(ctx context.Context, target func(current uint, all []*Migration) (uint, error)) (int, error) {
+	all, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	// Session advisory locks belong to a connection, everything has to run
+	// on the same one.
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get db connection: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1), 0)", migrationsTable); err != nil {
+		return 0, fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1), 0)", migrationsTable)
+	}()
+
+	if _, err := conn.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS "+migrationsTable+" (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)",
+	); err != nil {
+		return 0, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	current, dirty, err := schemaVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	if dirty {
+		return 0, fmt.Errorf("%w: version %d", ErrSchemaDirty, current)
+	}
+
+	to, err := target(current, all)
+	if err != nil {
+		return 0, err
+	}
+
+	if to < current && !slices.ContainsFunc(all, func(m *Migration) bool { return m.Version == current }) {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownMigration, current)
+	}
+
+	applied := 0
+
+	for _, m := range all {
+		if m.Version <= current || m.Version > to {
+			continue
+		}
+
+		if err := applyMigration(ctx, conn, m.up, m.Version); err != nil {
+			return applied, fmt.Errorf("failed to apply migration %d_%s: %w", m.Version, m.Name, err)
+		}
+
+		log(ctx).Info("applied migration", slog.Uint64("version", uint64(m.Version)), slog.String("name", m.Name))
+		applied++
+	}
+
+	for i := len(all) - 1; i >= 0; i-- {
+		m := all[i]
+		if m.Version > current || m.Version <= to {
+			continue
+		}
+
+		if m.down == "" {
+			return applied, fmt.Errorf("migration %d_%s has no down script", m.Version, m.Name)
+		}
+
+		var prev uint
+		if i > 0 {
+			prev = all[i-1].Version
+		}
+
+		if err := applyMigration(ctx, conn, m.down, prev); err != nil {
+			return applied, fmt.Errorf("failed to roll back migration %d_%s: %w", m.Version, m.Name, err)
+		}
+
+		log(ctx).Info("rolled back migration", slog.Uint64("version", uint64(m.Version)), slog.String("name", m.Name))
+		applied++
+	}
+
+	return applied, nil
+}
+
+// applyMigration runs the script and records the resulting schema version in
+// one transaction.
+func applyMigration(ctx context.Context, conn *sql.Conn, script string, version uint) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+migrationsTable); err != nil {
+		return fmt.Errorf("failed to clear schema version: %w", err)
+	}
+
+	if version > 0 {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO "+migrationsTable+" (version, dirty) VALUES ($1, false)",
+			version,
+		); err != nil {
+			return fmt.Errorf("failed to set schema version: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func schemaVersion(ctx context.Context, db queryRower) (uint, bool, error) {
+	var version uint
+	var dirty bool
+
+	err := db.QueryRowContext(ctx, "SELECT version, dirty FROM "+migrationsTable+" LIMIT 1").Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+
+		return 0, false, fmt.Errorf("failed to get schema version: %w", err)
+	}
+
+	return version, dirty, nil
+}
@@ -7,24 +7,43 @@ import (
 	"fmt"
 	"log/slog"
 	"net/url"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
 	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/tracing/sqltrace"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/lib/pq"
 )
 
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+	undefinedTable      = "42P01"
+)
+
+const (
+	defaultSearchConfig = "simple"
+	headlineOptions     = "MaxFragments=2, MaxWords=20, MinWords=5, StartSel=<mark>, StopSel=</mark>"
+)
+
 type PostgresStorage struct {
-	db *sql.DB
+	db           *sql.DB
+	dsn          url.URL
+	searchConfig string
 }
 
 func New(path url.URL) (*PostgresStorage, error) {
-	db, err := sql.Open("postgres", path.String())
+	connector, err := pq.NewConnector(path.String())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open db connection: %w", err)
 	}
 
+	db := sql.OpenDB(sqltrace.WrapConnector(connector, "postgresql"))
+
 	db.SetConnMaxLifetime(10 * time.Second)
 	db.SetMaxOpenConns(3)
 	db.SetMaxIdleConns(3)
@@ -34,110 +53,441 @@ func New(path url.URL) (*PostgresStorage, error) {
 	}
 
 	return &PostgresStorage{
-		db: db,
+		db:           db,
+		dsn:          path,
+		searchConfig: defaultSearchConfig,
 	}, nil
 }
 
-func (s *PostgresStorage) GetBookmarks(ctx context.Context, limit, offset int, search string) ([]*model.Bookmark, int, error) {
-	var rows *sql.Rows
+// reindexBookmarks recomputes search_vector the way the
+// bookmarks_search_vector trigger of migration 00008 does.
+const reindexBookmarks = `UPDATE bookmarks SET search_vector =
+	setweight(to_tsvector($1::regconfig, COALESCE(title, '')), 'A') ||
+	setweight(to_tsvector($1::regconfig, COALESCE(description, '')), 'B') ||
+	setweight(to_tsvector($1::regconfig, COALESCE(url, '')), 'C')`
+
+// ConfigureSearch sets the text search configuration used to index and query
+// bookmarks. Changing it reindexes all bookmarks.
+func (s *PostgresStorage) ConfigureSearch(ctx context.Context, config string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE search_settings SET config = $1::regconfig WHERE config <> $1::regconfig",
+		config,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set search config: %w", err)
+	}
+
+	changed, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check search config update: %w", err)
+	}
+
+	if changed > 0 {
+		log(ctx).Info("search config changed, reindexing bookmarks", slog.String("config", config))
+
+		// search_vector is assigned directly: touching title would fire the
+		// change_seq trigger and hand every bookmark to sync clients again.
+		if _, err := tx.ExecContext(ctx, reindexBookmarks, config); err != nil {
+			return fmt.Errorf("failed to reindex bookmarks: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.searchConfig = config
+
+	return nil
+}
+
+var bookmarkColumns = []string{
+	"id", "url", "title", "description", "image_url", "favicon_url",
+	"folder_id", "metadata_fetched_at",
+	"COALESCE(link_status, '')", "http_status", "final_url", "last_checked_at", "consecutive_failures",
+	"created_at", "updated_at", "deleted_at", "change_seq",
+}
+
+var bookmarkReturning = "RETURNING " + strings.Join(bookmarkColumns, ", ")
+
+// bookmarkFields returns scan destinations matching bookmarkColumns.
+func bookmarkFields(bm *model.Bookmark) []any {
+	return []any{
+		&bm.ID, &bm.URL, &bm.Title, &bm.Description, &bm.ImageURL, &bm.FaviconURL,
+		&bm.FolderID, &bm.MetadataFetchedAt,
+		&bm.LinkStatus, &bm.HTTPStatus, &bm.FinalURL, &bm.LastCheckedAt, &bm.ConsecutiveFailures,
+		&bm.CreatedAt, &bm.UpdatedAt, &bm.DeletedAt, &bm.Version,
+	}
+}
+
+const bookmarkTagsColumn = `COALESCE((
+	SELECT array_agg(t.name ORDER BY t.name)
+	FROM bookmark_tags bt
+	JOIN tags t ON t.id = bt.tag_id
+	WHERE bt.bookmark_id = bookmarks.id
+), '{}') AS tags`
+
+func (s *PostgresStorage) GetBookmarks(ctx context.Context, filter BookmarkFilter) (*BookmarkPage, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	where := sq.And{sq.Eq{"user_id": userID, "deleted_at": nil}}
+
+	if filter.FolderID != nil {
+		where = append(where, sq.Eq{"folder_id": *filter.FolderID})
+	}
 
+	if filter.LinkStatus != "" {
+		where = append(where, sq.Eq{"link_status": filter.LinkStatus})
+	}
+
+	var query sq.Sqlizer
+	if filter.Search != "" {
+		query = sq.Expr("websearch_to_tsquery(?::regconfig, ?)", s.searchConfig, filter.Search)
+
+		// Full-text matches are ranked, trigram similarity keeps typos and
+		// URL substrings findable.
+		where = append(where, sq.Or{
+			sq.Expr("search_vector @@ ?", query),
+			sq.ILike{"url": "%" + filter.Search + "%"},
+			sq.Expr("similarity(title, ?) > ?", filter.Search, 0.2),
+		})
+	}
+
+	if len(filter.Tags) > 0 {
+		if filter.AnyTag {
+			where = append(where, sq.Expr(`EXISTS (
+				SELECT 1 FROM bookmark_tags bt
+				JOIN tags t ON t.id = bt.tag_id
+				WHERE bt.bookmark_id = bookmarks.id AND t.name = ANY(?)
+			)`, pq.Array(filter.Tags)))
+		} else {
+			where = append(where, sq.Expr(`id IN (
+				SELECT bt.bookmark_id FROM bookmark_tags bt
+				JOIN tags t ON t.id = bt.tag_id
+				WHERE t.name = ANY(?)
+				GROUP BY bt.bookmark_id
+				HAVING COUNT(DISTINCT t.id) = ?
+			)`, pq.Array(filter.Tags), len(filter.Tags)))
+		}
+	}
+
+	var total *int
+
+	if !filter.SkipCount {
+		var count int
+
+		err := sq.
+			Select("COUNT(*)").
+			From("bookmarks").
+			Where(where).
+			PlaceholderFormat(sq.Dollar).
+			RunWith(s.db).
+			QueryRowContext(ctx).
+			Scan(&count)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count bookmarks: %w", err)
+		}
+
+		total = &count
+	}
+
+	// One extra row tells whether there is a further page.
 	stmt := sq.
-		Select("*", "COUNT(*) OVER() AS total_count").
+		Select(append(bookmarkColumns, bookmarkTagsColumn)...).
 		From("bookmarks").
-		OrderBy("created_at DESC").
-		Limit(uint64(limit)).
-		Offset(uint64(offset)).
+		Where(where).
+		Limit(uint64(filter.Limit) + 1).
 		PlaceholderFormat(sq.Dollar).
 		RunWith(s.db)
 
-	if search != "" {
-		stmt = stmt.Where(
-			sq.Or{
-				sq.ILike{"url": "%" + search + "%"},
-				sq.ILike{"title": "%" + search + "%"},
-				sq.Expr("similarity(title, ?) > ?", search, 0.2),
-			})
+	cursor := filter.Cursor
+
+	switch {
+	case query != nil:
+		stmt = stmt.
+			Column(sq.Alias(sq.Expr("ts_rank(search_vector, ?)", query), "score")).
+			Column(sq.Alias(sq.Expr("ts_headline(?::regconfig, title || ' ' || description, ?, ?)",
+				s.searchConfig, query, headlineOptions), "snippet")).
+			OrderBy("score DESC", "created_at DESC", "id DESC").
+			Offset(uint64(filter.Offset))
+	case cursor != nil && cursor.Backward:
+		stmt = stmt.
+			Where(sq.Expr("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID)).
+			OrderBy("created_at ASC", "id ASC")
+	case cursor != nil:
+		stmt = stmt.
+			Where(sq.Expr("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)).
+			OrderBy("created_at DESC", "id DESC")
+	default:
+		stmt = stmt.
+			OrderBy("created_at DESC", "id DESC").
+			Offset(uint64(filter.Offset))
 	}
 
 	rows, err := stmt.QueryContext(ctx)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get bookmarks rows: %w", err)
+		return nil, fmt.Errorf("failed to get bookmarks rows: %w", err)
 	}
 	defer func() {
 		_ = rows.Close()
 	}()
 
-	var totalCount int
 	bookmarks := []*model.Bookmark{}
 	for rows.Next() {
 		var bm model.Bookmark
 
-		if err := rows.Scan(&bm.ID, &bm.URL, &bm.Title, &bm.CreatedAt, &bm.UpdatedAt, &totalCount); err != nil {
-			return nil, 0, fmt.Errorf("failed to scan bookmark: %w", err)
+		fields := append(bookmarkFields(&bm), pq.Array(&bm.Tags))
+		if query != nil {
+			fields = append(fields, &bm.Score, &bm.Snippet)
+		}
+
+		if err := rows.Scan(fields...); err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark: %w", err)
 		}
 
 		bookmarks = append(bookmarks, &bm)
 	}
 
-	return bookmarks, totalCount, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate bookmarks rows: %w", err)
+	}
+
+	return NewBookmarkPage(filter, bookmarks, total), nil
+}
+
+func (s *PostgresStorage) GetBookmark(ctx context.Context, id int) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select(append(bookmarkColumns, bookmarkTagsColumn)...).
+		From("bookmarks").
+		Where(sq.Eq{"id": id, "user_id": userID, "deleted_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	var bm model.Bookmark
+	if err := stmt.QueryRowContext(ctx).Scan(append(bookmarkFields(&bm), pq.Array(&bm.Tags))...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get bookmark: %w", err)
+	}
+
+	return &bm, nil
+}
+
+func (s *PostgresStorage) CreateBookmark(ctx context.Context, title, url string, tags []string, folderID *int) (*model.Bookmark, error) {
+	return s.createBookmark(ctx, map[string]any{
+		"title":     title,
+		"url":       url,
+		"folder_id": folderID,
+	}, tags)
+}
+
+// ImportBookmark creates a bookmark keeping its original timestamps.
+func (s *PostgresStorage) ImportBookmark(ctx context.Context, bookmark *model.Bookmark) (*model.Bookmark, error) {
+	return s.createBookmark(ctx, map[string]any{
+		"title":      bookmark.Title,
+		"url":        bookmark.URL,
+		"folder_id":  bookmark.FolderID,
+		"created_at": bookmark.CreatedAt,
+		"updated_at": bookmark.UpdatedAt,
+	}, bookmark.Tags)
 }
 
-func (s *PostgresStorage) CreateBookmark(ctx context.Context, title, url string) (*model.Bookmark, error) {
-	const uniqueViolation = "23505"
+func (s *PostgresStorage) createBookmark(ctx context.Context, values map[string]any, tags []string) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	values["user_id"] = userID
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if folderID, ok := values["folder_id"].(*int); ok {
+		if err := checkFolderOwner(ctx, tx, userID, folderID); err != nil {
+			return nil, err
+		}
+	}
 
 	stmt := sq.
 		Insert("bookmarks").
-		Columns("title", "url").
-		Values(title, url).
-		Suffix("RETURNING id, url, title, created_at, updated_at").
+		SetMap(values).
+		Suffix(bookmarkReturning).
 		PlaceholderFormat(sq.Dollar).
-		RunWith(s.db)
+		RunWith(tx)
 
 	row := stmt.QueryRowContext(ctx)
 
 	var bm model.Bookmark
-	if err := row.Scan(&bm.ID, &bm.URL, &bm.Title, &bm.CreatedAt, &bm.UpdatedAt); err != nil {
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+	if err := row.Scan(bookmarkFields(&bm)...); err != nil {
+		if isPqError(err, uniqueViolation) {
 			return nil, ErrExists
 		}
+		if isPqError(err, foreignKeyViolation) {
+			return nil, ErrFolderNotFound
+		}
 
 		return nil, fmt.Errorf("failed to create bookmark: %w", err)
 	}
 
+	if err := setBookmarkTags(ctx, tx, bm.ID, tags); err != nil {
+		return nil, err
+	}
+	bm.Tags = sortedTags(tags)
+
+	if err := recordRevision(ctx, tx, bm.ID, model.RevisionCreated); err != nil {
+		return nil, err
+	}
+
+	if err := recordEvent(ctx, tx, userID, model.EventBookmarkCreated, &bm); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return &bm, nil
 }
 
-func (s *PostgresStorage) EditBookmark(ctx context.Context, id int, title, url string) (*model.Bookmark, error) {
+func (s *PostgresStorage) EditBookmark(ctx context.Context, id int, title, url string, tags []string) (*model.Bookmark, error) {
+	return s.editBookmark(ctx, id, 0, title, url, tags)
+}
+
+// EditBookmarkIfUnchanged edits the bookmark only if it wasn't changed since
+// the given version, failing with ErrConflict otherwise.
+func (s *PostgresStorage) EditBookmarkIfUnchanged(ctx context.Context, id int, version int64, title, url string, tags []string) (*model.Bookmark, error) {
+	return s.editBookmark(ctx, id, version, title, url, tags)
+}
+
+// editBookmark edits the bookmark, checking its version unless it's zero.
+func (s *PostgresStorage) editBookmark(ctx context.Context, id int, version int64, title, url string, tags []string) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
 	stmt := sq.
 		Update("bookmarks").
 		Set("title", title).
 		Set("url", url).
 		Set("updated_at", sq.Expr("NOW()")).
-		Where(sq.Eq{"id": id}).
-		Suffix("RETURNING id, url, title, created_at, updated_at").
+		Where(sq.Eq{"id": id, "user_id": userID, "deleted_at": nil}).
+		Suffix(bookmarkReturning).
 		PlaceholderFormat(sq.Dollar).
-		RunWith(s.db)
+		RunWith(tx)
+
+	if version > 0 {
+		stmt = stmt.Where(sq.LtOrEq{"change_seq": version})
+	}
 
 	row := stmt.QueryRowContext(ctx)
 
 	var bm model.Bookmark
-	if err := row.Scan(&bm.ID, &bm.URL, &bm.Title, &bm.CreatedAt, &bm.UpdatedAt); err != nil {
+	if err := row.Scan(bookmarkFields(&bm)...); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrNotFound
+			return nil, missingOrConflict(ctx, tx, userID, id, version)
+		}
+		if isPqError(err, uniqueViolation) {
+			return nil, ErrExists
 		}
 
 		return nil, fmt.Errorf("failed to edit bookmark: %w", err)
 	}
 
+	if tags != nil {
+		if err := setBookmarkTags(ctx, tx, bm.ID, tags); err != nil {
+			return nil, err
+		}
+	}
+
+	bm.Tags, err = getBookmarkTags(ctx, tx, bm.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordRevision(ctx, tx, bm.ID, model.RevisionEdited); err != nil {
+		return nil, err
+	}
+
+	if err := recordEvent(ctx, tx, userID, model.EventBookmarkUpdated, &bm); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return &bm, nil
 }
 
+// DeleteBookmark moves the bookmark to the trash, it's purged for good by
+// PurgeBookmark or once the trash retention period is over.
 func (s *PostgresStorage) DeleteBookmark(ctx context.Context, id int) error {
+	return s.deleteBookmark(ctx, id, 0)
+}
+
+// DeleteBookmarkIfUnchanged deletes the bookmark only if it wasn't changed
+// since the given version, failing with ErrConflict otherwise.
+func (s *PostgresStorage) DeleteBookmarkIfUnchanged(ctx context.Context, id int, version int64) error {
+	return s.deleteBookmark(ctx, id, version)
+}
+
+// deleteBookmark deletes the bookmark, checking its version unless it's zero.
+func (s *PostgresStorage) deleteBookmark(ctx context.Context, id int, version int64) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
 	stmt := sq.
-		Delete("bookmarks").
-		Where(sq.Eq{"id": id}).
+		Update("bookmarks").
+		Set("deleted_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": id, "user_id": userID, "deleted_at": nil}).
 		PlaceholderFormat(sq.Dollar).
-		RunWith(s.db)
+		RunWith(tx)
+
+	if version > 0 {
+		stmt = stmt.Where(sq.LtOrEq{"change_seq": version})
+	}
 
 	result, err := stmt.ExecContext(ctx)
 	if err != nil {
@@ -150,7 +500,19 @@ func (s *PostgresStorage) DeleteBookmark(ctx context.Context, id int) error {
 	}
 
 	if rowAffected == 0 {
-		return ErrNotFound
+		return missingOrConflict(ctx, tx, userID, id, version)
+	}
+
+	if err := recordRevision(ctx, tx, id, model.RevisionDeleted); err != nil {
+		return err
+	}
+
+	if err := recordEvent(ctx, tx, userID, model.EventBookmarkDeleted, map[string]int{"id": id}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
@@ -160,15 +522,20 @@ func (s *PostgresStorage) BookmarkExist(ctx context.Context, url string) (int, b
 	var id int
 	var found bool
 
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+
 	stmt := sq.
 		Select("id", "true").
 		From("bookmarks").
-		Where(sq.Eq{"url": url}).
+		Where(sq.Eq{"url": url, "user_id": userID, "deleted_at": nil}).
 		Limit(1).
 		PlaceholderFormat(sq.Dollar).
 		RunWith(s.db)
 
-	err := stmt.QueryRowContext(ctx).Scan(&id, &found)
+	err = stmt.QueryRowContext(ctx).Scan(&id, &found)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return 0, false, nil
@@ -180,6 +547,135 @@ func (s *PostgresStorage) BookmarkExist(ctx context.Context, url string) (int, b
 	return id, found, nil
 }
 
+func (s *PostgresStorage) GetTags(ctx context.Context) ([]*model.Tag, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select("t.name", "COUNT(bt.bookmark_id) AS count").
+		From("tags t").
+		Join("bookmark_tags bt ON bt.tag_id = t.id").
+		Join("bookmarks b ON b.id = bt.bookmark_id").
+		Where(sq.Eq{"b.user_id": userID, "b.deleted_at": nil}).
+		GroupBy("t.name").
+		OrderBy("count DESC", "t.name").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	tags := []*model.Tag{}
+	for rows.Next() {
+		var tag model.Tag
+
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+
+		tags = append(tags, &tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tags rows: %w", err)
+	}
+
+	return tags, nil
+}
+
+func getBookmarkTags(ctx context.Context, runner sq.BaseRunner, bookmarkID int) ([]string, error) {
+	var tags []string
+
+	row := sq.
+		Select(bookmarkTagsColumn).
+		From("bookmarks").
+		Where(sq.Eq{"id": bookmarkID}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(runner).
+		QueryRowContext(ctx)
+
+	if err := row.Scan(pq.Array(&tags)); err != nil {
+		return nil, fmt.Errorf("failed to get bookmark tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// checkFolderOwner makes sure the folder, when one is given, belongs to the user.
+func checkFolderOwner(ctx context.Context, runner sq.BaseRunner, userID int, folderID *int) error {
+	if folderID == nil {
+		return nil
+	}
+
+	var found bool
+
+	err := sq.
+		Select("true").
+		From("folders").
+		Where(sq.Eq{"id": *folderID, "user_id": userID}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(runner).
+		QueryRowContext(ctx).
+		Scan(&found)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrFolderNotFound
+		}
+
+		return fmt.Errorf("failed to find folder: %w", err)
+	}
+
+	return nil
+}
+
+// setBookmarkTags replaces the tags of the bookmark with the given ones,
+// creating tags that don't exist yet.
+func setBookmarkTags(ctx context.Context, tx *sql.Tx, bookmarkID int, tags []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM bookmark_tags WHERE bookmark_id = $1", bookmarkID); err != nil {
+		return fmt.Errorf("failed to clear bookmark tags: %w", err)
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO tags (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING",
+		pq.Array(tags),
+	); err != nil {
+		return fmt.Errorf("failed to create tags: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO bookmark_tags (bookmark_id, tag_id) SELECT $1, id FROM tags WHERE name = ANY($2)",
+		bookmarkID, pq.Array(tags),
+	); err != nil {
+		return fmt.Errorf("failed to tag bookmark: %w", err)
+	}
+
+	return nil
+}
+
+func isPqError(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
+
+func sortedTags(tags []string) []string {
+	sorted := append([]string{}, tags...)
+	slices.Sort(sorted)
+
+	return sorted
+}
+
 func (s *PostgresStorage) Ping(ctx context.Context) error {
 	if err := s.db.PingContext(ctx); err != nil {
 		return fmt.Errorf("failed to ping db: %w", err)
@@ -188,12 +684,17 @@ func (s *PostgresStorage) Ping(ctx context.Context) error {
 	return nil
 }
 
+// Stats returns the connection pool statistics.
+func (s *PostgresStorage) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
 func (s *PostgresStorage) Close() error {
-	slog.Info("closing database connection")
+	log(context.Background()).Info("closing database connection")
 
 	if s.db != nil {
 		return s.db.Close()
 	}
-	
+
 	return nil
 }
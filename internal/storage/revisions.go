@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/lib/pq"
+)
+
+// GetBookmarkHistory returns the revisions of the bookmark, newest first,
+// each with its changes from the revision before it.
+func (s *PostgresStorage) GetBookmarkHistory(ctx context.Context, id int) ([]*model.Revision, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select("r.revision", "r.action", "r.title", "r.url", "r.tags", "r.created_at").
+		From("bookmark_revisions r").
+		Join("bookmarks b ON b.id = r.bookmark_id").
+		Where(sq.Eq{"r.bookmark_id": id, "b.user_id": userID}).
+		OrderBy("r.revision").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	revisions := []*model.Revision{}
+	var prev *model.Revision
+	for rows.Next() {
+		var rev model.Revision
+
+		if err := rows.Scan(&rev.Revision, &rev.Action, &rev.Title, &rev.URL, pq.Array(&rev.Tags), &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+
+		rev.Changes = rev.Diff(prev)
+		prev = &rev
+
+		revisions = append(revisions, &rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate revisions rows: %w", err)
+	}
+
+	if len(revisions) == 0 {
+		return nil, ErrNotFound
+	}
+
+	slices.Reverse(revisions)
+
+	return revisions, nil
+}
+
+// RevertBookmark restores the title, url and tags the bookmark had at the
+// given revision, recording it as a new revision.
+func (s *PostgresStorage) RevertBookmark(ctx context.Context, id, revision int) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var rev model.Revision
+
+	err = sq.
+		Select("r.title", "r.url", "r.tags").
+		From("bookmark_revisions r").
+		Join("bookmarks b ON b.id = r.bookmark_id").
+		Where(sq.Eq{"r.bookmark_id": id, "r.revision": revision, "b.user_id": userID, "b.deleted_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx).
+		QueryRowContext(ctx).
+		Scan(&rev.Title, &rev.URL, pq.Array(&rev.Tags))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRevisionNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	stmt := sq.
+		Update("bookmarks").
+		Set("title", rev.Title).
+		Set("url", rev.URL).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": id, "user_id": userID, "deleted_at": nil}).
+		Suffix(bookmarkReturning).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx)
+
+	var bm model.Bookmark
+	if err := stmt.QueryRowContext(ctx).Scan(bookmarkFields(&bm)...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if isPqError(err, uniqueViolation) {
+			return nil, ErrExists
+		}
+
+		return nil, fmt.Errorf("failed to revert bookmark: %w", err)
+	}
+
+	if err := setBookmarkTags(ctx, tx, bm.ID, rev.Tags); err != nil {
+		return nil, err
+	}
+	bm.Tags = sortedTags(rev.Tags)
+
+	if err := recordRevision(ctx, tx, bm.ID, model.RevisionReverted); err != nil {
+		return nil, err
+	}
+
+	if err := recordEvent(ctx, tx, userID, model.EventBookmarkUpdated, &bm); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &bm, nil
+}
+
+// recordRevision snapshots the current state of the bookmark as its next revision.
+func recordRevision(ctx context.Context, tx *sql.Tx, bookmarkID int, action string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO bookmark_revisions (bookmark_id, revision, action, title, url, tags)
+		SELECT id,
+			COALESCE((SELECT MAX(revision) FROM bookmark_revisions WHERE bookmark_id = bookmarks.id), 0) + 1,
+			$2, title, url, `+bookmarkTagsColumn+`
+		FROM bookmarks
+		WHERE id = $1`,
+		bookmarkID, action,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record bookmark revision: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,508 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+// likeEscaper escapes the wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// GetBookmarks lists bookmarks like the PostgreSQL storage does, except that
+// search matches every word of the query as a substring, so results carry no
+// score or snippet.
+func (s *Storage) GetBookmarks(ctx context.Context, filter storage.BookmarkFilter) (*storage.BookmarkPage, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	where := sq.And{sq.Eq{"user_id": userID, "deleted_at": nil}}
+
+	if filter.FolderID != nil {
+		where = append(where, sq.Eq{"folder_id": *filter.FolderID})
+	}
+
+	if filter.LinkStatus != "" {
+		where = append(where, sq.Eq{"link_status": filter.LinkStatus})
+	}
+
+	for _, word := range strings.Fields(filter.Search) {
+		where = append(where, sq.Expr(
+			`(title || ' ' || description || ' ' || url) LIKE ? ESCAPE '\'`,
+			"%"+likeEscaper.Replace(word)+"%",
+		))
+	}
+
+	if len(filter.Tags) > 0 {
+		tags := make([]any, 0, len(filter.Tags))
+		for _, tag := range filter.Tags {
+			tags = append(tags, tag)
+		}
+
+		placeholders := sq.Placeholders(len(tags))
+
+		if filter.AnyTag {
+			where = append(where, sq.Expr(`EXISTS (
+				SELECT 1 FROM bookmark_tags bt
+				WHERE bt.bookmark_id = bookmarks.id AND bt.name IN (`+placeholders+`)
+			)`, tags...))
+		} else {
+			where = append(where, sq.Expr(`id IN (
+				SELECT bookmark_id FROM bookmark_tags
+				WHERE name IN (`+placeholders+`)
+				GROUP BY bookmark_id
+				HAVING COUNT(DISTINCT name) = ?
+			)`, append(tags, len(filter.Tags))...))
+		}
+	}
+
+	var total *int
+
+	if !filter.SkipCount {
+		var count int
+
+		err := sq.
+			Select("COUNT(*)").
+			From("bookmarks").
+			Where(where).
+			RunWith(s.db).
+			QueryRowContext(ctx).
+			Scan(&count)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count bookmarks: %w", err)
+		}
+
+		total = &count
+	}
+
+	// One extra row tells whether there is a further page.
+	stmt := sq.
+		Select(append(bookmarkColumns, bookmarkTagsColumn)...).
+		From("bookmarks").
+		Where(where).
+		Limit(uint64(filter.Limit) + 1).
+		RunWith(s.db)
+
+	cursor := filter.Cursor
+
+	switch {
+	case filter.Search != "" || cursor == nil:
+		stmt = stmt.
+			OrderBy("created_at DESC", "id DESC").
+			Offset(uint64(filter.Offset))
+	case cursor.Backward:
+		stmt = stmt.
+			Where(sq.Expr("(created_at, id) > (?, ?)", cursor.CreatedAt.UTC(), cursor.ID)).
+			OrderBy("created_at ASC", "id ASC")
+	default:
+		stmt = stmt.
+			Where(sq.Expr("(created_at, id) < (?, ?)", cursor.CreatedAt.UTC(), cursor.ID)).
+			OrderBy("created_at DESC", "id DESC")
+	}
+
+	bookmarks, err := queryBookmarks(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.NewBookmarkPage(filter, bookmarks, total), nil
+}
+
+func (s *Storage) GetBookmark(ctx context.Context, id int) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select(append(bookmarkColumns, bookmarkTagsColumn)...).
+		From("bookmarks").
+		Where(sq.Eq{"id": id, "user_id": userID, "deleted_at": nil}).
+		RunWith(s.db)
+
+	var bm model.Bookmark
+	if err := stmt.QueryRowContext(ctx).Scan(bookmarkFields(&bm)...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get bookmark: %w", err)
+	}
+
+	return &bm, nil
+}
+
+func (s *Storage) CreateBookmark(ctx context.Context, title, url string, tags []string, folderID *int) (*model.Bookmark, error) {
+	createdAt := now()
+
+	return s.createBookmark(ctx, &model.Bookmark{
+		Title:     title,
+		URL:       url,
+		Tags:      tags,
+		FolderID:  folderID,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	})
+}
+
+// ImportBookmark creates a bookmark keeping its original timestamps.
+func (s *Storage) ImportBookmark(ctx context.Context, bookmark *model.Bookmark) (*model.Bookmark, error) {
+	return s.createBookmark(ctx, &model.Bookmark{
+		Title:     bookmark.Title,
+		URL:       bookmark.URL,
+		Tags:      bookmark.Tags,
+		FolderID:  bookmark.FolderID,
+		CreatedAt: bookmark.CreatedAt.UTC(),
+		UpdatedAt: bookmark.UpdatedAt.UTC(),
+	})
+}
+
+func (s *Storage) createBookmark(ctx context.Context, bookmark *model.Bookmark) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := checkFolderOwner(ctx, tx, userID, bookmark.FolderID); err != nil {
+		return nil, err
+	}
+
+	result, err := sq.
+		Insert("bookmarks").
+		SetMap(map[string]any{
+			"user_id":    userID,
+			"title":      bookmark.Title,
+			"url":        bookmark.URL,
+			"folder_id":  bookmark.FolderID,
+			"created_at": bookmark.CreatedAt,
+			"updated_at": bookmark.UpdatedAt,
+		}).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, storage.ErrExists
+		}
+
+		return nil, fmt.Errorf("failed to create bookmark: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookmark id: %w", err)
+	}
+
+	if err := setBookmarkTags(ctx, tx, int(id), sortedTags(bookmark.Tags)); err != nil {
+		return nil, err
+	}
+
+	return s.commitBookmark(ctx, tx, userID, int(id), model.RevisionCreated, model.EventBookmarkCreated)
+}
+
+func (s *Storage) EditBookmark(ctx context.Context, id int, title, url string, tags []string) (*model.Bookmark, error) {
+	return s.editBookmark(ctx, id, 0, title, url, tags)
+}
+
+// EditBookmarkIfUnchanged edits the bookmark only if it wasn't changed since
+// the given version, failing with ErrConflict otherwise.
+func (s *Storage) EditBookmarkIfUnchanged(ctx context.Context, id int, version int64, title, url string, tags []string) (*model.Bookmark, error) {
+	return s.editBookmark(ctx, id, version, title, url, tags)
+}
+
+// editBookmark edits the bookmark, checking its version unless it's zero.
+func (s *Storage) editBookmark(ctx context.Context, id int, version int64, title, url string, tags []string) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt := sq.
+		Update("bookmarks").
+		Set("title", title).
+		Set("url", url).
+		Set("updated_at", now()).
+		Where(sq.Eq{"id": id, "user_id": userID, "deleted_at": nil}).
+		RunWith(tx)
+
+	if version > 0 {
+		stmt = stmt.Where(sq.LtOrEq{"change_seq": version})
+	}
+
+	if err := updateOne(ctx, stmt); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, missingOrConflict(ctx, tx, userID, id, version)
+		}
+
+		return nil, err
+	}
+
+	if tags != nil {
+		if err := setBookmarkTags(ctx, tx, id, sortedTags(tags)); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.commitBookmark(ctx, tx, userID, id, model.RevisionEdited, model.EventBookmarkUpdated)
+}
+
+// DeleteBookmark moves the bookmark to the trash, it's purged for good by
+// PurgeBookmark or once the trash retention period is over.
+func (s *Storage) DeleteBookmark(ctx context.Context, id int) error {
+	return s.deleteBookmark(ctx, id, 0)
+}
+
+// DeleteBookmarkIfUnchanged deletes the bookmark only if it wasn't changed
+// since the given version, failing with ErrConflict otherwise.
+func (s *Storage) DeleteBookmarkIfUnchanged(ctx context.Context, id int, version int64) error {
+	return s.deleteBookmark(ctx, id, version)
+}
+
+// deleteBookmark deletes the bookmark, checking its version unless it's zero.
+func (s *Storage) deleteBookmark(ctx context.Context, id int, version int64) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt := sq.
+		Update("bookmarks").
+		Set("deleted_at", now()).
+		Where(sq.Eq{"id": id, "user_id": userID, "deleted_at": nil}).
+		RunWith(tx)
+
+	if version > 0 {
+		stmt = stmt.Where(sq.LtOrEq{"change_seq": version})
+	}
+
+	if err := updateOne(ctx, stmt); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return missingOrConflict(ctx, tx, userID, id, version)
+		}
+
+		return err
+	}
+
+	if err := recordRevision(ctx, tx, id, model.RevisionDeleted); err != nil {
+		return err
+	}
+
+	if err := s.recordEvent(ctx, tx, userID, model.EventBookmarkDeleted, map[string]int{"id": id}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) BookmarkExist(ctx context.Context, url string) (int, bool, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var id int
+
+	err = sq.
+		Select("id").
+		From("bookmarks").
+		Where(sq.Eq{"url": url, "user_id": userID, "deleted_at": nil}).
+		Limit(1).
+		RunWith(s.db).
+		QueryRowContext(ctx).
+		Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+
+		return 0, false, fmt.Errorf("failed to find bookmark: %w", err)
+	}
+
+	return id, true, nil
+}
+
+func (s *Storage) MoveBookmark(ctx context.Context, id int, folderID *int) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := checkFolderOwner(ctx, tx, userID, folderID); err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Update("bookmarks").
+		Set("folder_id", folderID).
+		Set("updated_at", now()).
+		Where(sq.Eq{"id": id, "user_id": userID, "deleted_at": nil}).
+		RunWith(tx)
+
+	if err := updateOne(ctx, stmt); err != nil {
+		return nil, err
+	}
+
+	return s.commitBookmark(ctx, tx, userID, id, "", model.EventBookmarkUpdated)
+}
+
+func (s *Storage) GetTags(ctx context.Context) ([]*model.Tag, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select("bt.name", "COUNT(*) AS count").
+		From("bookmark_tags bt").
+		Join("bookmarks b ON b.id = bt.bookmark_id").
+		Where(sq.Eq{"b.user_id": userID, "b.deleted_at": nil}).
+		GroupBy("bt.name").
+		OrderBy("count DESC", "bt.name").
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	tags := []*model.Tag{}
+	for rows.Next() {
+		var tag model.Tag
+
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+
+		tags = append(tags, &tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tags rows: %w", err)
+	}
+
+	return tags, nil
+}
+
+// commitBookmark finishes a change of the bookmark: it records the revision,
+// unless action is empty, and the event, then commits and returns the
+// bookmark as stored.
+func (s *Storage) commitBookmark(ctx context.Context, tx *sql.Tx, userID, id int, action, event string) (*model.Bookmark, error) {
+	if action != "" {
+		if err := recordRevision(ctx, tx, id, action); err != nil {
+			return nil, err
+		}
+	}
+
+	bm, err := getBookmark(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recordEvent(ctx, tx, userID, event, bm); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return bm, nil
+}
+
+// updateOne runs an update of a single bookmark, failing with ErrNotFound
+// when it matched none.
+func updateOne(ctx context.Context, stmt sq.UpdateBuilder) error {
+	result, err := stmt.ExecContext(ctx)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return storage.ErrExists
+		}
+
+		return fmt.Errorf("failed to update bookmark: %w", err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get check update: %w", err)
+	}
+
+	if rowAffected == 0 {
+		return storage.ErrNotFound
+	}
+
+	return nil
+}
+
+// missingOrConflict tells why a versioned change matched no bookmark. With
+// no version given, the bookmark can only be missing.
+func missingOrConflict(ctx context.Context, runner sq.BaseRunner, userID, id int, version int64) error {
+	if version == 0 {
+		return storage.ErrNotFound
+	}
+
+	var found bool
+
+	err := sq.
+		Select("true").
+		From("bookmarks").
+		Where(sq.Eq{"id": id, "user_id": userID, "deleted_at": nil}).
+		RunWith(runner).
+		QueryRowContext(ctx).
+		Scan(&found)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrNotFound
+		}
+
+		return fmt.Errorf("failed to find bookmark: %w", err)
+	}
+
+	return storage.ErrConflict
+}
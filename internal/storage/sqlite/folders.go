@@ -0,0 +1,230 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+var folderColumns = []string{"id", "name", "parent_id", "created_at", "updated_at"}
+
+func folderFields(f *model.Folder) []any {
+	return []any{&f.ID, &f.Name, &f.ParentID, &f.CreatedAt, &f.UpdatedAt}
+}
+
+func (s *Storage) GetFolders(ctx context.Context) ([]*model.Folder, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select(folderColumns...).
+		From("folders").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("name", "id").
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folders rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	folders := []*model.Folder{}
+	for rows.Next() {
+		var f model.Folder
+
+		if err := rows.Scan(folderFields(&f)...); err != nil {
+			return nil, fmt.Errorf("failed to scan folder: %w", err)
+		}
+
+		folders = append(folders, &f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate folders rows: %w", err)
+	}
+
+	return folders, nil
+}
+
+func (s *Storage) CreateFolder(ctx context.Context, name string, parentID *int) (*model.Folder, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkFolderOwner(ctx, s.db, userID, parentID); err != nil {
+		return nil, err
+	}
+
+	createdAt := now()
+
+	result, err := sq.
+		Insert("folders").
+		Columns("name", "parent_id", "user_id", "created_at", "updated_at").
+		Values(name, parentID, userID, createdAt, createdAt).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folder id: %w", err)
+	}
+
+	return &model.Folder{
+		ID:        int(id),
+		Name:      name,
+		ParentID:  parentID,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}, nil
+}
+
+// ImportFolder returns the folder with the given name under the given parent,
+// creating it if there is none, so repeated imports don't duplicate folders.
+func (s *Storage) ImportFolder(ctx context.Context, name string, parentID *int) (*model.Folder, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select(folderColumns...).
+		From("folders").
+		Where(sq.Eq{"name": name, "parent_id": parentID, "user_id": userID}).
+		OrderBy("id").
+		Limit(1).
+		RunWith(s.db)
+
+	var f model.Folder
+	err = stmt.QueryRowContext(ctx).Scan(folderFields(&f)...)
+	if err == nil {
+		return &f, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to find folder: %w", err)
+	}
+
+	return s.CreateFolder(ctx, name, parentID)
+}
+
+func (s *Storage) EditFolder(ctx context.Context, id int, name string, parentID *int) (*model.Folder, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := checkFolderOwner(ctx, tx, userID, parentID); err != nil {
+		return nil, err
+	}
+
+	if parentID != nil {
+		var cycle bool
+
+		err := tx.QueryRowContext(ctx, `
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM folders WHERE id = ?
+				UNION ALL
+				SELECT f.id FROM folders f JOIN subtree s ON f.parent_id = s.id
+			)
+			SELECT EXISTS (SELECT 1 FROM subtree WHERE id = ?)`,
+			id, *parentID,
+		).Scan(&cycle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check folder subtree: %w", err)
+		}
+
+		if cycle {
+			return nil, storage.ErrFolderCycle
+		}
+	}
+
+	result, err := sq.
+		Update("folders").
+		Set("name", name).
+		Set("parent_id", parentID).
+		Set("updated_at", now()).
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to edit folder: %w", err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check update: %w", err)
+	}
+
+	if rowAffected == 0 {
+		return nil, storage.ErrFolderNotFound
+	}
+
+	var f model.Folder
+	err = sq.
+		Select(folderColumns...).
+		From("folders").
+		Where(sq.Eq{"id": id}).
+		RunWith(tx).
+		QueryRowContext(ctx).
+		Scan(folderFields(&f)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folder: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &f, nil
+}
+
+// DeleteFolder removes the folder together with its subfolders. Bookmarks
+// stored in them are moved to the root.
+func (s *Storage) DeleteFolder(ctx context.Context, id int) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := sq.
+		Delete("folders").
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete folder: %w", err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get check deletion: %w", err)
+	}
+
+	if rowAffected == 0 {
+		return storage.ErrFolderNotFound
+	}
+
+	return nil
+}
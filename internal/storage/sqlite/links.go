@@ -0,0 +1,196 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+// SetBookmarkMetadata stores the fetched page metadata of the bookmark. The
+// title is only replaced while it's still the URL placeholder, so titles
+// chosen by the user are kept.
+//
+// Changes the owner can see are recorded like edits: a new title adds a
+// revision, and any change sends a bookmark.updated event.
+func (s *Storage) SetBookmarkMetadata(ctx context.Context, id int, metadata model.Metadata) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	before, err := getBookmark(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	var userID int
+	if err := tx.QueryRowContext(ctx, "SELECT user_id FROM bookmarks WHERE id = ?", id).Scan(&userID); err != nil {
+		return fmt.Errorf("failed to get bookmark owner: %w", err)
+	}
+
+	title := before.Title
+	if title == before.URL && metadata.Title != "" {
+		title = metadata.Title
+	}
+
+	stmt := sq.
+		Update("bookmarks").
+		Set("title", title).
+		Set("description", metadata.Description).
+		Set("image_url", metadata.ImageURL).
+		Set("favicon_url", metadata.FaviconURL).
+		Set("metadata_fetched_at", now()).
+		Where(sq.Eq{"id": id}).
+		RunWith(tx)
+
+	if err := updateOne(ctx, stmt); err != nil {
+		return err
+	}
+
+	// Bookmarks in the trash are updated silently, they're restored as they
+	// are now.
+	if before.DeletedAt == nil {
+		if title != before.Title {
+			if err := recordRevision(ctx, tx, id, model.RevisionEdited); err != nil {
+				return err
+			}
+		}
+
+		after, err := getBookmark(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if metadataChanged(before, after) {
+			if err := s.recordEvent(ctx, tx, userID, model.EventBookmarkUpdated, after); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// metadataChanged tells whether a metadata update changed a field the
+// bookmark owner can see.
+func metadataChanged(before, after *model.Bookmark) bool {
+	return before.Title != after.Title ||
+		before.Description != after.Description ||
+		before.ImageURL != after.ImageURL ||
+		before.FaviconURL != after.FaviconURL
+}
+
+// GetBookmarksToCheck returns bookmarks of all users that were never checked
+// or were last checked before the given time, least recently checked first.
+func (s *Storage) GetBookmarksToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*model.Bookmark, error) {
+	stmt := sq.
+		Select("id", "url").
+		From("bookmarks").
+		Where(sq.Eq{"deleted_at": nil}).
+		Where(sq.Or{
+			sq.Eq{"last_checked_at": nil},
+			sq.Lt{"last_checked_at": checkedBefore.UTC()},
+		}).
+		OrderBy("last_checked_at NULLS FIRST", "id").
+		Limit(uint64(limit)).
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookmarks to check: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	bookmarks := []*model.Bookmark{}
+	for rows.Next() {
+		var bm model.Bookmark
+
+		if err := rows.Scan(&bm.ID, &bm.URL); err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark: %w", err)
+		}
+
+		bookmarks = append(bookmarks, &bm)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate bookmarks rows: %w", err)
+	}
+
+	return bookmarks, nil
+}
+
+// SetLinkCheck records the result of a link check. Consecutive failures are
+// counted up for broken links and reset otherwise.
+func (s *Storage) SetLinkCheck(ctx context.Context, id int, check model.LinkCheck) error {
+	failures := sq.Expr("0")
+	if check.Status == model.LinkStatusBroken {
+		failures = sq.Expr("consecutive_failures + 1")
+	}
+
+	stmt := sq.
+		Update("bookmarks").
+		Set("link_status", check.Status).
+		Set("http_status", check.HTTPStatus).
+		Set("final_url", check.FinalURL).
+		Set("last_checked_at", check.CheckedAt.UTC()).
+		Set("consecutive_failures", failures).
+		Where(sq.Eq{"id": id}).
+		RunWith(s.db)
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("failed to set link check: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetLinkReport(ctx context.Context) (*model.LinkReport, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select().
+		Column(sq.Expr("COUNT(*) FILTER (WHERE link_status = ?)", model.LinkStatusOK)).
+		Column(sq.Expr("COUNT(*) FILTER (WHERE link_status = ?)", model.LinkStatusRedirected)).
+		Column(sq.Expr("COUNT(*) FILTER (WHERE link_status = ?)", model.LinkStatusBroken)).
+		Column("COUNT(*) FILTER (WHERE link_status IS NULL)").
+		Column("MAX(last_checked_at)").
+		From("bookmarks").
+		Where(sq.Eq{"user_id": userID, "deleted_at": nil}).
+		RunWith(s.db)
+
+	var report model.LinkReport
+	var lastCheckedAt *string
+
+	err = stmt.QueryRowContext(ctx).Scan(&report.OK, &report.Redirected, &report.Broken, &report.Unchecked, &lastCheckedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link report: %w", err)
+	}
+
+	// MAX loses the column type, the time comes back as text.
+	if lastCheckedAt != nil {
+		t, err := time.Parse(timeFormat, *lastCheckedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse last check time: %w", err)
+		}
+
+		report.LastCheckedAt = &t
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,175 @@
+-- The SQLite schema mirrors the PostgreSQL migrations. It's applied on every
+-- start and has to stay idempotent.
+
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY,
+    username TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    last_used_at DATETIME,
+    revoked_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id
+ON api_tokens (user_id);
+
+CREATE TABLE IF NOT EXISTS folders (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    parent_id INTEGER REFERENCES folders (id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_folders_user_id
+ON folders (user_id);
+
+CREATE INDEX IF NOT EXISTS idx_folders_parent_id
+ON folders (parent_id);
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    favicon_url TEXT NOT NULL DEFAULT '',
+    folder_id INTEGER REFERENCES folders (id) ON DELETE SET NULL,
+    metadata_fetched_at DATETIME,
+    link_status TEXT,
+    http_status INTEGER,
+    final_url TEXT NOT NULL DEFAULT '',
+    last_checked_at DATETIME,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    deleted_at DATETIME,
+    change_seq INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS bookmarks_user_id_url_key
+ON bookmarks (user_id, url) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user_created
+ON bookmarks (user_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user_change_seq
+ON bookmarks (user_id, change_seq);
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_folder_id
+ON bookmarks (folder_id);
+
+CREATE TABLE IF NOT EXISTS bookmark_tags (
+    bookmark_id INTEGER NOT NULL REFERENCES bookmarks (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    PRIMARY KEY (bookmark_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookmark_tags_name
+ON bookmark_tags (name);
+
+CREATE TABLE IF NOT EXISTS bookmark_revisions (
+    bookmark_id INTEGER NOT NULL REFERENCES bookmarks (id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    tags TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (bookmark_id, revision)
+);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    dispatched_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_user_id
+ON outbox_events (user_id, id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id INTEGER NOT NULL REFERENCES outbox_events (id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_attempt_at DATETIME,
+    response_status INTEGER,
+    error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id
+ON webhook_deliveries (webhook_id, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending
+ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+-- Bookmark changes are numbered from a single counter, writes are serialized
+-- by SQLite so the numbers follow commit order.
+CREATE TABLE IF NOT EXISTS sequences (
+    name TEXT PRIMARY KEY,
+    value INTEGER NOT NULL
+);
+
+INSERT OR IGNORE INTO sequences (name, value) VALUES ('bookmark_change_seq', 0);
+
+CREATE TRIGGER IF NOT EXISTS trg_bookmarks_change_seq_insert
+AFTER INSERT ON bookmarks
+BEGIN
+    UPDATE sequences SET value = value + 1 WHERE name = 'bookmark_change_seq';
+    UPDATE bookmarks
+    SET change_seq = (SELECT value FROM sequences WHERE name = 'bookmark_change_seq')
+    WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_bookmarks_change_seq_update
+AFTER UPDATE OF title, url, description, image_url, favicon_url, folder_id, updated_at, deleted_at
+ON bookmarks
+BEGIN
+    UPDATE sequences SET value = value + 1 WHERE name = 'bookmark_change_seq';
+    UPDATE bookmarks
+    SET change_seq = (SELECT value FROM sequences WHERE name = 'bookmark_change_seq')
+    WHERE id = NEW.id;
+END;
+
+-- Purged bookmarks leave no tombstone. Clients that synced before the purge
+-- are told to start over.
+CREATE TABLE IF NOT EXISTS sync_resets (
+    user_id INTEGER PRIMARY KEY,
+    change_seq INTEGER NOT NULL
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_bookmarks_sync_reset
+AFTER DELETE ON bookmarks
+BEGIN
+    INSERT INTO sync_resets (user_id, change_seq)
+    VALUES (OLD.user_id, OLD.change_seq)
+    ON CONFLICT (user_id) DO UPDATE
+    SET change_seq = MAX(sync_resets.change_seq, excluded.change_seq);
+END;
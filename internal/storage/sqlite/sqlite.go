@@ -0,0 +1,330 @@
+// Package sqlite stores everything in a single SQLite file, for running the
+// API without a database server. The driver is written in pure Go, the
+// backend works in binaries built without cgo.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+	_ "modernc.org/sqlite"
+)
+
+//go:embed schema.sql
+var schema string
+
+var _ storage.Storage = (*Storage)(nil)
+
+type Storage struct {
+	db *sql.DB
+
+	mu             sync.Mutex
+	listeners      map[int]func(userID int)
+	lastListenerID int
+}
+
+func New(path string) (*Storage, error) {
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open db: %w", err)
+	}
+
+	// SQLite serializes writes anyway, a single connection saves transactions
+	// from failing on a busy database.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return &Storage{
+		db:        db,
+		listeners: make(map[int]func(int)),
+	}, nil
+}
+
+func (s *Storage) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping db: %w", err)
+	}
+
+	return nil
+}
+
+// Stats returns the connection pool statistics.
+func (s *Storage) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
+func (s *Storage) Close() error {
+	log(context.Background()).Info("closing database connection")
+
+	return s.db.Close()
+}
+
+// ListenEvents calls notify for every event recorded by this process until
+// ctx is cancelled. The database file isn't meant to be shared between
+// instances.
+func (s *Storage) ListenEvents(ctx context.Context, notify func(userID int)) error {
+	s.mu.Lock()
+	s.lastListenerID++
+	id := s.lastListenerID
+	s.listeners[id] = notify
+	s.mu.Unlock()
+
+	<-ctx.Done()
+
+	s.mu.Lock()
+	delete(s.listeners, id)
+	s.mu.Unlock()
+
+	return nil
+}
+
+var bookmarkColumns = []string{
+	"id", "url", "title", "description", "image_url", "favicon_url",
+	"folder_id", "metadata_fetched_at",
+	"COALESCE(link_status, '')", "http_status", "final_url", "last_checked_at", "consecutive_failures",
+	"created_at", "updated_at", "deleted_at", "change_seq",
+}
+
+// bookmarkFields returns scan destinations matching bookmarkColumns and
+// bookmarkTagsColumn.
+func bookmarkFields(bm *model.Bookmark) []any {
+	return []any{
+		&bm.ID, &bm.URL, &bm.Title, &bm.Description, &bm.ImageURL, &bm.FaviconURL,
+		&bm.FolderID, &bm.MetadataFetchedAt,
+		&bm.LinkStatus, &bm.HTTPStatus, &bm.FinalURL, &bm.LastCheckedAt, &bm.ConsecutiveFailures,
+		&bm.CreatedAt, &bm.UpdatedAt, &bm.DeletedAt, &bm.Version,
+		(*stringList)(&bm.Tags),
+	}
+}
+
+const bookmarkTagsColumn = `(
+	SELECT json_group_array(name) FROM (
+		SELECT name FROM bookmark_tags WHERE bookmark_id = bookmarks.id ORDER BY name
+	)
+) AS tags`
+
+// getBookmark reads a single bookmark, whatever its owner or state.
+func getBookmark(ctx context.Context, runner sq.BaseRunner, id int) (*model.Bookmark, error) {
+	var bm model.Bookmark
+
+	err := sq.
+		Select(append(bookmarkColumns, bookmarkTagsColumn)...).
+		From("bookmarks").
+		Where(sq.Eq{"id": id}).
+		RunWith(runner).
+		QueryRowContext(ctx).
+		Scan(bookmarkFields(&bm)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get bookmark: %w", err)
+	}
+
+	return &bm, nil
+}
+
+// queryBookmarks runs a select of bookmarkColumns and bookmarkTagsColumn.
+func queryBookmarks(ctx context.Context, stmt sq.SelectBuilder) ([]*model.Bookmark, error) {
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookmarks rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	bookmarks := []*model.Bookmark{}
+	for rows.Next() {
+		var bm model.Bookmark
+
+		if err := rows.Scan(bookmarkFields(&bm)...); err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark: %w", err)
+		}
+
+		bookmarks = append(bookmarks, &bm)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate bookmarks rows: %w", err)
+	}
+
+	return bookmarks, nil
+}
+
+// recordRevision snapshots the current state of the bookmark as its next revision.
+func recordRevision(ctx context.Context, tx *sql.Tx, bookmarkID int, action string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO bookmark_revisions (bookmark_id, revision, action, title, url, tags, created_at)
+		SELECT id,
+			COALESCE((SELECT MAX(revision) FROM bookmark_revisions WHERE bookmark_id = bookmarks.id), 0) + 1,
+			?, title, url, `+bookmarkTagsColumn+`, ?
+		FROM bookmarks
+		WHERE id = ?`,
+		action, now(), bookmarkID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record bookmark revision: %w", err)
+	}
+
+	return nil
+}
+
+// recordEvent writes a bookmark lifecycle event to the outbox and wakes up
+// the event listeners. It has to run in the transaction of the change it
+// describes, listeners can't read the event before it's committed as there
+// is a single connection.
+func (s *Storage) recordEvent(ctx context.Context, tx *sql.Tx, userID int, event string, data any) error {
+	payload, err := json.Marshal(model.Event{
+		Event:      event,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO outbox_events (user_id, event, payload, created_at) VALUES (?, ?, ?, ?)",
+		userID, event, string(payload), now(),
+	); err != nil {
+		return fmt.Errorf("failed to record event: %w", err)
+	}
+
+	s.mu.Lock()
+	for _, notify := range s.listeners {
+		notify(userID)
+	}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// setBookmarkTags replaces the tags of the bookmark with the given ones.
+func setBookmarkTags(ctx context.Context, tx *sql.Tx, bookmarkID int, tags []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM bookmark_tags WHERE bookmark_id = ?", bookmarkID); err != nil {
+		return fmt.Errorf("failed to clear bookmark tags: %w", err)
+	}
+
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO bookmark_tags (bookmark_id, name) VALUES (?, ?)",
+			bookmarkID, tag,
+		); err != nil {
+			return fmt.Errorf("failed to tag bookmark: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkFolderOwner makes sure the folder, when one is given, belongs to the user.
+func checkFolderOwner(ctx context.Context, runner sq.BaseRunner, userID int, folderID *int) error {
+	if folderID == nil {
+		return nil
+	}
+
+	var found bool
+
+	err := sq.
+		Select("true").
+		From("folders").
+		Where(sq.Eq{"id": *folderID, "user_id": userID}).
+		RunWith(runner).
+		QueryRowContext(ctx).
+		Scan(&found)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrFolderNotFound
+		}
+
+		return fmt.Errorf("failed to find folder: %w", err)
+	}
+
+	return nil
+}
+
+// stringList stores a list of strings as a JSON array.
+type stringList []string
+
+func (l *stringList) Scan(src any) error {
+	var data []byte
+
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unsupported string list type %T", src)
+	}
+
+	list := []string{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("failed to decode string list: %w", err)
+	}
+
+	*l = list
+
+	return nil
+}
+
+func (l stringList) Value() (driver.Value, error) {
+	if l == nil {
+		l = stringList{}
+	}
+
+	data, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode string list: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// timeFormat is the layout times are stored in, see the _time_format option
+// of the driver.
+const timeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+// now returns the current time in UTC. Times are stored as text, keeping them
+// all in UTC makes them compare correctly.
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+func isUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+func sortedTags(tags []string) []string {
+	sorted := append([]string{}, tags...)
+	slices.Sort(sorted)
+
+	return slices.Compact(sorted)
+}
+
+// log returns the logger carried by ctx for the storage package.
+func log(ctx context.Context) *slog.Logger {
+	return logger.For(ctx, "storage")
+}
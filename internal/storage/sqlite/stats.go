@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+// GetStats counts users and bookmarks of all users.
+func (s *Storage) GetStats(ctx context.Context) (*model.Stats, error) {
+	var stats model.Stats
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			COUNT(*) FILTER (WHERE deleted_at IS NULL),
+			COUNT(*) FILTER (WHERE deleted_at IS NOT NULL),
+			COUNT(*) FILTER (WHERE deleted_at IS NULL AND link_status = ?)
+		FROM bookmarks`,
+		model.LinkStatusBroken,
+	).Scan(&stats.Users, &stats.Bookmarks, &stats.Trashed, &stats.BrokenLinks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	return &stats, nil
+}
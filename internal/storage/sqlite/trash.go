@@ -0,0 +1,218 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+// GetTrash returns the deleted bookmarks of the user, most recently deleted first.
+func (s *Storage) GetTrash(ctx context.Context) ([]*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select(append(bookmarkColumns, bookmarkTagsColumn)...).
+		From("bookmarks").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.NotEq{"deleted_at": nil}).
+		OrderBy("deleted_at DESC", "id DESC").
+		RunWith(s.db)
+
+	return queryBookmarks(ctx, stmt)
+}
+
+// RestoreBookmark takes the bookmark out of the trash. It fails with
+// ErrExists when the URL was saved again in the meantime.
+func (s *Storage) RestoreBookmark(ctx context.Context, id int) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt := sq.
+		Update("bookmarks").
+		Set("deleted_at", nil).
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		Where(sq.NotEq{"deleted_at": nil}).
+		RunWith(tx)
+
+	if err := updateOne(ctx, stmt); err != nil {
+		return nil, err
+	}
+
+	return s.commitBookmark(ctx, tx, userID, id, model.RevisionRestored, model.EventBookmarkUpdated)
+}
+
+// PurgeBookmark permanently deletes a bookmark from the trash.
+func (s *Storage) PurgeBookmark(ctx context.Context, id int) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := sq.
+		Delete("bookmarks").
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		Where(sq.NotEq{"deleted_at": nil}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to purge bookmark: %w", err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get check purge: %w", err)
+	}
+
+	if rowAffected == 0 {
+		return storage.ErrNotFound
+	}
+
+	return nil
+}
+
+// PurgeTrash permanently deletes bookmarks of all users that were moved to
+// the trash before the given time and returns how many were deleted.
+func (s *Storage) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := sq.
+		Delete("bookmarks").
+		Where(sq.Lt{"deleted_at": deletedBefore.UTC()}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get check purge: %w", err)
+	}
+
+	return purged, nil
+}
+
+// GetBookmarkHistory returns the revisions of the bookmark, newest first,
+// each with its changes from the revision before it.
+func (s *Storage) GetBookmarkHistory(ctx context.Context, id int) ([]*model.Revision, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select("r.revision", "r.action", "r.title", "r.url", "r.tags", "r.created_at").
+		From("bookmark_revisions r").
+		Join("bookmarks b ON b.id = r.bookmark_id").
+		Where(sq.Eq{"r.bookmark_id": id, "b.user_id": userID}).
+		OrderBy("r.revision").
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	revisions := []*model.Revision{}
+	var prev *model.Revision
+
+	for rows.Next() {
+		var rev model.Revision
+
+		if err := rows.Scan(&rev.Revision, &rev.Action, &rev.Title, &rev.URL, (*stringList)(&rev.Tags), &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+
+		rev.Changes = rev.Diff(prev)
+		prev = &rev
+
+		revisions = append(revisions, &rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate revisions rows: %w", err)
+	}
+
+	if len(revisions) == 0 {
+		return nil, storage.ErrNotFound
+	}
+
+	slices.Reverse(revisions)
+
+	return revisions, nil
+}
+
+// RevertBookmark restores the title, url and tags the bookmark had at the
+// given revision, recording it as a new revision.
+func (s *Storage) RevertBookmark(ctx context.Context, id, revision int) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var rev model.Revision
+
+	err = sq.
+		Select("r.title", "r.url", "r.tags").
+		From("bookmark_revisions r").
+		Join("bookmarks b ON b.id = r.bookmark_id").
+		Where(sq.Eq{"r.bookmark_id": id, "r.revision": revision, "b.user_id": userID, "b.deleted_at": nil}).
+		RunWith(tx).
+		QueryRowContext(ctx).
+		Scan(&rev.Title, &rev.URL, (*stringList)(&rev.Tags))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrRevisionNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	stmt := sq.
+		Update("bookmarks").
+		Set("title", rev.Title).
+		Set("url", rev.URL).
+		Set("updated_at", now()).
+		Where(sq.Eq{"id": id, "user_id": userID, "deleted_at": nil}).
+		RunWith(tx)
+
+	if err := updateOne(ctx, stmt); err != nil {
+		return nil, err
+	}
+
+	if err := setBookmarkTags(ctx, tx, id, rev.Tags); err != nil {
+		return nil, err
+	}
+
+	return s.commitBookmark(ctx, tx, userID, id, model.RevisionReverted, model.EventBookmarkUpdated)
+}
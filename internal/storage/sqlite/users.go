@@ -0,0 +1,246 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+// tokenUsageResolution limits how often last_used_at is written, so that
+// busy clients don't turn every read into a write.
+const tokenUsageResolution = time.Minute
+
+func (s *Storage) CreateUser(ctx context.Context, username, passwordHash string) (*model.User, error) {
+	u := model.User{
+		Username:     username,
+		PasswordHash: passwordHash,
+		CreatedAt:    now(),
+	}
+
+	result, err := sq.
+		Insert("users").
+		Columns("username", "password_hash", "created_at").
+		Values(u.Username, u.PasswordHash, u.CreatedAt).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, storage.ErrUserExists
+		}
+
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user id: %w", err)
+	}
+	u.ID = int(id)
+
+	return &u, nil
+}
+
+func (s *Storage) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
+	stmt := sq.
+		Select("id", "username", "password_hash", "created_at").
+		From("users").
+		Where(sq.Eq{"username": username}).
+		RunWith(s.db)
+
+	var u model.User
+	if err := stmt.QueryRowContext(ctx).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrUserNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &u, nil
+}
+
+// GetUsers returns all users in the order they signed up.
+func (s *Storage) GetUsers(ctx context.Context) ([]*model.User, error) {
+	stmt := sq.
+		Select("id", "username", "password_hash", "created_at").
+		From("users").
+		OrderBy("id").
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	users := []*model.User{}
+	for rows.Next() {
+		var u model.User
+
+		if err := rows.Scan(&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		users = append(users, &u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate users rows: %w", err)
+	}
+
+	return users, nil
+}
+
+func (s *Storage) GetTokens(ctx context.Context) ([]*model.Token, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select("id", "name", "scopes", "created_at", "last_used_at", "revoked_at").
+		From("api_tokens").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at DESC", "id DESC").
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tokens rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	tokens := []*model.Token{}
+	for rows.Next() {
+		var t model.Token
+
+		if err := rows.Scan(&t.ID, &t.Name, (*stringList)(&t.Scopes), &t.CreatedAt, &t.LastUsedAt, &t.RevokedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan token: %w", err)
+		}
+
+		tokens = append(tokens, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tokens rows: %w", err)
+	}
+
+	return tokens, nil
+}
+
+func (s *Storage) CreateToken(ctx context.Context, name, tokenHash string, scopes []string) (*model.Token, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t := model.Token{
+		Name:      name,
+		Scopes:    scopes,
+		CreatedAt: now(),
+	}
+
+	result, err := sq.
+		Insert("api_tokens").
+		Columns("user_id", "name", "token_hash", "scopes", "created_at").
+		Values(userID, t.Name, tokenHash, stringList(t.Scopes), t.CreatedAt).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token id: %w", err)
+	}
+	t.ID = int(id)
+
+	return &t, nil
+}
+
+func (s *Storage) RevokeToken(ctx context.Context, id int) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := sq.
+		Update("api_tokens").
+		Set("revoked_at", now()).
+		Where(sq.Eq{"id": id, "user_id": userID, "revoked_at": nil}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get check revocation: %w", err)
+	}
+
+	if rowAffected == 0 {
+		return storage.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// AuthenticateToken finds the owner of an active token by the token hash and
+// records that the token was used.
+func (s *Storage) AuthenticateToken(ctx context.Context, tokenHash string) (*model.User, *model.Token, error) {
+	stmt := sq.
+		Select(
+			"u.id", "u.username", "u.password_hash", "u.created_at",
+			"t.id", "t.name", "t.scopes", "t.created_at", "t.last_used_at", "t.revoked_at",
+		).
+		From("api_tokens t").
+		Join("users u ON u.id = t.user_id").
+		Where(sq.Eq{"t.token_hash": tokenHash, "t.revoked_at": nil}).
+		RunWith(s.db)
+
+	var u model.User
+	var t model.Token
+
+	err := stmt.QueryRowContext(ctx).Scan(
+		&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt,
+		&t.ID, &t.Name, (*stringList)(&t.Scopes), &t.CreatedAt, &t.LastUsedAt, &t.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, storage.ErrTokenNotFound
+		}
+
+		return nil, nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	if t.LastUsedAt == nil || time.Since(*t.LastUsedAt) > tokenUsageResolution {
+		usedAt := now()
+
+		_, err := sq.
+			Update("api_tokens").
+			Set("last_used_at", usedAt).
+			Where(sq.Eq{"id": t.ID}).
+			RunWith(s.db).
+			ExecContext(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update token usage: %w", err)
+		}
+
+		t.LastUsedAt = &usedAt
+	}
+
+	return &u, &t, nil
+}
@@ -0,0 +1,547 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+var deliveryColumns = []string{
+	"d.id", "d.webhook_id", "d.event_id", "e.event", "d.status", "d.attempts",
+	"d.next_attempt_at", "d.last_attempt_at", "d.response_status", "d.error", "d.created_at",
+}
+
+// deliveryFields returns scan destinations matching deliveryColumns.
+func deliveryFields(d *model.WebhookDelivery) []any {
+	return []any{
+		&d.ID, &d.WebhookID, &d.EventID, &d.Event, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastAttemptAt, &d.ResponseStatus, &d.Error, &d.CreatedAt,
+	}
+}
+
+func (s *Storage) GetWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select("id", "url", "events", "created_at").
+		From("webhooks").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at DESC", "id DESC").
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	webhooks := []*model.Webhook{}
+	for rows.Next() {
+		var wh model.Webhook
+
+		if err := rows.Scan(&wh.ID, &wh.URL, (*stringList)(&wh.Events), &wh.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+
+		webhooks = append(webhooks, &wh)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhooks rows: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (s *Storage) CreateWebhook(ctx context.Context, url, secret string, events []string) (*model.Webhook, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wh := model.Webhook{
+		URL:       url,
+		Events:    events,
+		CreatedAt: now(),
+	}
+
+	result, err := sq.
+		Insert("webhooks").
+		Columns("user_id", "url", "secret", "events", "created_at").
+		Values(userID, wh.URL, secret, stringList(wh.Events), wh.CreatedAt).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook id: %w", err)
+	}
+	wh.ID = int(id)
+
+	return &wh, nil
+}
+
+func (s *Storage) DeleteWebhook(ctx context.Context, id int) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := sq.
+		Delete("webhooks").
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get check deletion: %w", err)
+	}
+
+	if rowAffected == 0 {
+		return storage.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries returns the delivery log of the webhook, newest first.
+func (s *Storage) GetWebhookDeliveries(ctx context.Context, webhookID, limit int) ([]*model.WebhookDelivery, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkWebhookOwner(ctx, s.db, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select(deliveryColumns...).
+		From("webhook_deliveries d").
+		Join("outbox_events e ON e.id = d.event_id").
+		Where(sq.Eq{"d.webhook_id": webhookID}).
+		OrderBy("d.id DESC").
+		Limit(uint64(limit)).
+		RunWith(s.db)
+
+	return queryDeliveries(ctx, stmt, false)
+}
+
+// ReplayDelivery schedules the event of a past delivery to be sent again. The
+// original delivery is kept in the log untouched.
+func (s *Storage) ReplayDelivery(ctx context.Context, webhookID int, deliveryID int64) (*model.WebhookDelivery, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := checkWebhookOwner(ctx, tx, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	createdAt := now()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, next_attempt_at, created_at)
+		SELECT webhook_id, event_id, ?, ? FROM webhook_deliveries
+		WHERE id = ? AND webhook_id = ?`,
+		createdAt, createdAt, deliveryID, webhookID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay delivery: %w", err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check replay: %w", err)
+	}
+
+	if rowAffected == 0 {
+		return nil, storage.ErrDeliveryNotFound
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery id: %w", err)
+	}
+
+	var d model.WebhookDelivery
+
+	err = sq.
+		Select(deliveryColumns...).
+		From("webhook_deliveries d").
+		Join("outbox_events e ON e.id = d.event_id").
+		Where(sq.Eq{"d.id": id}).
+		RunWith(tx).
+		QueryRowContext(ctx).
+		Scan(deliveryFields(&d)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &d, nil
+}
+
+// DispatchEvents fans out up to limit pending outbox events into deliveries
+// for the webhooks subscribed to them and returns how many events it handled.
+func (s *Storage) DispatchEvents(ctx context.Context, limit int) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id FROM outbox_events WHERE dispatched_at IS NULL ORDER BY id LIMIT ?",
+		limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get outbox events: %w", err)
+	}
+
+	var ids []any
+	for rows.Next() {
+		var id int64
+
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate outbox events: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	placeholders := sq.Placeholders(len(ids))
+	createdAt := now()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, next_attempt_at, created_at)
+		SELECT w.id, e.id, ?, ?
+		FROM outbox_events e
+		JOIN webhooks w ON w.user_id = e.user_id
+		WHERE e.id IN (`+placeholders+`)
+		AND EXISTS (SELECT 1 FROM json_each(w.events) WHERE value = e.event)
+		ORDER BY e.id`,
+		append([]any{createdAt, createdAt}, ids...)...,
+	); err != nil {
+		return 0, fmt.Errorf("failed to create deliveries: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE outbox_events SET dispatched_at = ? WHERE id IN ("+placeholders+")",
+		append([]any{createdAt}, ids...)...,
+	); err != nil {
+		return 0, fmt.Errorf("failed to mark outbox events dispatched: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(ids), nil
+}
+
+// ClaimDeliveries returns up to limit deliveries that are due, pushing their
+// next attempt by lease so that concurrent workers don't pick them up twice.
+func (s *Storage) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	claimedAt := now()
+
+	stmt := sq.
+		Select(append(deliveryColumns, "w.url", "w.secret", "e.payload")...).
+		From("webhook_deliveries d").
+		Join("webhooks w ON w.id = d.webhook_id").
+		Join("outbox_events e ON e.id = d.event_id").
+		Where(sq.Eq{"d.status": model.DeliveryPending}).
+		Where(sq.LtOrEq{"d.next_attempt_at": claimedAt}).
+		OrderBy("d.next_attempt_at").
+		Limit(uint64(limit)).
+		RunWith(tx)
+
+	deliveries, err := queryDeliveries(ctx, stmt, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range deliveries {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ?",
+			claimedAt.Add(lease), d.ID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to claim delivery: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// SetDeliveryResult records the outcome of a delivery attempt.
+func (s *Storage) SetDeliveryResult(ctx context.Context, id int64, result model.DeliveryResult) error {
+	status := model.DeliveryPending
+	switch {
+	case result.Succeeded:
+		status = model.DeliverySucceeded
+	case result.NextAttemptAt == nil:
+		status = model.DeliveryFailed
+	}
+
+	stmt := sq.
+		Update("webhook_deliveries").
+		Set("status", status).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_attempt_at", now()).
+		Set("response_status", result.ResponseStatus).
+		Set("error", result.Error).
+		Where(sq.Eq{"id": id}).
+		RunWith(s.db)
+
+	if result.NextAttemptAt != nil {
+		stmt = stmt.Set("next_attempt_at", result.NextAttemptAt.UTC())
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("failed to set delivery result: %w", err)
+	}
+
+	return nil
+}
+
+// GetEventsSince returns up to limit events of the user recorded after the
+// given event id, oldest first.
+func (s *Storage) GetEventsSince(ctx context.Context, afterID int64, limit int) ([]*model.StoredEvent, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select("id", "event", "payload").
+		From("outbox_events").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Gt{"id": afterID}).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	events := []*model.StoredEvent{}
+	for rows.Next() {
+		var e model.StoredEvent
+		var payload string
+
+		if err := rows.Scan(&e.ID, &e.Event, &payload); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		e.Payload = []byte(payload)
+
+		events = append(events, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate events rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetLastEventID returns the id of the latest event of the user, or 0 when
+// there is none yet.
+func (s *Storage) GetLastEventID(ctx context.Context) (int64, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+
+	err = sq.
+		Select("COALESCE(MAX(id), 0)").
+		From("outbox_events").
+		Where(sq.Eq{"user_id": userID}).
+		RunWith(s.db).
+		QueryRowContext(ctx).
+		Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last event id: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetChanges returns up to limit bookmarks of the user changed after the
+// given change sequence number, trashed ones included as tombstones. When
+// bookmarks were purged since then, the client has to start over: Reset is
+// set and changes are listed from the beginning.
+func (s *Storage) GetChanges(ctx context.Context, since int64, limit int) (*model.ChangeSet, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &model.ChangeSet{}
+
+	if since > 0 {
+		var resetSeq int64
+
+		err := sq.
+			Select("change_seq").
+			From("sync_resets").
+			Where(sq.Eq{"user_id": userID}).
+			RunWith(s.db).
+			QueryRowContext(ctx).
+			Scan(&resetSeq)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get sync reset: %w", err)
+		}
+
+		if since < resetSeq {
+			changes.Reset = true
+			since = 0
+		}
+	}
+
+	stmt := sq.
+		Select(append(bookmarkColumns, bookmarkTagsColumn)...).
+		From("bookmarks").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Gt{"change_seq": since}).
+		OrderBy("change_seq").
+		Limit(uint64(limit) + 1).
+		RunWith(s.db)
+
+	bookmarks, err := queryBookmarks(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	changes.HasMore = len(bookmarks) > limit
+	if changes.HasMore {
+		bookmarks = bookmarks[:limit]
+	}
+
+	changes.Bookmarks = bookmarks
+	changes.Since = since
+	if len(bookmarks) > 0 {
+		changes.Since = bookmarks[len(bookmarks)-1].Version
+	}
+
+	return changes, nil
+}
+
+// queryDeliveries runs a select of deliveryColumns, followed by the webhook
+// URL, secret and event payload when withTarget is set.
+func queryDeliveries(ctx context.Context, stmt sq.SelectBuilder, withTarget bool) ([]*model.WebhookDelivery, error) {
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deliveries rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	deliveries := []*model.WebhookDelivery{}
+	for rows.Next() {
+		var d model.WebhookDelivery
+		var payload string
+
+		fields := deliveryFields(&d)
+		if withTarget {
+			fields = append(fields, &d.URL, &d.Secret, &payload)
+		}
+
+		if err := rows.Scan(fields...); err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+		d.Payload = []byte(payload)
+
+		deliveries = append(deliveries, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate deliveries rows: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// checkWebhookOwner makes sure the webhook belongs to the user.
+func checkWebhookOwner(ctx context.Context, runner sq.BaseRunner, userID, webhookID int) error {
+	var found bool
+
+	err := sq.
+		Select("true").
+		From("webhooks").
+		Where(sq.Eq{"id": webhookID, "user_id": userID}).
+		RunWith(runner).
+		QueryRowContext(ctx).
+		Scan(&found)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrWebhookNotFound
+		}
+
+		return fmt.Errorf("failed to find webhook: %w", err)
+	}
+
+	return nil
+}
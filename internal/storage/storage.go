@@ -1,10 +1,199 @@
 package storage
 
 import (
+	"context"
 	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+const (
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+	BackendMemory   = "memory"
 )
 
+// Storage is implemented by every storage backend. User facing methods are
+// scoped to the user carried by ctx, see auth.WithUser.
+type Storage interface {
+	Ping(ctx context.Context) error
+	Close() error
+
+	GetBookmarks(ctx context.Context, filter BookmarkFilter) (*BookmarkPage, error)
+	GetBookmark(ctx context.Context, id int) (*model.Bookmark, error)
+	CreateBookmark(ctx context.Context, title, url string, tags []string, folderID *int) (*model.Bookmark, error)
+	ImportBookmark(ctx context.Context, bookmark *model.Bookmark) (*model.Bookmark, error)
+	EditBookmark(ctx context.Context, id int, title, url string, tags []string) (*model.Bookmark, error)
+	EditBookmarkIfUnchanged(ctx context.Context, id int, version int64, title, url string, tags []string) (*model.Bookmark, error)
+	DeleteBookmark(ctx context.Context, id int) error
+	DeleteBookmarkIfUnchanged(ctx context.Context, id int, version int64) error
+	BookmarkExist(ctx context.Context, url string) (int, bool, error)
+	MoveBookmark(ctx context.Context, id int, folderID *int) (*model.Bookmark, error)
+	GetTags(ctx context.Context) ([]*model.Tag, error)
+
+	GetFolders(ctx context.Context) ([]*model.Folder, error)
+	CreateFolder(ctx context.Context, name string, parentID *int) (*model.Folder, error)
+	ImportFolder(ctx context.Context, name string, parentID *int) (*model.Folder, error)
+	EditFolder(ctx context.Context, id int, name string, parentID *int) (*model.Folder, error)
+	DeleteFolder(ctx context.Context, id int) error
+
+	CreateUser(ctx context.Context, username, passwordHash string) (*model.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
+
+	GetTokens(ctx context.Context) ([]*model.Token, error)
+	CreateToken(ctx context.Context, name, tokenHash string, scopes []string) (*model.Token, error)
+	RevokeToken(ctx context.Context, id int) error
+	AuthenticateToken(ctx context.Context, tokenHash string) (*model.User, *model.Token, error)
+
+	GetTrash(ctx context.Context) ([]*model.Bookmark, error)
+	RestoreBookmark(ctx context.Context, id int) (*model.Bookmark, error)
+	PurgeBookmark(ctx context.Context, id int) error
+
+	GetBookmarkHistory(ctx context.Context, id int) ([]*model.Revision, error)
+	RevertBookmark(ctx context.Context, id, revision int) (*model.Bookmark, error)
+
+	GetLinkReport(ctx context.Context) (*model.LinkReport, error)
+
+	GetWebhooks(ctx context.Context) ([]*model.Webhook, error)
+	CreateWebhook(ctx context.Context, url, secret string, events []string) (*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	GetWebhookDeliveries(ctx context.Context, webhookID, limit int) ([]*model.WebhookDelivery, error)
+	ReplayDelivery(ctx context.Context, webhookID int, deliveryID int64) (*model.WebhookDelivery, error)
+
+	GetEventsSince(ctx context.Context, afterID int64, limit int) ([]*model.StoredEvent, error)
+	GetLastEventID(ctx context.Context) (int64, error)
+	GetChanges(ctx context.Context, since int64, limit int) (*model.ChangeSet, error)
+
+	// The methods below serve background jobs and aren't scoped to a user.
+
+	GetUsers(ctx context.Context) ([]*model.User, error)
+	GetStats(ctx context.Context) (*model.Stats, error)
+
+	SetBookmarkMetadata(ctx context.Context, id int, metadata model.Metadata) error
+	GetBookmarksToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*model.Bookmark, error)
+	SetLinkCheck(ctx context.Context, id int, check model.LinkCheck) error
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error)
+
+	DispatchEvents(ctx context.Context, limit int) (int, error)
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error)
+	SetDeliveryResult(ctx context.Context, id int64, result model.DeliveryResult) error
+
+	// ListenEvents calls notify with the user id of every event recorded
+	// from now on, by any instance sharing the storage, until ctx is
+	// cancelled. A zero user id means events may have been missed.
+	ListenEvents(ctx context.Context, notify func(userID int)) error
+}
+
+var _ Storage = (*PostgresStorage)(nil)
+
 var (
 	ErrNotFound = errors.New("bookmark not found")
 	ErrExists   = errors.New("bookmark for this url already exists")
+
+	ErrFolderNotFound = errors.New("folder not found")
+	ErrFolderCycle    = errors.New("folder can't be moved into itself or its subfolder")
+
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = errors.New("user with this username already exists")
+
+	ErrTokenNotFound = errors.New("token not found")
+
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrConflict         = errors.New("bookmark was changed since the given version")
+
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+	ErrUnknownMigration = errors.New("unknown migration version")
+	ErrSchemaDirty      = errors.New("schema is dirty, a migration failed halfway and has to be fixed by hand")
 )
+
+// BookmarkFilter narrows down the list of bookmarks returned by GetBookmarks.
+type BookmarkFilter struct {
+	Limit  int
+	Offset int
+	Search string
+
+	// Cursor continues the listing after (or before, when Backward is set)
+	// the given position instead of using Offset. It's ignored for searches,
+	// which are ordered by relevance.
+	Cursor *Cursor
+
+	// SkipCount leaves BookmarkPage.Total unset, saving a count query on
+	// large libraries.
+	SkipCount bool
+
+	// FolderID keeps only bookmarks stored directly in the given folder.
+	FolderID *int
+
+	// LinkStatus keeps only bookmarks with the given link checker status.
+	LinkStatus string
+
+	// Tags keeps only bookmarks labeled with the given tags. By default a
+	// bookmark has to carry all of them, AnyTag relaxes it to at least one.
+	Tags   []string
+	AnyTag bool
+}
+
+// Cursor is a keyset position in the bookmarks list, which is ordered by
+// creation time and id, newest first.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int
+	Backward  bool
+}
+
+// BookmarkPage is a single page of bookmarks. NextCursor and PrevCursor are
+// nil when there is nothing to page to in that direction.
+type BookmarkPage struct {
+	Bookmarks  []*model.Bookmark
+	Total      *int
+	NextCursor *Cursor
+	PrevCursor *Cursor
+}
+
+// NewBookmarkPage builds a page out of up to filter.Limit+1 bookmarks, as
+// fetched by a backend in listing order, or in reverse order when paging
+// backward. The extra bookmark only tells that there is a further page.
+func NewBookmarkPage(filter BookmarkFilter, bookmarks []*model.Bookmark, total *int) *BookmarkPage {
+	searching := filter.Search != ""
+	cursor := filter.Cursor
+	backward := !searching && cursor != nil && cursor.Backward
+
+	hasMore := len(bookmarks) > filter.Limit
+	if hasMore {
+		bookmarks = bookmarks[:filter.Limit]
+	}
+
+	if backward {
+		slices.Reverse(bookmarks)
+	}
+
+	page := &BookmarkPage{Bookmarks: bookmarks, Total: total}
+
+	// Relevance ordering has no stable keyset, searches are paged by offset.
+	if searching || len(bookmarks) == 0 {
+		return page
+	}
+
+	first, last := bookmarks[0], bookmarks[len(bookmarks)-1]
+
+	if hasMore || backward {
+		page.NextCursor = &Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	if (backward && hasMore) || (!backward && (cursor != nil || filter.Offset > 0)) {
+		page.PrevCursor = &Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true}
+	}
+
+	return page
+}
+
+// log returns the logger carried by ctx for the storage package.
+func log(ctx context.Context) *slog.Logger {
+	return logger.For(ctx, "storage")
+}
@@ -0,0 +1,986 @@
+// Package storagetest checks that a storage backend behaves the way the API
+// expects, so every backend is held to the same semantics.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+// Run runs the conformance tests against the backend returned by
+// newStorage, which is called once per test and has to return an empty
+// storage.
+func Run(t *testing.T, newStorage func(t *testing.T) storage.Storage) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s storage.Storage)
+	}{
+		{"Users", testUsers},
+		{"Bookmarks", testBookmarks},
+		{"Conflicts", testConflicts},
+		{"Isolation", testIsolation},
+		{"Pagination", testPagination},
+		{"Tags", testTags},
+		{"Search", testSearch},
+		{"Folders", testFolders},
+		{"Trash", testTrash},
+		{"History", testHistory},
+		{"Tokens", testTokens},
+		{"Changes", testChanges},
+		{"Events", testEvents},
+		{"Metadata", testMetadata},
+		{"Webhooks", testWebhooks},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStorage(t))
+		})
+	}
+}
+
+func testUsers(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, "alice", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if user.ID == 0 || user.Username != "alice" {
+		t.Errorf("CreateUser() = %+v, want a new user named alice", user)
+	}
+
+	if _, err := s.CreateUser(ctx, "alice", "other"); !errors.Is(err, storage.ErrUserExists) {
+		t.Errorf("CreateUser() of a taken username error = %v, want ErrUserExists", err)
+	}
+
+	found, err := s.GetUserByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetUserByUsername() error = %v", err)
+	}
+	if found.ID != user.ID || found.PasswordHash != "hash" {
+		t.Errorf("GetUserByUsername() = %+v, want user %d with its password hash", found, user.ID)
+	}
+
+	if _, err := s.GetUserByUsername(ctx, "bob"); !errors.Is(err, storage.ErrUserNotFound) {
+		t.Errorf("GetUserByUsername() of an unknown user error = %v, want ErrUserNotFound", err)
+	}
+
+	users, err := s.GetUsers(ctx)
+	if err != nil {
+		t.Fatalf("GetUsers() error = %v", err)
+	}
+	if len(users) != 1 || users[0].ID != user.ID {
+		t.Errorf("GetUsers() = %v, want only user %d", users, user.ID)
+	}
+}
+
+func testBookmarks(t *testing.T, s storage.Storage) {
+	ctx := userContext(t, s, "alice")
+
+	bm, err := s.CreateBookmark(ctx, "Go", "https://go.dev", []string{"lang", "go", "go"}, nil)
+	if err != nil {
+		t.Fatalf("CreateBookmark() error = %v", err)
+	}
+	if bm.ID == 0 || bm.Title != "Go" || bm.URL != "https://go.dev" || bm.Version == 0 {
+		t.Errorf("CreateBookmark() = %+v, want a new versioned bookmark", bm)
+	}
+	if !slices.Equal(bm.Tags, []string{"go", "lang"}) {
+		t.Errorf("CreateBookmark() tags = %v, want sorted tags without duplicates", bm.Tags)
+	}
+
+	if _, err := s.CreateBookmark(ctx, "Go again", "https://go.dev", nil, nil); !errors.Is(err, storage.ErrExists) {
+		t.Errorf("CreateBookmark() of a bookmarked URL error = %v, want ErrExists", err)
+	}
+
+	got, err := s.GetBookmark(ctx, bm.ID)
+	if err != nil {
+		t.Fatalf("GetBookmark() error = %v", err)
+	}
+	if got.Title != bm.Title || got.URL != bm.URL || !slices.Equal(got.Tags, bm.Tags) {
+		t.Errorf("GetBookmark() = %+v, want %+v", got, bm)
+	}
+
+	id, found, err := s.BookmarkExist(ctx, "https://go.dev")
+	if err != nil || !found || id != bm.ID {
+		t.Errorf("BookmarkExist() = %d, %v, %v, want %d, true, nil", id, found, err, bm.ID)
+	}
+	if _, found, err := s.BookmarkExist(ctx, "https://example.com"); err != nil || found {
+		t.Errorf("BookmarkExist() of an unknown URL = %v, %v, want false, nil", found, err)
+	}
+
+	edited, err := s.EditBookmark(ctx, bm.ID, "The Go language", "https://go.dev/doc", []string{"go"})
+	if err != nil {
+		t.Fatalf("EditBookmark() error = %v", err)
+	}
+	if edited.Title != "The Go language" || edited.URL != "https://go.dev/doc" || !slices.Equal(edited.Tags, []string{"go"}) {
+		t.Errorf("EditBookmark() = %+v, want the new title, url and tags", edited)
+	}
+	if edited.Version <= bm.Version {
+		t.Errorf("EditBookmark() version = %d, want above %d", edited.Version, bm.Version)
+	}
+
+	other := createBookmark(t, ctx, s, "Rust", "https://rust-lang.org")
+	if _, err := s.EditBookmark(ctx, other.ID, "Rust", "https://go.dev/doc", nil); !errors.Is(err, storage.ErrExists) {
+		t.Errorf("EditBookmark() to a bookmarked URL error = %v, want ErrExists", err)
+	}
+
+	if _, err := s.EditBookmark(ctx, 9999, "Missing", "https://missing.example.com", nil); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("EditBookmark() of an unknown bookmark error = %v, want ErrNotFound", err)
+	}
+
+	if err := s.DeleteBookmark(ctx, bm.ID); err != nil {
+		t.Fatalf("DeleteBookmark() error = %v", err)
+	}
+	if _, err := s.GetBookmark(ctx, bm.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("GetBookmark() of a deleted bookmark error = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteBookmark(ctx, bm.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("DeleteBookmark() of a deleted bookmark error = %v, want ErrNotFound", err)
+	}
+}
+
+func testConflicts(t *testing.T, s storage.Storage) {
+	ctx := userContext(t, s, "alice")
+
+	bm := createBookmark(t, ctx, s, "Go", "https://go.dev")
+
+	edited, err := s.EditBookmarkIfUnchanged(ctx, bm.ID, bm.Version, "Go docs", bm.URL, nil)
+	if err != nil {
+		t.Fatalf("EditBookmarkIfUnchanged() with the current version error = %v", err)
+	}
+
+	if _, err := s.EditBookmarkIfUnchanged(ctx, bm.ID, bm.Version, "Stale", bm.URL, nil); !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("EditBookmarkIfUnchanged() with a stale version error = %v, want ErrConflict", err)
+	}
+
+	if err := s.DeleteBookmarkIfUnchanged(ctx, bm.ID, bm.Version); !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("DeleteBookmarkIfUnchanged() with a stale version error = %v, want ErrConflict", err)
+	}
+
+	got, err := s.GetBookmark(ctx, bm.ID)
+	if err != nil {
+		t.Fatalf("GetBookmark() error = %v", err)
+	}
+	if got.Title != "Go docs" {
+		t.Errorf("GetBookmark() title = %q, want the conflicting changes discarded", got.Title)
+	}
+
+	if err := s.DeleteBookmarkIfUnchanged(ctx, bm.ID, edited.Version); err != nil {
+		t.Errorf("DeleteBookmarkIfUnchanged() with the current version error = %v", err)
+	}
+}
+
+func testIsolation(t *testing.T, s storage.Storage) {
+	alice := userContext(t, s, "alice")
+	bob := userContext(t, s, "bob")
+
+	bm := createBookmark(t, alice, s, "Go", "https://go.dev")
+	folder, err := s.CreateFolder(alice, "Work", nil)
+	if err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+
+	if _, err := s.GetBookmark(bob, bm.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("GetBookmark() of another user's bookmark error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.EditBookmark(bob, bm.ID, "Mine", bm.URL, nil); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("EditBookmark() of another user's bookmark error = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteBookmark(bob, bm.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("DeleteBookmark() of another user's bookmark error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.CreateBookmark(bob, "Go", "https://go.dev", nil, &folder.ID); !errors.Is(err, storage.ErrFolderNotFound) {
+		t.Errorf("CreateBookmark() into another user's folder error = %v, want ErrFolderNotFound", err)
+	}
+
+	// URLs are unique per user only.
+	createBookmark(t, bob, s, "Go", "https://go.dev")
+
+	for _, ctx := range []context.Context{alice, bob} {
+		page, err := s.GetBookmarks(ctx, storage.BookmarkFilter{Limit: 10})
+		if err != nil {
+			t.Fatalf("GetBookmarks() error = %v", err)
+		}
+		if len(page.Bookmarks) != 1 {
+			t.Errorf("GetBookmarks() = %d bookmarks, want only the user's own", len(page.Bookmarks))
+		}
+	}
+
+	folders, err := s.GetFolders(bob)
+	if err != nil {
+		t.Fatalf("GetFolders() error = %v", err)
+	}
+	if len(folders) != 0 {
+		t.Errorf("GetFolders() = %v, want none of another user's folders", folders)
+	}
+}
+
+func testPagination(t *testing.T, s storage.Storage) {
+	ctx := userContext(t, s, "alice")
+
+	var want []int
+	for i := range 5 {
+		bm := createBookmark(t, ctx, s, fmt.Sprintf("Page %d", i), fmt.Sprintf("https://example.com/%d", i))
+		want = append(want, bm.ID)
+	}
+	// Listings are newest first.
+	slices.Reverse(want)
+
+	first, err := s.GetBookmarks(ctx, storage.BookmarkFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("GetBookmarks() error = %v", err)
+	}
+	if first.Total == nil || *first.Total != 5 {
+		t.Errorf("GetBookmarks() total = %v, want 5", first.Total)
+	}
+	if first.PrevCursor != nil {
+		t.Errorf("GetBookmarks() first page has a previous cursor")
+	}
+
+	var got []int
+	page := first
+	for pages := 1; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("GetBookmarks() pages past the last one")
+		}
+
+		for _, bm := range page.Bookmarks {
+			got = append(got, bm.ID)
+		}
+		if page.NextCursor == nil {
+			break
+		}
+
+		page, err = s.GetBookmarks(ctx, storage.BookmarkFilter{Limit: 2, Cursor: page.NextCursor, SkipCount: true})
+		if err != nil {
+			t.Fatalf("GetBookmarks() error = %v", err)
+		}
+		if page.Total != nil {
+			t.Errorf("GetBookmarks() with SkipCount total = %d, want none", *page.Total)
+		}
+	}
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("GetBookmarks() pages = %v, want %v", got, want)
+	}
+
+	second, err := s.GetBookmarks(ctx, storage.BookmarkFilter{Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("GetBookmarks() error = %v", err)
+	}
+	if second.PrevCursor == nil {
+		t.Fatalf("GetBookmarks() second page has no previous cursor")
+	}
+
+	back, err := s.GetBookmarks(ctx, storage.BookmarkFilter{Limit: 2, Cursor: second.PrevCursor})
+	if err != nil {
+		t.Fatalf("GetBookmarks() error = %v", err)
+	}
+	if ids := bookmarkIDs(back.Bookmarks); !slices.Equal(ids, want[:2]) {
+		t.Errorf("GetBookmarks() backward = %v, want %v", ids, want[:2])
+	}
+	if back.PrevCursor != nil {
+		t.Errorf("GetBookmarks() backward to the first page has a previous cursor")
+	}
+
+	offset, err := s.GetBookmarks(ctx, storage.BookmarkFilter{Limit: 2, Offset: 4})
+	if err != nil {
+		t.Fatalf("GetBookmarks() error = %v", err)
+	}
+	if ids := bookmarkIDs(offset.Bookmarks); !slices.Equal(ids, want[4:]) {
+		t.Errorf("GetBookmarks() with offset = %v, want %v", ids, want[4:])
+	}
+}
+
+func testTags(t *testing.T, s storage.Storage) {
+	ctx := userContext(t, s, "alice")
+
+	goWeb, err := s.CreateBookmark(ctx, "Go web", "https://go.dev/web", []string{"go", "web"}, nil)
+	if err != nil {
+		t.Fatalf("CreateBookmark() error = %v", err)
+	}
+	goDoc, err := s.CreateBookmark(ctx, "Go doc", "https://go.dev/doc", []string{"go"}, nil)
+	if err != nil {
+		t.Fatalf("CreateBookmark() error = %v", err)
+	}
+	rust, err := s.CreateBookmark(ctx, "Rust", "https://rust-lang.org", []string{"rust"}, nil)
+	if err != nil {
+		t.Fatalf("CreateBookmark() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter storage.BookmarkFilter
+		want   []int
+	}{
+		{"single tag", storage.BookmarkFilter{Tags: []string{"go"}}, []int{goDoc.ID, goWeb.ID}},
+		{"all tags", storage.BookmarkFilter{Tags: []string{"go", "web"}}, []int{goWeb.ID}},
+		{"any tag", storage.BookmarkFilter{Tags: []string{"web", "rust"}, AnyTag: true}, []int{rust.ID, goWeb.ID}},
+		{"unknown tag", storage.BookmarkFilter{Tags: []string{"java"}}, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Limit = 10
+
+			page, err := s.GetBookmarks(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("GetBookmarks() error = %v", err)
+			}
+			if ids := bookmarkIDs(page.Bookmarks); !slices.Equal(ids, tt.want) {
+				t.Errorf("GetBookmarks() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	tags, err := s.GetTags(ctx)
+	if err != nil {
+		t.Fatalf("GetTags() error = %v", err)
+	}
+
+	want := []model.Tag{{Name: "go", Count: 2}, {Name: "rust", Count: 1}, {Name: "web", Count: 1}}
+	if len(tags) != len(want) {
+		t.Fatalf("GetTags() = %d tags, want %d", len(tags), len(want))
+	}
+	for i, tag := range tags {
+		if *tag != want[i] {
+			t.Errorf("GetTags()[%d] = %+v, want %+v", i, *tag, want[i])
+		}
+	}
+}
+
+func testSearch(t *testing.T, s storage.Storage) {
+	ctx := userContext(t, s, "alice")
+
+	book := createBookmark(t, ctx, s, "The Rust programming language book", "https://doc.rust-lang.org/book")
+	createBookmark(t, ctx, s, "A tour of Go", "https://go.dev/tour")
+	trashed := createBookmark(t, ctx, s, "Rust by example", "https://doc.rust-lang.org/rust-by-example")
+
+	if err := s.DeleteBookmark(ctx, trashed.ID); err != nil {
+		t.Fatalf("DeleteBookmark() error = %v", err)
+	}
+
+	tests := []struct {
+		search string
+		want   []int
+	}{
+		{"rust", []int{book.ID}},
+		{"Rust book", []int{book.ID}},
+		{"rust tour", []int{}},
+		{"python", []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			page, err := s.GetBookmarks(ctx, storage.BookmarkFilter{Limit: 10, Search: tt.search})
+			if err != nil {
+				t.Fatalf("GetBookmarks() error = %v", err)
+			}
+			if ids := bookmarkIDs(page.Bookmarks); !slices.Equal(ids, tt.want) {
+				t.Errorf("GetBookmarks() = %v, want %v", ids, tt.want)
+			}
+			if page.NextCursor != nil || page.PrevCursor != nil {
+				t.Errorf("GetBookmarks() of a search has cursors, want offset paging")
+			}
+		})
+	}
+}
+
+func testFolders(t *testing.T, s storage.Storage) {
+	ctx := userContext(t, s, "alice")
+
+	parent, err := s.CreateFolder(ctx, "Work", nil)
+	if err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+	child, err := s.CreateFolder(ctx, "Docs", &parent.ID)
+	if err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+	if child.ParentID == nil || *child.ParentID != parent.ID {
+		t.Errorf("CreateFolder() parent = %v, want %d", child.ParentID, parent.ID)
+	}
+
+	unknown := 9999
+	if _, err := s.CreateFolder(ctx, "Lost", &unknown); !errors.Is(err, storage.ErrFolderNotFound) {
+		t.Errorf("CreateFolder() under an unknown folder error = %v, want ErrFolderNotFound", err)
+	}
+	if _, err := s.CreateBookmark(ctx, "Go", "https://go.dev", nil, &unknown); !errors.Is(err, storage.ErrFolderNotFound) {
+		t.Errorf("CreateBookmark() into an unknown folder error = %v, want ErrFolderNotFound", err)
+	}
+
+	if _, err := s.EditFolder(ctx, parent.ID, "Work", &child.ID); !errors.Is(err, storage.ErrFolderCycle) {
+		t.Errorf("EditFolder() into its subfolder error = %v, want ErrFolderCycle", err)
+	}
+	if _, err := s.EditFolder(ctx, parent.ID, "Work", &parent.ID); !errors.Is(err, storage.ErrFolderCycle) {
+		t.Errorf("EditFolder() into itself error = %v, want ErrFolderCycle", err)
+	}
+
+	renamed, err := s.EditFolder(ctx, parent.ID, "Job", nil)
+	if err != nil {
+		t.Fatalf("EditFolder() error = %v", err)
+	}
+	if renamed.Name != "Job" {
+		t.Errorf("EditFolder() name = %q, want %q", renamed.Name, "Job")
+	}
+
+	imported, err := s.ImportFolder(ctx, "Docs", &parent.ID)
+	if err != nil {
+		t.Fatalf("ImportFolder() error = %v", err)
+	}
+	if imported.ID != child.ID {
+		t.Errorf("ImportFolder() = folder %d, want the existing folder %d", imported.ID, child.ID)
+	}
+
+	bm := createBookmark(t, ctx, s, "Go", "https://go.dev")
+	moved, err := s.MoveBookmark(ctx, bm.ID, &child.ID)
+	if err != nil {
+		t.Fatalf("MoveBookmark() error = %v", err)
+	}
+	if moved.FolderID == nil || *moved.FolderID != child.ID {
+		t.Errorf("MoveBookmark() folder = %v, want %d", moved.FolderID, child.ID)
+	}
+
+	page, err := s.GetBookmarks(ctx, storage.BookmarkFilter{Limit: 10, FolderID: &child.ID})
+	if err != nil {
+		t.Fatalf("GetBookmarks() error = %v", err)
+	}
+	if ids := bookmarkIDs(page.Bookmarks); !slices.Equal(ids, []int{bm.ID}) {
+		t.Errorf("GetBookmarks() of the folder = %v, want %v", ids, []int{bm.ID})
+	}
+
+	// Deleting a folder deletes its subfolders and moves their bookmarks to
+	// the root.
+	if err := s.DeleteFolder(ctx, parent.ID); err != nil {
+		t.Fatalf("DeleteFolder() error = %v", err)
+	}
+
+	folders, err := s.GetFolders(ctx)
+	if err != nil {
+		t.Fatalf("GetFolders() error = %v", err)
+	}
+	if len(folders) != 0 {
+		t.Errorf("GetFolders() = %d folders, want none", len(folders))
+	}
+
+	got, err := s.GetBookmark(ctx, bm.ID)
+	if err != nil {
+		t.Fatalf("GetBookmark() error = %v", err)
+	}
+	if got.FolderID != nil {
+		t.Errorf("GetBookmark() folder = %d, want none", *got.FolderID)
+	}
+
+	if err := s.DeleteFolder(ctx, parent.ID); !errors.Is(err, storage.ErrFolderNotFound) {
+		t.Errorf("DeleteFolder() of a deleted folder error = %v, want ErrFolderNotFound", err)
+	}
+}
+
+func testTrash(t *testing.T, s storage.Storage) {
+	ctx := userContext(t, s, "alice")
+
+	first := createBookmark(t, ctx, s, "Go", "https://go.dev")
+	second := createBookmark(t, ctx, s, "Rust", "https://rust-lang.org")
+	live := createBookmark(t, ctx, s, "Zig", "https://ziglang.org")
+
+	for _, bm := range []*model.Bookmark{first, second} {
+		if err := s.DeleteBookmark(ctx, bm.ID); err != nil {
+			t.Fatalf("DeleteBookmark() error = %v", err)
+		}
+	}
+
+	trash, err := s.GetTrash(ctx)
+	if err != nil {
+		t.Fatalf("GetTrash() error = %v", err)
+	}
+	if ids := bookmarkIDs(trash); !slices.Equal(ids, []int{second.ID, first.ID}) {
+		t.Errorf("GetTrash() = %v, want the latest deleted first", ids)
+	}
+	if len(trash) > 0 && trash[0].DeletedAt == nil {
+		t.Errorf("GetTrash() bookmark has no deletion time")
+	}
+
+	restored, err := s.RestoreBookmark(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("RestoreBookmark() error = %v", err)
+	}
+	if restored.DeletedAt != nil || restored.Title != "Rust" {
+		t.Errorf("RestoreBookmark() = %+v, want the live bookmark", restored)
+	}
+	if _, err := s.RestoreBookmark(ctx, live.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("RestoreBookmark() of a live bookmark error = %v, want ErrNotFound", err)
+	}
+
+	// The URL of a trashed bookmark can be bookmarked again, which blocks
+	// restoring it.
+	createBookmark(t, ctx, s, "Go again", "https://go.dev")
+	if _, err := s.RestoreBookmark(ctx, first.ID); !errors.Is(err, storage.ErrExists) {
+		t.Errorf("RestoreBookmark() of a bookmarked URL error = %v, want ErrExists", err)
+	}
+
+	if err := s.PurgeBookmark(ctx, live.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("PurgeBookmark() of a live bookmark error = %v, want ErrNotFound", err)
+	}
+	if err := s.PurgeBookmark(ctx, first.ID); err != nil {
+		t.Fatalf("PurgeBookmark() error = %v", err)
+	}
+
+	trash, err = s.GetTrash(ctx)
+	if err != nil {
+		t.Fatalf("GetTrash() error = %v", err)
+	}
+	if len(trash) != 0 {
+		t.Errorf("GetTrash() = %v, want it empty", bookmarkIDs(trash))
+	}
+
+	if err := s.DeleteBookmark(ctx, live.ID); err != nil {
+		t.Fatalf("DeleteBookmark() error = %v", err)
+	}
+
+	purged, err := s.PurgeTrash(context.Background(), time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("PurgeTrash() error = %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("PurgeTrash() = %d, want 1", purged)
+	}
+}
+
+func testHistory(t *testing.T, s storage.Storage) {
+	ctx := userContext(t, s, "alice")
+
+	bm, err := s.CreateBookmark(ctx, "Go", "https://go.dev", []string{"go"}, nil)
+	if err != nil {
+		t.Fatalf("CreateBookmark() error = %v", err)
+	}
+	if _, err := s.EditBookmark(ctx, bm.ID, "The Go language", bm.URL, []string{"go"}); err != nil {
+		t.Fatalf("EditBookmark() error = %v", err)
+	}
+
+	history, err := s.GetBookmarkHistory(ctx, bm.ID)
+	if err != nil {
+		t.Fatalf("GetBookmarkHistory() error = %v", err)
+	}
+	if actions := revisionActions(history); !slices.Equal(actions, []string{model.RevisionEdited, model.RevisionCreated}) {
+		t.Fatalf("GetBookmarkHistory() = %v, want the latest revision first", actions)
+	}
+
+	edit := history[0]
+	if edit.Revision != 2 || edit.Title != "The Go language" {
+		t.Errorf("GetBookmarkHistory() latest = revision %d %q, want revision 2 with the new title", edit.Revision, edit.Title)
+	}
+	if len(edit.Changes) != 1 || edit.Changes[0].Field != "title" {
+		t.Errorf("GetBookmarkHistory() changes = %+v, want only the title", edit.Changes)
+	}
+
+	reverted, err := s.RevertBookmark(ctx, bm.ID, 1)
+	if err != nil {
+		t.Fatalf("RevertBookmark() error = %v", err)
+	}
+	if reverted.Title != "Go" {
+		t.Errorf("RevertBookmark() title = %q, want %q", reverted.Title, "Go")
+	}
+
+	if _, err := s.RevertBookmark(ctx, bm.ID, 99); !errors.Is(err, storage.ErrRevisionNotFound) {
+		t.Errorf("RevertBookmark() to an unknown revision error = %v, want ErrRevisionNotFound", err)
+	}
+
+	if err := s.DeleteBookmark(ctx, bm.ID); err != nil {
+		t.Fatalf("DeleteBookmark() error = %v", err)
+	}
+	if _, err := s.RestoreBookmark(ctx, bm.ID); err != nil {
+		t.Fatalf("RestoreBookmark() error = %v", err)
+	}
+
+	history, err = s.GetBookmarkHistory(ctx, bm.ID)
+	if err != nil {
+		t.Fatalf("GetBookmarkHistory() error = %v", err)
+	}
+	want := []string{model.RevisionRestored, model.RevisionDeleted, model.RevisionReverted, model.RevisionEdited, model.RevisionCreated}
+	if actions := revisionActions(history); !slices.Equal(actions, want) {
+		t.Errorf("GetBookmarkHistory() = %v, want %v", actions, want)
+	}
+
+	if _, err := s.GetBookmarkHistory(ctx, 9999); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("GetBookmarkHistory() of an unknown bookmark error = %v, want ErrNotFound", err)
+	}
+}
+
+func testTokens(t *testing.T, s storage.Storage) {
+	ctx := userContext(t, s, "alice")
+	user, _ := auth.User(ctx)
+
+	cli, err := s.CreateToken(ctx, "cli", "cli-hash", []string{"bookmarks:read"})
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+	ci, err := s.CreateToken(ctx, "ci", "ci-hash", []string{"bookmarks:read", "bookmarks:write"})
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	tokens, err := s.GetTokens(ctx)
+	if err != nil {
+		t.Fatalf("GetTokens() error = %v", err)
+	}
+	if len(tokens) != 2 || tokens[0].ID != ci.ID || tokens[1].ID != cli.ID {
+		t.Errorf("GetTokens() = %v, want the latest token first", tokens)
+	}
+
+	owner, token, err := s.AuthenticateToken(context.Background(), "cli-hash")
+	if err != nil {
+		t.Fatalf("AuthenticateToken() error = %v", err)
+	}
+	if owner.ID != user.ID || token.ID != cli.ID || !slices.Equal(token.Scopes, cli.Scopes) {
+		t.Errorf("AuthenticateToken() = user %d, token %+v, want user %d and token %d", owner.ID, token, user.ID, cli.ID)
+	}
+
+	if _, _, err := s.AuthenticateToken(context.Background(), "unknown-hash"); !errors.Is(err, storage.ErrTokenNotFound) {
+		t.Errorf("AuthenticateToken() of an unknown token error = %v, want ErrTokenNotFound", err)
+	}
+
+	bob := userContext(t, s, "bob")
+	if err := s.RevokeToken(bob, cli.ID); !errors.Is(err, storage.ErrTokenNotFound) {
+		t.Errorf("RevokeToken() of another user's token error = %v, want ErrTokenNotFound", err)
+	}
+
+	if err := s.RevokeToken(ctx, cli.ID); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+	if _, _, err := s.AuthenticateToken(context.Background(), "cli-hash"); !errors.Is(err, storage.ErrTokenNotFound) {
+		t.Errorf("AuthenticateToken() of a revoked token error = %v, want ErrTokenNotFound", err)
+	}
+
+	tokens, err = s.GetTokens(ctx)
+	if err != nil {
+		t.Fatalf("GetTokens() error = %v", err)
+	}
+	for _, token := range tokens {
+		if token.ID == cli.ID && token.RevokedAt == nil {
+			t.Errorf("GetTokens() revoked token has no revocation time")
+		}
+	}
+}
+
+func testChanges(t *testing.T, s storage.Storage) {
+	ctx := userContext(t, s, "alice")
+
+	first := createBookmark(t, ctx, s, "Go", "https://go.dev")
+	second := createBookmark(t, ctx, s, "Rust", "https://rust-lang.org")
+
+	all, err := s.GetChanges(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("GetChanges() error = %v", err)
+	}
+	if ids := bookmarkIDs(all.Bookmarks); !slices.Equal(ids, []int{first.ID, second.ID}) || all.HasMore || all.Reset {
+		t.Errorf("GetChanges() = %v, more %v, reset %v, want both bookmarks", ids, all.HasMore, all.Reset)
+	}
+
+	limited, err := s.GetChanges(ctx, 0, 1)
+	if err != nil {
+		t.Fatalf("GetChanges() error = %v", err)
+	}
+	if len(limited.Bookmarks) != 1 || !limited.HasMore || limited.Since != first.Version {
+		t.Errorf("GetChanges() with limit 1 = %d bookmarks, more %v, since %d, want 1, true, %d",
+			len(limited.Bookmarks), limited.HasMore, limited.Since, first.Version)
+	}
+
+	if err := s.DeleteBookmark(ctx, first.ID); err != nil {
+		t.Fatalf("DeleteBookmark() error = %v", err)
+	}
+
+	changes, err := s.GetChanges(ctx, all.Since, 10)
+	if err != nil {
+		t.Fatalf("GetChanges() error = %v", err)
+	}
+	if len(changes.Bookmarks) != 1 || changes.Bookmarks[0].ID != first.ID || changes.Bookmarks[0].DeletedAt == nil {
+		t.Fatalf("GetChanges() = %v, want the deleted bookmark as a tombstone", bookmarkIDs(changes.Bookmarks))
+	}
+
+	unchanged, err := s.GetChanges(ctx, changes.Since, 10)
+	if err != nil {
+		t.Fatalf("GetChanges() error = %v", err)
+	}
+	if len(unchanged.Bookmarks) != 0 || unchanged.Since != changes.Since {
+		t.Errorf("GetChanges() = %v since %d, want nothing since %d", bookmarkIDs(unchanged.Bookmarks), unchanged.Since, changes.Since)
+	}
+
+	if err := s.PurgeBookmark(ctx, first.ID); err != nil {
+		t.Fatalf("PurgeBookmark() error = %v", err)
+	}
+
+	reset, err := s.GetChanges(ctx, all.Since, 10)
+	if err != nil {
+		t.Fatalf("GetChanges() error = %v", err)
+	}
+	if !reset.Reset || !slices.Equal(bookmarkIDs(reset.Bookmarks), []int{second.ID}) {
+		t.Errorf("GetChanges() after a purge = %v, reset %v, want a reset to the remaining bookmark",
+			bookmarkIDs(reset.Bookmarks), reset.Reset)
+	}
+}
+
+func testEvents(t *testing.T, s storage.Storage) {
+	ctx := userContext(t, s, "alice")
+	bob := userContext(t, s, "bob")
+
+	last, err := s.GetLastEventID(ctx)
+	if err != nil || last != 0 {
+		t.Errorf("GetLastEventID() = %d, %v, want 0, nil", last, err)
+	}
+
+	bm := createBookmark(t, ctx, s, "Go", "https://go.dev")
+	createBookmark(t, bob, s, "Rust", "https://rust-lang.org")
+	if _, err := s.EditBookmark(ctx, bm.ID, "The Go language", bm.URL, nil); err != nil {
+		t.Fatalf("EditBookmark() error = %v", err)
+	}
+	if err := s.DeleteBookmark(ctx, bm.ID); err != nil {
+		t.Fatalf("DeleteBookmark() error = %v", err)
+	}
+
+	events, err := s.GetEventsSince(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("GetEventsSince() error = %v", err)
+	}
+	want := []string{model.EventBookmarkCreated, model.EventBookmarkUpdated, model.EventBookmarkDeleted}
+	if names := eventNames(events); !slices.Equal(names, want) {
+		t.Fatalf("GetEventsSince() = %v, want %v", names, want)
+	}
+
+	last, err = s.GetLastEventID(ctx)
+	if err != nil || last != events[2].ID {
+		t.Errorf("GetLastEventID() = %d, %v, want %d, nil", last, err, events[2].ID)
+	}
+
+	rest, err := s.GetEventsSince(ctx, events[0].ID, 1)
+	if err != nil {
+		t.Fatalf("GetEventsSince() error = %v", err)
+	}
+	if names := eventNames(rest); !slices.Equal(names, want[1:2]) {
+		t.Errorf("GetEventsSince() with limit 1 = %v, want %v", names, want[1:2])
+	}
+}
+
+func testMetadata(t *testing.T, s storage.Storage) {
+	ctx := userContext(t, s, "alice")
+
+	// A bookmark titled with its URL takes the title of the page.
+	bm := createBookmark(t, ctx, s, "https://go.dev", "https://go.dev")
+	named := createBookmark(t, ctx, s, "Rust", "https://rust-lang.org")
+
+	after, err := s.GetLastEventID(ctx)
+	if err != nil {
+		t.Fatalf("GetLastEventID() error = %v", err)
+	}
+
+	metadata := model.Metadata{
+		Title:       "The Go programming language",
+		Description: "Build simple, secure, scalable systems",
+		ImageURL:    "https://go.dev/images/go-logo.png",
+		FaviconURL:  "https://go.dev/favicon.ico",
+	}
+	if err := s.SetBookmarkMetadata(context.Background(), bm.ID, metadata); err != nil {
+		t.Fatalf("SetBookmarkMetadata() error = %v", err)
+	}
+	if err := s.SetBookmarkMetadata(context.Background(), named.ID, model.Metadata{Title: "Rust language"}); err != nil {
+		t.Fatalf("SetBookmarkMetadata() error = %v", err)
+	}
+
+	got, err := s.GetBookmark(ctx, bm.ID)
+	if err != nil {
+		t.Fatalf("GetBookmark() error = %v", err)
+	}
+	if got.Title != metadata.Title || got.Description != metadata.Description ||
+		got.ImageURL != metadata.ImageURL || got.FaviconURL != metadata.FaviconURL {
+		t.Errorf("GetBookmark() = %+v, want the fetched metadata", got)
+	}
+	if got.MetadataFetchedAt == nil || got.Version <= bm.Version {
+		t.Errorf("GetBookmark() fetched at %v, version %d, want it fetched and above %d", got.MetadataFetchedAt, got.Version, bm.Version)
+	}
+
+	kept, err := s.GetBookmark(ctx, named.ID)
+	if err != nil {
+		t.Fatalf("GetBookmark() error = %v", err)
+	}
+	if kept.Title != "Rust" {
+		t.Errorf("GetBookmark() title = %q, want the given title kept", kept.Title)
+	}
+
+	history, err := s.GetBookmarkHistory(ctx, bm.ID)
+	if err != nil {
+		t.Fatalf("GetBookmarkHistory() error = %v", err)
+	}
+	if len(history) != 2 || history[0].Action != model.RevisionEdited || history[0].Title != metadata.Title {
+		t.Errorf("GetBookmarkHistory() = %v, want an edit to the fetched title", revisionActions(history))
+	}
+
+	history, err = s.GetBookmarkHistory(ctx, named.ID)
+	if err != nil {
+		t.Fatalf("GetBookmarkHistory() error = %v", err)
+	}
+	if len(history) != 1 {
+		t.Errorf("GetBookmarkHistory() = %v, want no revision for a kept title", revisionActions(history))
+	}
+
+	events, err := s.GetEventsSince(ctx, after, 10)
+	if err != nil {
+		t.Fatalf("GetEventsSince() error = %v", err)
+	}
+	// The second bookmark had no metadata and got none, nothing visible
+	// changed.
+	if names := eventNames(events); !slices.Equal(names, []string{model.EventBookmarkUpdated}) {
+		t.Errorf("GetEventsSince() = %v, want a single update", names)
+	}
+
+	if err := s.SetBookmarkMetadata(context.Background(), 9999, metadata); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("SetBookmarkMetadata() of an unknown bookmark error = %v, want ErrNotFound", err)
+	}
+}
+
+func testWebhooks(t *testing.T, s storage.Storage) {
+	ctx := userContext(t, s, "alice")
+	bob := userContext(t, s, "bob")
+
+	wh, err := s.CreateWebhook(ctx, "https://hooks.example.com", "secret", []string{model.EventBookmarkCreated})
+	if err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+	if wh.ID == 0 || !slices.Equal(wh.Events, []string{model.EventBookmarkCreated}) {
+		t.Errorf("CreateWebhook() = %+v, want a new webhook for created bookmarks", wh)
+	}
+
+	webhooks, err := s.GetWebhooks(bob)
+	if err != nil {
+		t.Fatalf("GetWebhooks() error = %v", err)
+	}
+	if len(webhooks) != 0 {
+		t.Errorf("GetWebhooks() = %v, want none of another user's webhooks", webhooks)
+	}
+
+	bm := createBookmark(t, ctx, s, "Go", "https://go.dev")
+	createBookmark(t, bob, s, "Rust", "https://rust-lang.org")
+	if err := s.DeleteBookmark(ctx, bm.ID); err != nil {
+		t.Fatalf("DeleteBookmark() error = %v", err)
+	}
+
+	dispatched, err := s.DispatchEvents(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("DispatchEvents() error = %v", err)
+	}
+	if dispatched != 3 {
+		t.Errorf("DispatchEvents() = %d, want 3", dispatched)
+	}
+
+	deliveries, err := s.GetWebhookDeliveries(ctx, wh.ID, 10)
+	if err != nil {
+		t.Fatalf("GetWebhookDeliveries() error = %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Event != model.EventBookmarkCreated {
+		t.Fatalf("GetWebhookDeliveries() = %v, want a single created event", deliveries)
+	}
+
+	claimed, err := s.ClaimDeliveries(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimDeliveries() error = %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != deliveries[0].ID {
+		t.Fatalf("ClaimDeliveries() = %v, want delivery %d", claimed, deliveries[0].ID)
+	}
+
+	again, err := s.ClaimDeliveries(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimDeliveries() error = %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("ClaimDeliveries() = %v, want leased deliveries skipped", again)
+	}
+
+	if _, err := s.ReplayDelivery(bob, wh.ID, deliveries[0].ID); !errors.Is(err, storage.ErrWebhookNotFound) {
+		t.Errorf("ReplayDelivery() of another user's webhook error = %v, want ErrWebhookNotFound", err)
+	}
+	if _, err := s.ReplayDelivery(ctx, wh.ID, 9999); !errors.Is(err, storage.ErrDeliveryNotFound) {
+		t.Errorf("ReplayDelivery() of an unknown delivery error = %v, want ErrDeliveryNotFound", err)
+	}
+
+	replay, err := s.ReplayDelivery(ctx, wh.ID, deliveries[0].ID)
+	if err != nil {
+		t.Fatalf("ReplayDelivery() error = %v", err)
+	}
+	if replay.ID == deliveries[0].ID || replay.EventID != deliveries[0].EventID {
+		t.Errorf("ReplayDelivery() = %+v, want a new delivery of event %d", replay, deliveries[0].EventID)
+	}
+
+	if err := s.DeleteWebhook(bob, wh.ID); !errors.Is(err, storage.ErrWebhookNotFound) {
+		t.Errorf("DeleteWebhook() of another user's webhook error = %v, want ErrWebhookNotFound", err)
+	}
+	if err := s.DeleteWebhook(ctx, wh.ID); err != nil {
+		t.Fatalf("DeleteWebhook() error = %v", err)
+	}
+
+	webhooks, err = s.GetWebhooks(ctx)
+	if err != nil {
+		t.Fatalf("GetWebhooks() error = %v", err)
+	}
+	if len(webhooks) != 0 {
+		t.Errorf("GetWebhooks() = %v, want none", webhooks)
+	}
+}
+
+// userContext creates a user with the given name and returns a context
+// carrying it.
+func userContext(t *testing.T, s storage.Storage, username string) context.Context {
+	t.Helper()
+
+	user, err := s.CreateUser(context.Background(), username, "hash")
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	return auth.WithUser(context.Background(), user)
+}
+
+func createBookmark(t *testing.T, ctx context.Context, s storage.Storage, title, url string) *model.Bookmark {
+	t.Helper()
+
+	bm, err := s.CreateBookmark(ctx, title, url, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateBookmark() error = %v", err)
+	}
+
+	return bm
+}
+
+func bookmarkIDs(bookmarks []*model.Bookmark) []int {
+	ids := []int{}
+	for _, bm := range bookmarks {
+		ids = append(ids, bm.ID)
+	}
+
+	return ids
+}
+
+func revisionActions(revisions []*model.Revision) []string {
+	actions := []string{}
+	for _, r := range revisions {
+		actions = append(actions, r.Action)
+	}
+
+	return actions
+}
+
+func eventNames(events []*model.StoredEvent) []string {
+	names := []string{}
+	for _, e := range events {
+		names = append(names, e.Event)
+	}
+
+	return names
+}
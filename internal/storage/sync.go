@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/lib/pq"
+)
+
+// GetChanges returns up to limit bookmarks of the user changed after the
+// given change sequence number, trashed ones included as tombstones. When
+// bookmarks were purged since then, the client has to start over: Reset is
+// set and changes are listed from the beginning.
+func (s *PostgresStorage) GetChanges(ctx context.Context, since int64, limit int) (*model.ChangeSet, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &model.ChangeSet{}
+
+	if since > 0 {
+		var resetSeq int64
+
+		err := sq.
+			Select("change_seq").
+			From("sync_resets").
+			Where(sq.Eq{"user_id": userID}).
+			PlaceholderFormat(sq.Dollar).
+			RunWith(s.db).
+			QueryRowContext(ctx).
+			Scan(&resetSeq)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get sync reset: %w", err)
+		}
+
+		if since < resetSeq {
+			changes.Reset = true
+			since = 0
+		}
+	}
+
+	stmt := sq.
+		Select(append(bookmarkColumns, bookmarkTagsColumn)...).
+		From("bookmarks").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Gt{"change_seq": since}).
+		OrderBy("change_seq").
+		Limit(uint64(limit) + 1).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	bookmarks := []*model.Bookmark{}
+	for rows.Next() {
+		var bm model.Bookmark
+
+		if err := rows.Scan(append(bookmarkFields(&bm), pq.Array(&bm.Tags))...); err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark: %w", err)
+		}
+
+		bookmarks = append(bookmarks, &bm)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate changes rows: %w", err)
+	}
+
+	changes.HasMore = len(bookmarks) > limit
+	if changes.HasMore {
+		bookmarks = bookmarks[:limit]
+	}
+
+	changes.Bookmarks = bookmarks
+	changes.Since = since
+	if len(bookmarks) > 0 {
+		changes.Since = bookmarks[len(bookmarks)-1].Version
+	}
+
+	return changes, nil
+}
+
+// missingOrConflict tells why a versioned change matched no bookmark. With
+// no version given, the bookmark can only be missing.
+func missingOrConflict(ctx context.Context, runner sq.BaseRunner, userID, id int, version int64) error {
+	if version == 0 {
+		return ErrNotFound
+	}
+
+	var found bool
+
+	err := sq.
+		Select("true").
+		From("bookmarks").
+		Where(sq.Eq{"id": id, "user_id": userID, "deleted_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(runner).
+		QueryRowContext(ctx).
+		Scan(&found)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+
+		return fmt.Errorf("failed to find bookmark: %w", err)
+	}
+
+	return ErrConflict
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/lib/pq"
+)
+
+// tokenUsageResolution limits how often last_used_at is written, so that
+// busy clients don't turn every read into a write.
+const tokenUsageResolution = time.Minute
+
+func (s *PostgresStorage) GetTokens(ctx context.Context) ([]*model.Token, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select("id", "name", "scopes", "created_at", "last_used_at", "revoked_at").
+		From("api_tokens").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at DESC").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tokens rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	tokens := []*model.Token{}
+	for rows.Next() {
+		var t model.Token
+
+		if err := rows.Scan(&t.ID, &t.Name, pq.Array(&t.Scopes), &t.CreatedAt, &t.LastUsedAt, &t.RevokedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan token: %w", err)
+		}
+
+		tokens = append(tokens, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tokens rows: %w", err)
+	}
+
+	return tokens, nil
+}
+
+func (s *PostgresStorage) CreateToken(ctx context.Context, name, tokenHash string, scopes []string) (*model.Token, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Insert("api_tokens").
+		Columns("user_id", "name", "token_hash", "scopes").
+		Values(userID, name, tokenHash, pq.Array(scopes)).
+		Suffix("RETURNING id, name, scopes, created_at, last_used_at, revoked_at").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	var t model.Token
+	if err := stmt.QueryRowContext(ctx).Scan(&t.ID, &t.Name, pq.Array(&t.Scopes), &t.CreatedAt, &t.LastUsedAt, &t.RevokedAt); err != nil {
+		return nil, fmt.Errorf("failed to create token: %w", err)
+	}
+
+	return &t, nil
+}
+
+func (s *PostgresStorage) RevokeToken(ctx context.Context, id int) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	stmt := sq.
+		Update("api_tokens").
+		Set("revoked_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": id, "user_id": userID, "revoked_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	result, err := stmt.ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get check revocation: %w", err)
+	}
+
+	if rowAffected == 0 {
+		return ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// AuthenticateToken finds the owner of an active token by the token hash and
+// records that the token was used.
+func (s *PostgresStorage) AuthenticateToken(ctx context.Context, tokenHash string) (*model.User, *model.Token, error) {
+	stmt := sq.
+		Select(
+			"u.id", "u.username", "u.password_hash", "u.created_at",
+			"t.id", "t.name", "t.scopes", "t.created_at", "t.last_used_at", "t.revoked_at",
+		).
+		From("api_tokens t").
+		Join("users u ON u.id = t.user_id").
+		Where(sq.Eq{"t.token_hash": tokenHash, "t.revoked_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	var u model.User
+	var t model.Token
+	err := stmt.QueryRowContext(ctx).Scan(
+		&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt,
+		&t.ID, &t.Name, pq.Array(&t.Scopes), &t.CreatedAt, &t.LastUsedAt, &t.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrTokenNotFound
+		}
+
+		return nil, nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	if t.LastUsedAt == nil || time.Since(*t.LastUsedAt) > tokenUsageResolution {
+		now := time.Now()
+
+		_, err := sq.
+			Update("api_tokens").
+			Set("last_used_at", now).
+			Where(sq.Eq{"id": t.ID}).
+			PlaceholderFormat(sq.Dollar).
+			RunWith(s.db).
+			ExecContext(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update token usage: %w", err)
+		}
+
+		t.LastUsedAt = &now
+	}
+
+	return &u, &t, nil
+}
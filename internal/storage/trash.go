@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/lib/pq"
+)
+
+// GetTrash returns the deleted bookmarks of the user, most recently deleted first.
+func (s *PostgresStorage) GetTrash(ctx context.Context) ([]*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select(append(bookmarkColumns, bookmarkTagsColumn)...).
+		From("bookmarks").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.NotEq{"deleted_at": nil}).
+		OrderBy("deleted_at DESC", "id DESC").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	bookmarks := []*model.Bookmark{}
+	for rows.Next() {
+		var bm model.Bookmark
+
+		if err := rows.Scan(append(bookmarkFields(&bm), pq.Array(&bm.Tags))...); err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark: %w", err)
+		}
+
+		bookmarks = append(bookmarks, &bm)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate trash rows: %w", err)
+	}
+
+	return bookmarks, nil
+}
+
+// RestoreBookmark takes the bookmark out of the trash. It fails with
+// ErrExists when the URL was saved again in the meantime.
+func (s *PostgresStorage) RestoreBookmark(ctx context.Context, id int) (*model.Bookmark, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt := sq.
+		Update("bookmarks").
+		Set("deleted_at", nil).
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		Where(sq.NotEq{"deleted_at": nil}).
+		Suffix(bookmarkReturning).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx)
+
+	var bm model.Bookmark
+	if err := stmt.QueryRowContext(ctx).Scan(bookmarkFields(&bm)...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if isPqError(err, uniqueViolation) {
+			return nil, ErrExists
+		}
+
+		return nil, fmt.Errorf("failed to restore bookmark: %w", err)
+	}
+
+	tags, err := getBookmarkTags(ctx, tx, bm.ID)
+	if err != nil {
+		return nil, err
+	}
+	bm.Tags = tags
+
+	if err := recordRevision(ctx, tx, bm.ID, model.RevisionRestored); err != nil {
+		return nil, err
+	}
+
+	if err := recordEvent(ctx, tx, userID, model.EventBookmarkUpdated, &bm); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &bm, nil
+}
+
+// PurgeBookmark permanently deletes a bookmark from the trash.
+func (s *PostgresStorage) PurgeBookmark(ctx context.Context, id int) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	stmt := sq.
+		Delete("bookmarks").
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		Where(sq.NotEq{"deleted_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	result, err := stmt.ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to purge bookmark: %w", err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get check purge: %w", err)
+	}
+
+	if rowAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// PurgeTrash permanently deletes bookmarks of all users that were moved to
+// the trash before the given time and returns how many were deleted.
+func (s *PostgresStorage) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	stmt := sq.
+		Delete("bookmarks").
+		Where(sq.Lt{"deleted_at": deletedBefore}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	result, err := stmt.ExecContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get check purge: %w", err)
+	}
+
+	return purged, nil
+}
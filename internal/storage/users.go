@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+func (s *PostgresStorage) CreateUser(ctx context.Context, username, passwordHash string) (*model.User, error) {
+	stmt := sq.
+		Insert("users").
+		Columns("username", "password_hash").
+		Values(username, passwordHash).
+		Suffix("RETURNING id, username, password_hash, created_at").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	var u model.User
+	if err := stmt.QueryRowContext(ctx).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt); err != nil {
+		if isPqError(err, uniqueViolation) {
+			return nil, ErrUserExists
+		}
+
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return &u, nil
+}
+
+func (s *PostgresStorage) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
+	stmt := sq.
+		Select("id", "username", "password_hash", "created_at").
+		From("users").
+		Where(sq.Eq{"username": username}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	var u model.User
+	if err := stmt.QueryRowContext(ctx).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &u, nil
+}
+
+// GetUsers returns all users in the order they signed up.
+func (s *PostgresStorage) GetUsers(ctx context.Context) ([]*model.User, error) {
+	stmt := sq.
+		Select("id", "username", "password_hash", "created_at").
+		From("users").
+		OrderBy("id").
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	users := []*model.User{}
+	for rows.Next() {
+		var u model.User
+
+		if err := rows.Scan(&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		users = append(users, &u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate users rows: %w", err)
+	}
+
+	return users, nil
+}
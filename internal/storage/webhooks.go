@@ -0,0 +1,410 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/lib/pq"
+)
+
+var deliveryColumns = []string{
+	"d.id", "d.webhook_id", "d.event_id", "e.event", "d.status", "d.attempts",
+	"d.next_attempt_at", "d.last_attempt_at", "d.response_status", "d.error", "d.created_at",
+}
+
+// deliveryFields returns scan destinations matching deliveryColumns.
+func deliveryFields(d *model.WebhookDelivery) []any {
+	return []any{
+		&d.ID, &d.WebhookID, &d.EventID, &d.Event, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastAttemptAt, &d.ResponseStatus, &d.Error, &d.CreatedAt,
+	}
+}
+
+func (s *PostgresStorage) GetWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select("id", "url", "events", "created_at").
+		From("webhooks").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at DESC").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	webhooks := []*model.Webhook{}
+	for rows.Next() {
+		var wh model.Webhook
+
+		if err := rows.Scan(&wh.ID, &wh.URL, pq.Array(&wh.Events), &wh.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+
+		webhooks = append(webhooks, &wh)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhooks rows: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (s *PostgresStorage) CreateWebhook(ctx context.Context, url, secret string, events []string) (*model.Webhook, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Insert("webhooks").
+		Columns("user_id", "url", "secret", "events").
+		Values(userID, url, secret, pq.Array(events)).
+		Suffix("RETURNING id, url, events, created_at").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	var wh model.Webhook
+	if err := stmt.QueryRowContext(ctx).Scan(&wh.ID, &wh.URL, pq.Array(&wh.Events), &wh.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return &wh, nil
+}
+
+func (s *PostgresStorage) DeleteWebhook(ctx context.Context, id int) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	stmt := sq.
+		Delete("webhooks").
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	result, err := stmt.ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get check deletion: %w", err)
+	}
+
+	if rowAffected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries returns the delivery log of the webhook, newest first.
+func (s *PostgresStorage) GetWebhookDeliveries(ctx context.Context, webhookID, limit int) ([]*model.WebhookDelivery, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkWebhookOwner(ctx, s.db, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	stmt := sq.
+		Select(deliveryColumns...).
+		From("webhook_deliveries d").
+		Join("outbox_events e ON e.id = d.event_id").
+		Where(sq.Eq{"d.webhook_id": webhookID}).
+		OrderBy("d.id DESC").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deliveries rows: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	deliveries := []*model.WebhookDelivery{}
+	for rows.Next() {
+		var d model.WebhookDelivery
+
+		if err := rows.Scan(deliveryFields(&d)...); err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+
+		deliveries = append(deliveries, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate deliveries rows: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ReplayDelivery schedules the event of a past delivery to be sent again. The
+// original delivery is kept in the log untouched.
+func (s *PostgresStorage) ReplayDelivery(ctx context.Context, webhookID int, deliveryID int64) (*model.WebhookDelivery, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkWebhookOwner(ctx, s.db, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		WITH d AS (
+			INSERT INTO webhook_deliveries (webhook_id, event_id)
+			SELECT webhook_id, event_id FROM webhook_deliveries
+			WHERE id = $1 AND webhook_id = $2
+			RETURNING *
+		)
+		SELECT `+joinColumns(deliveryColumns)+`
+		FROM d
+		JOIN outbox_events e ON e.id = d.event_id`,
+		deliveryID, webhookID,
+	)
+
+	var d model.WebhookDelivery
+	if err := row.Scan(deliveryFields(&d)...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeliveryNotFound
+		}
+
+		return nil, fmt.Errorf("failed to replay delivery: %w", err)
+	}
+
+	return &d, nil
+}
+
+// DispatchEvents fans out up to limit pending outbox events into deliveries
+// for the webhooks subscribed to them and returns how many events it handled.
+func (s *PostgresStorage) DispatchEvents(ctx context.Context, limit int) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM outbox_events
+		WHERE dispatched_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`,
+		limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get outbox events: %w", err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate outbox events: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event_id)
+		SELECT w.id, e.id
+		FROM outbox_events e
+		JOIN webhooks w ON w.user_id = e.user_id AND e.event = ANY(w.events)
+		WHERE e.id = ANY($1)
+		ORDER BY e.id`,
+		pq.Array(ids),
+	); err != nil {
+		return 0, fmt.Errorf("failed to create deliveries: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE outbox_events SET dispatched_at = NOW() WHERE id = ANY($1)",
+		pq.Array(ids),
+	); err != nil {
+		return 0, fmt.Errorf("failed to mark outbox events dispatched: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(ids), nil
+}
+
+// ClaimDeliveries returns up to limit deliveries that are due, pushing their
+// next attempt by lease so that concurrent workers don't pick them up twice.
+func (s *PostgresStorage) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $2
+		FROM webhooks w, outbox_events e
+		WHERE d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $3 AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		AND w.id = d.webhook_id AND e.id = d.event_id
+		RETURNING `+joinColumns(deliveryColumns)+`, w.url, w.secret, e.payload`,
+		limit, time.Now().Add(lease), model.DeliveryPending,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim deliveries: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	deliveries := []*model.WebhookDelivery{}
+	for rows.Next() {
+		var d model.WebhookDelivery
+
+		if err := rows.Scan(append(deliveryFields(&d), &d.URL, &d.Secret, &d.Payload)...); err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+
+		deliveries = append(deliveries, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate deliveries rows: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// SetDeliveryResult records the outcome of a delivery attempt.
+func (s *PostgresStorage) SetDeliveryResult(ctx context.Context, id int64, result model.DeliveryResult) error {
+	status := model.DeliveryPending
+	switch {
+	case result.Succeeded:
+		status = model.DeliverySucceeded
+	case result.NextAttemptAt == nil:
+		status = model.DeliveryFailed
+	}
+
+	stmt := sq.
+		Update("webhook_deliveries").
+		Set("status", status).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_attempt_at", sq.Expr("NOW()")).
+		Set("response_status", result.ResponseStatus).
+		Set("error", result.Error).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(s.db)
+
+	if result.NextAttemptAt != nil {
+		stmt = stmt.Set("next_attempt_at", *result.NextAttemptAt)
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("failed to set delivery result: %w", err)
+	}
+
+	return nil
+}
+
+// changesLock is the advisory lock serializing the changes of a user. The
+// change_seq trigger of the bookmarks table takes the same lock, so a
+// transaction changing a bookmark and recording its event holds just one.
+const changesLock = "bookmark_changes"
+
+// recordEvent writes a bookmark lifecycle event to the outbox. It has to run
+// in the transaction of the change it describes.
+func recordEvent(ctx context.Context, tx *sql.Tx, userID int, event string, data any) error {
+	payload, err := json.Marshal(model.Event{
+		Event:      event,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	// The lock is held until the transaction ends, so a concurrent event of
+	// the user takes its id only after this one is committed or rolled
+	// back. Event ids of a user become visible in increasing order, which
+	// GetEventsSince relies on.
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1), $2)", changesLock, userID); err != nil {
+		return fmt.Errorf("failed to lock user changes: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO outbox_events (user_id, event, payload) VALUES ($1, $2, $3)",
+		userID, event, payload,
+	); err != nil {
+		return fmt.Errorf("failed to record event: %w", err)
+	}
+
+	return nil
+}
+
+// checkWebhookOwner makes sure the webhook belongs to the user.
+func checkWebhookOwner(ctx context.Context, runner sq.BaseRunner, userID, webhookID int) error {
+	var found bool
+
+	err := sq.
+		Select("true").
+		From("webhooks").
+		Where(sq.Eq{"id": webhookID, "user_id": userID}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(runner).
+		QueryRowContext(ctx).
+		Scan(&found)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrWebhookNotFound
+		}
+
+		return fmt.Errorf("failed to find webhook: %w", err)
+	}
+
+	return nil
+}
+
+func joinColumns(columns []string) string {
+	return strings.Join(columns, ", ")
+}
@@ -0,0 +1,170 @@
+// Package sqltrace traces the statements run through a database/sql driver.
+package sqltrace
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/haadi-coder/bookmark-manager/internal/tracing/sqltrace"
+
+// WrapConnector traces the queries and transactions run on connections of
+// the connector, with the SQL statement as an attribute. Queries outside of
+// a traced context start no span.
+func WrapConnector(connector driver.Connector, system string) driver.Connector {
+	return &tracedConnector{
+		Connector: connector,
+		attrs:     []attribute.KeyValue{attribute.String("db.system.name", system)},
+	}
+}
+
+type tracedConnector struct {
+	driver.Connector
+	attrs []attribute.KeyValue
+}
+
+func (c *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tracedConn{Conn: conn, attrs: c.attrs}, nil
+}
+
+type tracedConn struct {
+	driver.Conn
+	attrs []attribute.KeyValue
+}
+
+func (c *tracedConn) startSpan(ctx context.Context, name, query string) (context.Context, trace.Span, bool) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, nil, false
+	}
+
+	attrs := c.attrs
+	if query != "" {
+		attrs = append(attrs[:len(attrs):len(attrs)], attribute.String("db.query.text", query))
+	}
+
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+
+	return ctx, span, true
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span, traced := c.startSpan(ctx, "sql.exec", query)
+	result, err := execer.ExecContext(ctx, query, args)
+	if traced {
+		end(span, skipErr(err))
+	}
+
+	return result, err
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span, traced := c.startSpan(ctx, "sql.query", query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if traced {
+		end(span, skipErr(err))
+	}
+
+	return rows, err
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+
+	return c.Conn.Prepare(query)
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	ctx, span, traced := c.startSpan(ctx, "sql.begin", "")
+
+	var tx driver.Tx
+	var err error
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(ctx, opts)
+	} else {
+		//nolint:staticcheck // Fallback for drivers without BeginTx.
+		tx, err = c.Conn.Begin()
+	}
+
+	if traced {
+		end(span, err)
+	}
+
+	return tx, err
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *tracedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+
+	return true
+}
+
+func (c *tracedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+// skipErr hides driver.ErrSkip, which only makes database/sql fall back to
+// a prepared statement.
+func skipErr(err error) error {
+	if errors.Is(err, driver.ErrSkip) {
+		return nil
+	}
+
+	return err
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,337 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+var _ storage.Storage = (*Storage)(nil)
+
+// Storage starts a span for every storage operation. The queries it runs
+// show up as child spans when the database connector is traced too.
+type Storage struct {
+	storage.Storage
+}
+
+// WrapStorage instruments the given storage.
+func WrapStorage(s storage.Storage) *Storage {
+	return &Storage{Storage: s}
+}
+
+func (s *Storage) GetBookmarks(ctx context.Context, filter storage.BookmarkFilter) (_ *storage.BookmarkPage, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetBookmarks")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetBookmarks(ctx, filter)
+}
+
+func (s *Storage) GetBookmark(ctx context.Context, id int) (_ *model.Bookmark, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetBookmark")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetBookmark(ctx, id)
+}
+
+func (s *Storage) CreateBookmark(ctx context.Context, title, url string, tags []string, folderID *int) (_ *model.Bookmark, err error) {
+	ctx, span := tracer().Start(ctx, "storage.CreateBookmark")
+	defer func() { end(span, err) }()
+
+	return s.Storage.CreateBookmark(ctx, title, url, tags, folderID)
+}
+
+func (s *Storage) ImportBookmark(ctx context.Context, bookmark *model.Bookmark) (_ *model.Bookmark, err error) {
+	ctx, span := tracer().Start(ctx, "storage.ImportBookmark")
+	defer func() { end(span, err) }()
+
+	return s.Storage.ImportBookmark(ctx, bookmark)
+}
+
+func (s *Storage) EditBookmark(ctx context.Context, id int, title, url string, tags []string) (_ *model.Bookmark, err error) {
+	ctx, span := tracer().Start(ctx, "storage.EditBookmark")
+	defer func() { end(span, err) }()
+
+	return s.Storage.EditBookmark(ctx, id, title, url, tags)
+}
+
+func (s *Storage) EditBookmarkIfUnchanged(ctx context.Context, id int, version int64, title, url string, tags []string) (_ *model.Bookmark, err error) {
+	ctx, span := tracer().Start(ctx, "storage.EditBookmarkIfUnchanged")
+	defer func() { end(span, err) }()
+
+	return s.Storage.EditBookmarkIfUnchanged(ctx, id, version, title, url, tags)
+}
+
+func (s *Storage) DeleteBookmark(ctx context.Context, id int) (err error) {
+	ctx, span := tracer().Start(ctx, "storage.DeleteBookmark")
+	defer func() { end(span, err) }()
+
+	return s.Storage.DeleteBookmark(ctx, id)
+}
+
+func (s *Storage) DeleteBookmarkIfUnchanged(ctx context.Context, id int, version int64) (err error) {
+	ctx, span := tracer().Start(ctx, "storage.DeleteBookmarkIfUnchanged")
+	defer func() { end(span, err) }()
+
+	return s.Storage.DeleteBookmarkIfUnchanged(ctx, id, version)
+}
+
+func (s *Storage) BookmarkExist(ctx context.Context, url string) (_ int, _ bool, err error) {
+	ctx, span := tracer().Start(ctx, "storage.BookmarkExist")
+	defer func() { end(span, err) }()
+
+	return s.Storage.BookmarkExist(ctx, url)
+}
+
+func (s *Storage) MoveBookmark(ctx context.Context, id int, folderID *int) (_ *model.Bookmark, err error) {
+	ctx, span := tracer().Start(ctx, "storage.MoveBookmark")
+	defer func() { end(span, err) }()
+
+	return s.Storage.MoveBookmark(ctx, id, folderID)
+}
+
+func (s *Storage) GetTags(ctx context.Context) (_ []*model.Tag, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetTags")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetTags(ctx)
+}
+
+func (s *Storage) GetFolders(ctx context.Context) (_ []*model.Folder, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetFolders")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetFolders(ctx)
+}
+
+func (s *Storage) CreateFolder(ctx context.Context, name string, parentID *int) (_ *model.Folder, err error) {
+	ctx, span := tracer().Start(ctx, "storage.CreateFolder")
+	defer func() { end(span, err) }()
+
+	return s.Storage.CreateFolder(ctx, name, parentID)
+}
+
+func (s *Storage) ImportFolder(ctx context.Context, name string, parentID *int) (_ *model.Folder, err error) {
+	ctx, span := tracer().Start(ctx, "storage.ImportFolder")
+	defer func() { end(span, err) }()
+
+	return s.Storage.ImportFolder(ctx, name, parentID)
+}
+
+func (s *Storage) EditFolder(ctx context.Context, id int, name string, parentID *int) (_ *model.Folder, err error) {
+	ctx, span := tracer().Start(ctx, "storage.EditFolder")
+	defer func() { end(span, err) }()
+
+	return s.Storage.EditFolder(ctx, id, name, parentID)
+}
+
+func (s *Storage) DeleteFolder(ctx context.Context, id int) (err error) {
+	ctx, span := tracer().Start(ctx, "storage.DeleteFolder")
+	defer func() { end(span, err) }()
+
+	return s.Storage.DeleteFolder(ctx, id)
+}
+
+func (s *Storage) CreateUser(ctx context.Context, username, passwordHash string) (_ *model.User, err error) {
+	ctx, span := tracer().Start(ctx, "storage.CreateUser")
+	defer func() { end(span, err) }()
+
+	return s.Storage.CreateUser(ctx, username, passwordHash)
+}
+
+func (s *Storage) GetUserByUsername(ctx context.Context, username string) (_ *model.User, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetUserByUsername")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetUserByUsername(ctx, username)
+}
+
+func (s *Storage) GetTokens(ctx context.Context) (_ []*model.Token, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetTokens")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetTokens(ctx)
+}
+
+func (s *Storage) CreateToken(ctx context.Context, name, tokenHash string, scopes []string) (_ *model.Token, err error) {
+	ctx, span := tracer().Start(ctx, "storage.CreateToken")
+	defer func() { end(span, err) }()
+
+	return s.Storage.CreateToken(ctx, name, tokenHash, scopes)
+}
+
+func (s *Storage) RevokeToken(ctx context.Context, id int) (err error) {
+	ctx, span := tracer().Start(ctx, "storage.RevokeToken")
+	defer func() { end(span, err) }()
+
+	return s.Storage.RevokeToken(ctx, id)
+}
+
+func (s *Storage) AuthenticateToken(ctx context.Context, tokenHash string) (_ *model.User, _ *model.Token, err error) {
+	ctx, span := tracer().Start(ctx, "storage.AuthenticateToken")
+	defer func() { end(span, err) }()
+
+	return s.Storage.AuthenticateToken(ctx, tokenHash)
+}
+
+func (s *Storage) GetTrash(ctx context.Context) (_ []*model.Bookmark, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetTrash")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetTrash(ctx)
+}
+
+func (s *Storage) RestoreBookmark(ctx context.Context, id int) (_ *model.Bookmark, err error) {
+	ctx, span := tracer().Start(ctx, "storage.RestoreBookmark")
+	defer func() { end(span, err) }()
+
+	return s.Storage.RestoreBookmark(ctx, id)
+}
+
+func (s *Storage) PurgeBookmark(ctx context.Context, id int) (err error) {
+	ctx, span := tracer().Start(ctx, "storage.PurgeBookmark")
+	defer func() { end(span, err) }()
+
+	return s.Storage.PurgeBookmark(ctx, id)
+}
+
+func (s *Storage) GetBookmarkHistory(ctx context.Context, id int) (_ []*model.Revision, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetBookmarkHistory")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetBookmarkHistory(ctx, id)
+}
+
+func (s *Storage) RevertBookmark(ctx context.Context, id, revision int) (_ *model.Bookmark, err error) {
+	ctx, span := tracer().Start(ctx, "storage.RevertBookmark")
+	defer func() { end(span, err) }()
+
+	return s.Storage.RevertBookmark(ctx, id, revision)
+}
+
+func (s *Storage) GetLinkReport(ctx context.Context) (_ *model.LinkReport, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetLinkReport")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetLinkReport(ctx)
+}
+
+func (s *Storage) GetWebhooks(ctx context.Context) (_ []*model.Webhook, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetWebhooks")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetWebhooks(ctx)
+}
+
+func (s *Storage) CreateWebhook(ctx context.Context, url, secret string, events []string) (_ *model.Webhook, err error) {
+	ctx, span := tracer().Start(ctx, "storage.CreateWebhook")
+	defer func() { end(span, err) }()
+
+	return s.Storage.CreateWebhook(ctx, url, secret, events)
+}
+
+func (s *Storage) DeleteWebhook(ctx context.Context, id int) (err error) {
+	ctx, span := tracer().Start(ctx, "storage.DeleteWebhook")
+	defer func() { end(span, err) }()
+
+	return s.Storage.DeleteWebhook(ctx, id)
+}
+
+func (s *Storage) GetWebhookDeliveries(ctx context.Context, webhookID, limit int) (_ []*model.WebhookDelivery, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetWebhookDeliveries")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetWebhookDeliveries(ctx, webhookID, limit)
+}
+
+func (s *Storage) ReplayDelivery(ctx context.Context, webhookID int, deliveryID int64) (_ *model.WebhookDelivery, err error) {
+	ctx, span := tracer().Start(ctx, "storage.ReplayDelivery")
+	defer func() { end(span, err) }()
+
+	return s.Storage.ReplayDelivery(ctx, webhookID, deliveryID)
+}
+
+func (s *Storage) GetEventsSince(ctx context.Context, afterID int64, limit int) (_ []*model.StoredEvent, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetEventsSince")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetEventsSince(ctx, afterID, limit)
+}
+
+func (s *Storage) GetLastEventID(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetLastEventID")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetLastEventID(ctx)
+}
+
+func (s *Storage) GetChanges(ctx context.Context, since int64, limit int) (_ *model.ChangeSet, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetChanges")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetChanges(ctx, since, limit)
+}
+
+func (s *Storage) GetUsers(ctx context.Context) (_ []*model.User, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetUsers")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetUsers(ctx)
+}
+
+func (s *Storage) GetStats(ctx context.Context) (_ *model.Stats, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetStats")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetStats(ctx)
+}
+
+func (s *Storage) SetBookmarkMetadata(ctx context.Context, id int, metadata model.Metadata) (err error) {
+	ctx, span := tracer().Start(ctx, "storage.SetBookmarkMetadata")
+	defer func() { end(span, err) }()
+
+	return s.Storage.SetBookmarkMetadata(ctx, id, metadata)
+}
+
+func (s *Storage) GetBookmarksToCheck(ctx context.Context, checkedBefore time.Time, limit int) (_ []*model.Bookmark, err error) {
+	ctx, span := tracer().Start(ctx, "storage.GetBookmarksToCheck")
+	defer func() { end(span, err) }()
+
+	return s.Storage.GetBookmarksToCheck(ctx, checkedBefore, limit)
+}
+
+func (s *Storage) SetLinkCheck(ctx context.Context, id int, check model.LinkCheck) (err error) {
+	ctx, span := tracer().Start(ctx, "storage.SetLinkCheck")
+	defer func() { end(span, err) }()
+
+	return s.Storage.SetLinkCheck(ctx, id, check)
+}
+
+func (s *Storage) PurgeTrash(ctx context.Context, deletedBefore time.Time) (_ int64, err error) {
+	ctx, span := tracer().Start(ctx, "storage.PurgeTrash")
+	defer func() { end(span, err) }()
+
+	return s.Storage.PurgeTrash(ctx, deletedBefore)
+}
+
+func (s *Storage) DispatchEvents(ctx context.Context, limit int) (_ int, err error) {
+	ctx, span := tracer().Start(ctx, "storage.DispatchEvents")
+	defer func() { end(span, err) }()
+
+	return s.Storage.DispatchEvents(ctx, limit)
+}
+
+func (s *Storage) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) (_ []*model.WebhookDelivery, err error) {
+	ctx, span := tracer().Start(ctx, "storage.ClaimDeliveries")
+	defer func() { end(span, err) }()
+
+	return s.Storage.ClaimDeliveries(ctx, limit, lease)
+}
+
+func (s *Storage) SetDeliveryResult(ctx context.Context, id int64, result model.DeliveryResult) (err error) {
+	ctx, span := tracer().Start(ctx, "storage.SetDeliveryResult")
+	defer func() { end(span, err) }()
+
+	return s.Storage.SetDeliveryResult(ctx, id, result)
+}
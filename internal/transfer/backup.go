@@ -0,0 +1,114 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+// BackupVersion is bumped whenever the backup layout changes in a way older
+// restores can't read.
+const BackupVersion = 1
+
+type BackupStorage interface {
+	Exporter
+	GetUsers(ctx context.Context) ([]*model.User, error)
+}
+
+type RestoreStorage interface {
+	Importer
+	CreateUser(ctx context.Context, username, passwordHash string) (*model.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
+}
+
+// Backup holds the accounts, folders and bookmarks of all users. Tokens,
+// webhooks, revisions and the trash aren't part of it.
+type Backup struct {
+	Version   int          `json:"version"`
+	CreatedAt time.Time    `json:"created_at"`
+	Users     []BackupUser `json:"users"`
+}
+
+type BackupUser struct {
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"password_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+	Document
+}
+
+// CreateBackup exports every user. Progress is told about users, not
+// bookmarks.
+func CreateBackup(ctx context.Context, store BackupStorage, progress Progress) (*Backup, error) {
+	users, err := store.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	backup := Backup{
+		Version:   BackupVersion,
+		CreatedAt: time.Now().UTC(),
+		Users:     make([]BackupUser, 0, len(users)),
+	}
+
+	for i, u := range users {
+		doc, err := Export(auth.WithUser(ctx, u), store)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export user %s: %w", u.Username, err)
+		}
+
+		backup.Users = append(backup.Users, BackupUser{
+			Username:     u.Username,
+			PasswordHash: u.PasswordHash,
+			CreatedAt:    u.CreatedAt,
+			Document:     *doc,
+		})
+
+		if progress != nil {
+			progress(i+1, len(users))
+		}
+	}
+
+	return &backup, nil
+}
+
+// RestoreBackup imports a backup. Missing users are created with their
+// password, existing ones get the bookmarks merged in, skipping URLs they
+// already saved, so a restore can be repeated.
+func RestoreBackup(ctx context.Context, store RestoreStorage, backup *Backup, progress Progress) (*Report, error) {
+	if backup.Version != BackupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d, expected %d", backup.Version, BackupVersion)
+	}
+
+	report := &Report{
+		Items: []Item{},
+	}
+
+	total := 0
+	for _, u := range backup.Users {
+		total += len(u.Bookmarks)
+	}
+
+	for _, bu := range backup.Users {
+		user, err := store.CreateUser(ctx, bu.Username, bu.PasswordHash)
+		if errors.Is(err, storage.ErrUserExists) {
+			log(ctx).Info("user already exists, merging bookmarks", slog.String("username", bu.Username))
+
+			user, err = store.GetUserByUsername(ctx, bu.Username)
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to restore user %s: %w", bu.Username, err)
+		}
+
+		imp := newImportRun(auth.WithUser(ctx, user), store, report, progress)
+		imp.total = total
+		imp.importDocument(&bu.Document)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,108 @@
+package transfer
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/api/request"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+// ImportDocument imports a JSON export for the user carried by ctx. Folders
+// get new ids, bookmarks follow their folder. Bookmarks whose URL is already
+// saved are skipped.
+func ImportDocument(ctx context.Context, importer Importer, doc *Document, progress Progress) *Report {
+	report := &Report{
+		Items: []Item{},
+	}
+
+	imp := newImportRun(ctx, importer, report, progress)
+	imp.total = len(doc.Bookmarks)
+	imp.importDocument(doc)
+
+	return report
+}
+
+func (imp *importRun) importDocument(doc *Document) {
+	known := make(map[int]bool, len(doc.Folders))
+	for _, f := range doc.Folders {
+		known[f.ID] = true
+	}
+
+	// Folders whose parent isn't part of the document end up at the root.
+	children := make(map[int][]*model.Folder)
+	for _, f := range doc.Folders {
+		key := 0
+		if f.ParentID != nil && known[*f.ParentID] {
+			key = *f.ParentID
+		}
+
+		children[key] = append(children[key], f)
+	}
+
+	// newIDs maps folder ids of the document to the created folders, failed
+	// folders map to nil.
+	newIDs := make(map[int]*int, len(doc.Folders))
+
+	var createFolders func(parentKey int, parentID *int)
+	createFolders = func(parentKey int, parentID *int) {
+		for _, f := range children[parentKey] {
+			created, err := imp.importer.ImportFolder(imp.ctx, f.Name, parentID)
+			if err != nil {
+				log(imp.ctx).Error("failed to import folder", slog.String("name", f.Name), logger.Error(err))
+
+				markFailed(children, f.ID, newIDs)
+				continue
+			}
+
+			newIDs[f.ID] = &created.ID
+			createFolders(f.ID, &created.ID)
+		}
+	}
+	createFolders(0, nil)
+
+	for _, bm := range doc.Bookmarks {
+		imported := &model.Bookmark{
+			Title:     bm.Title,
+			URL:       bm.URL,
+			Tags:      request.NormalizeTags(bm.Tags),
+			CreatedAt: bm.CreatedAt,
+			UpdatedAt: bm.UpdatedAt,
+		}
+
+		if imported.Title == "" {
+			imported.Title = imported.URL
+		}
+
+		if imported.CreatedAt.IsZero() {
+			imported.CreatedAt = time.Now()
+		}
+
+		if imported.UpdatedAt.IsZero() {
+			imported.UpdatedAt = imported.CreatedAt
+		}
+
+		if bm.FolderID != nil && known[*bm.FolderID] {
+			folderID, ok := newIDs[*bm.FolderID]
+			if !ok || folderID == nil {
+				imp.failBookmark(bm.URL, bm.Title)
+				continue
+			}
+
+			imported.FolderID = folderID
+		}
+
+		imp.importBookmark(imported)
+	}
+}
+
+// markFailed records the folder and its subfolders as not created.
+func markFailed(children map[int][]*model.Folder, id int, newIDs map[int]*int) {
+	newIDs[id] = nil
+
+	for _, f := range children[id] {
+		markFailed(children, f.ID, newIDs)
+	}
+}
@@ -0,0 +1,175 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/api/request"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/virtualtam/netscape-go"
+	"github.com/virtualtam/netscape-go/types"
+)
+
+// MarshalNetscape renders the document as a Netscape bookmarks file, with
+// folders nested as they are stored.
+func MarshalNetscape(doc *Document) ([]byte, error) {
+	tree := newFolderTree(doc.Folders, doc.Bookmarks)
+	netscapeDoc := types.Document{
+		Title: "Bookmarks",
+		Root:  tree.netscapeFolder(nil, "Bookmarks"),
+	}
+
+	m, err := netscape.Marshal(&netscapeDoc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bookmarks to netscape format: %w", err)
+	}
+
+	return m, nil
+}
+
+// ImportNetscape imports a Netscape bookmarks file for the user carried by
+// ctx, recreating its folders. Bookmarks whose URL is already saved are
+// skipped.
+func ImportNetscape(ctx context.Context, importer Importer, content []byte, progress Progress) (*Report, error) {
+	doc, err := netscape.Unmarshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse netscape bookmarks file: %w", err)
+	}
+
+	report := &Report{
+		Items: []Item{},
+	}
+
+	imp := newImportRun(ctx, importer, report, progress)
+	imp.total = len(flattenNetscapeFolder(doc.Root))
+	imp.importNetscapeFolder(doc.Root, nil)
+
+	return report, nil
+}
+
+// importNetscapeFolder imports the bookmarks of the folder into the folder
+// with the given id and recreates its subfolders beneath it.
+func (imp *importRun) importNetscapeFolder(folder types.Folder, folderID *int) {
+	for _, item := range folder.Bookmarks {
+		bm := netscapeToBookmark(item)
+		bm.FolderID = folderID
+
+		imp.importBookmark(bm)
+	}
+
+	for _, subfolder := range folder.Subfolders {
+		created, err := imp.importer.ImportFolder(imp.ctx, subfolder.Name, folderID)
+		if err != nil {
+			log(imp.ctx).Error("failed to import folder", slog.String("name", subfolder.Name), logger.Error(err))
+
+			for _, item := range flattenNetscapeFolder(subfolder) {
+				imp.failBookmark(item.Href, item.Title)
+			}
+			continue
+		}
+
+		imp.importNetscapeFolder(subfolder, &created.ID)
+	}
+}
+
+func flattenNetscapeFolder(folder types.Folder) []types.Bookmark {
+	bookmarks := append([]types.Bookmark{}, folder.Bookmarks...)
+	for _, subfolder := range folder.Subfolders {
+		bookmarks = append(bookmarks, flattenNetscapeFolder(subfolder)...)
+	}
+
+	return bookmarks
+}
+
+func netscapeToBookmark(item types.Bookmark) *model.Bookmark {
+	bm := &model.Bookmark{
+		Title:     item.Title,
+		URL:       item.Href,
+		Tags:      request.NormalizeTags(item.Tags),
+		CreatedAt: time.Now(),
+	}
+
+	if bm.Title == "" {
+		bm.Title = bm.URL
+	}
+
+	if item.CreatedAt != nil {
+		bm.CreatedAt = *item.CreatedAt
+	}
+
+	bm.UpdatedAt = bm.CreatedAt
+	if item.UpdatedAt != nil {
+		bm.UpdatedAt = *item.UpdatedAt
+	}
+
+	return bm
+}
+
+// folderTree indexes folders and bookmarks by their parent folder. The root
+// level is stored under the zero key since folder ids start from one.
+type folderTree struct {
+	folders   map[int][]*model.Folder
+	bookmarks map[int][]*model.Bookmark
+}
+
+func newFolderTree(folders []*model.Folder, bookmarks []*model.Bookmark) *folderTree {
+	tree := &folderTree{
+		folders:   make(map[int][]*model.Folder),
+		bookmarks: make(map[int][]*model.Bookmark),
+	}
+
+	for _, f := range folders {
+		key := folderKey(f.ParentID)
+		tree.folders[key] = append(tree.folders[key], f)
+	}
+
+	for _, bm := range bookmarks {
+		key := folderKey(bm.FolderID)
+		tree.bookmarks[key] = append(tree.bookmarks[key], bm)
+	}
+
+	return tree
+}
+
+func (t *folderTree) netscapeFolder(folder *model.Folder, name string) types.Folder {
+	var id *int
+	result := types.Folder{
+		Name: name,
+	}
+
+	if folder != nil {
+		id = &folder.ID
+		result.Name = folder.Name
+		result.CreatedAt = &folder.CreatedAt
+		result.UpdatedAt = &folder.UpdatedAt
+	}
+
+	key := folderKey(id)
+	result.Bookmarks = make([]types.Bookmark, 0, len(t.bookmarks[key]))
+	for _, bm := range t.bookmarks[key] {
+		result.Bookmarks = append(result.Bookmarks, types.Bookmark{
+			Title:     bm.Title,
+			CreatedAt: &bm.CreatedAt,
+			UpdatedAt: &bm.UpdatedAt,
+			Href:      bm.URL,
+			Tags:      bm.Tags,
+		})
+	}
+
+	for _, subfolder := range t.folders[key] {
+		result.Subfolders = append(result.Subfolders, t.netscapeFolder(subfolder, ""))
+	}
+
+	return result
+}
+
+func folderKey(id *int) int {
+	if id == nil {
+		return 0
+	}
+
+	return *id
+}
@@ -0,0 +1,168 @@
+// Package transfer moves bookmarks in and out of the storage: Netscape HTML
+// and JSON exports, imports of both and backups covering all users.
+package transfer
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+
+	"github.com/go-playground/validator"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/storage"
+)
+
+const (
+	StatusCreated = "created"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+)
+
+type Importer interface {
+	ImportBookmark(ctx context.Context, bookmark *model.Bookmark) (*model.Bookmark, error)
+	ImportFolder(ctx context.Context, name string, parentID *int) (*model.Folder, error)
+}
+
+type Exporter interface {
+	GetBookmarks(ctx context.Context, filter storage.BookmarkFilter) (*storage.BookmarkPage, error)
+	GetFolders(ctx context.Context) ([]*model.Folder, error)
+}
+
+// Progress is told how many of the total bookmarks were handled so far.
+type Progress func(done, total int)
+
+// Document holds the folders and bookmarks of a single user.
+type Document struct {
+	Folders   []*model.Folder   `json:"folders"`
+	Bookmarks []*model.Bookmark `json:"bookmarks"`
+}
+
+type Report struct {
+	Created int    `json:"created"`
+	Skipped int    `json:"skipped"`
+	Failed  int    `json:"failed"`
+	Items   []Item `json:"items"`
+}
+
+type Item struct {
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	ID     int    `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (r *Report) Add(item Item) {
+	switch item.Status {
+	case StatusCreated:
+		r.Created++
+	case StatusSkipped:
+		r.Skipped++
+	case StatusFailed:
+		r.Failed++
+	}
+
+	r.Items = append(r.Items, item)
+}
+
+func (r *Report) done() int {
+	return r.Created + r.Skipped + r.Failed
+}
+
+// Export reads all live bookmarks and folders of the user carried by ctx.
+func Export(ctx context.Context, exporter Exporter) (*Document, error) {
+	page, err := exporter.GetBookmarks(ctx, storage.BookmarkFilter{
+		Limit:     math.MaxInt32,
+		SkipCount: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := exporter.GetFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{
+		Folders:   folders,
+		Bookmarks: page.Bookmarks,
+	}, nil
+}
+
+// importRun imports bookmarks for the user carried by ctx into report.
+type importRun struct {
+	ctx      context.Context
+	importer Importer
+	validate *validator.Validate
+	report   *Report
+	progress Progress
+	total    int
+}
+
+func newImportRun(ctx context.Context, importer Importer, report *Report, progress Progress) *importRun {
+	return &importRun{
+		ctx:      ctx,
+		importer: importer,
+		validate: validator.New(),
+		report:   report,
+		progress: progress,
+	}
+}
+
+func (imp *importRun) add(item Item) {
+	imp.report.Add(item)
+
+	if imp.progress != nil {
+		imp.progress(imp.report.done(), imp.total)
+	}
+}
+
+func (imp *importRun) importBookmark(bm *model.Bookmark) {
+	item := Item{
+		URL:   bm.URL,
+		Title: bm.Title,
+	}
+
+	if err := imp.validate.Var(bm.URL, "required,url"); err != nil {
+		item.Status = StatusFailed
+		item.Error = "invalid url"
+		imp.add(item)
+		return
+	}
+
+	created, err := imp.importer.ImportBookmark(imp.ctx, bm)
+	switch {
+	case errors.Is(err, storage.ErrExists):
+		item.Status = StatusSkipped
+		item.Error = storage.ErrExists.Error()
+	case err != nil:
+		log(imp.ctx).Error("failed to import bookmark", slog.String("url", bm.URL), logger.Error(err))
+
+		item.Status = StatusFailed
+		item.Error = "failed to create bookmark"
+	default:
+		item.Status = StatusCreated
+		item.ID = created.ID
+	}
+
+	imp.add(item)
+}
+
+// failBookmark records a bookmark that couldn't be imported because its
+// folder couldn't be created.
+func (imp *importRun) failBookmark(url, title string) {
+	imp.add(Item{
+		URL:    url,
+		Title:  title,
+		Status: StatusFailed,
+		Error:  "failed to create folder",
+	})
+}
+
+// log returns the logger carried by ctx for the transfer package.
+func log(ctx context.Context) *slog.Logger {
+	return logger.For(ctx, "transfer")
+}
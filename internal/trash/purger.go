@@ -0,0 +1,60 @@
+package trash
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+)
+
+type Storage interface {
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+// Purger periodically deletes bookmarks that stayed in the trash longer than
+// the retention period.
+type Purger struct {
+	storage   Storage
+	interval  time.Duration
+	retention time.Duration
+}
+
+func NewPurger(storage Storage, interval, retention time.Duration) *Purger {
+	return &Purger{
+		storage:   storage,
+		interval:  interval,
+		retention: retention,
+	}
+}
+
+// Run purges the trash round after round until ctx is cancelled.
+func (p *Purger) Run(ctx context.Context) {
+	log(ctx).Info("trash purger starting",
+		slog.Duration("interval", p.interval),
+		slog.Duration("retention", p.retention),
+	)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := p.storage.PurgeTrash(ctx, time.Now().Add(-p.retention))
+		if err != nil {
+			log(ctx).Error("failed to purge trash", logger.Error(err))
+		} else if purged > 0 {
+			log(ctx).Info("trash purged", slog.Int64("bookmarks_count", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// log returns the logger carried by ctx for the trash package.
+func log(ctx context.Context) *slog.Logger {
+	return logger.For(ctx, "trash")
+}
@@ -0,0 +1,205 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/lib/logger"
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+	"github.com/haadi-coder/bookmark-manager/internal/tracing"
+)
+
+const userAgent = "bookmark-manager/1.0 (+webhooks)"
+
+type Storage interface {
+	DispatchEvents(ctx context.Context, limit int) (int, error)
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error)
+	SetDeliveryResult(ctx context.Context, id int64, result model.DeliveryResult) error
+}
+
+type Config struct {
+	// PollInterval is the pause between two looks at the outbox.
+	PollInterval time.Duration
+	// Concurrency caps the number of deliveries sent at the same time.
+	Concurrency int
+	// MaxAttempts is how many times a delivery is tried before giving up.
+	MaxAttempts int
+	// Backoff is the pause after the first failed attempt, it doubles with
+	// every further failure up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Timeout    time.Duration
+}
+
+// Worker turns outbox events into webhook deliveries and sends them.
+type Worker struct {
+	cfg     Config
+	storage Storage
+	client  *http.Client
+}
+
+func NewWorker(cfg Config, storage Storage) *Worker {
+	return &Worker{
+		cfg:     cfg,
+		storage: storage,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: tracing.Transport(),
+		},
+	}
+}
+
+// Run dispatches and delivers events until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	log(ctx).Info("webhook worker starting", slog.Duration("poll_interval", w.cfg.PollInterval))
+
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.dispatch(ctx); err != nil {
+			log(ctx).Error("failed to dispatch webhook events", logger.Error(err))
+		}
+
+		if err := w.deliver(ctx); err != nil {
+			log(ctx).Error("failed to deliver webhooks", logger.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) dispatch(ctx context.Context) error {
+	const batchSize = 100
+
+	for {
+		dispatched, err := w.storage.DispatchEvents(ctx, batchSize)
+		if err != nil {
+			return err
+		}
+
+		if dispatched < batchSize {
+			return nil
+		}
+	}
+}
+
+// deliver sends due deliveries until none are left.
+func (w *Worker) deliver(ctx context.Context) error {
+	for ctx.Err() == nil {
+		// The lease outlives a timed out attempt, so a delivery is only
+		// picked up again if the worker died while sending it.
+		deliveries, err := w.storage.ClaimDeliveries(ctx, w.cfg.Concurrency, 2*w.cfg.Timeout)
+		if err != nil {
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		var wg sync.WaitGroup
+		for _, d := range deliveries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				result := w.send(ctx, d)
+				if err := w.storage.SetDeliveryResult(ctx, d.ID, result); err != nil {
+					log(ctx).Error("failed to save webhook delivery result", logger.Error(err), slog.Int64("id", d.ID))
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	return nil
+}
+
+func (w *Worker) send(ctx context.Context, d *model.WebhookDelivery) model.DeliveryResult {
+	var result model.DeliveryResult
+
+	err := w.post(ctx, d, &result)
+	if err == nil {
+		result.Succeeded = true
+
+		log(ctx).Debug("webhook delivered", slog.Int64("id", d.ID), slog.String("url", d.URL))
+		return result
+	}
+
+	result.Error = err.Error()
+
+	attempts := d.Attempts + 1
+	if attempts < w.cfg.MaxAttempts {
+		next := time.Now().Add(w.backoff(attempts))
+		result.NextAttemptAt = &next
+	}
+
+	log(ctx).Info("webhook delivery failed",
+		slog.Int64("id", d.ID),
+		slog.String("url", d.URL),
+		slog.Int("attempt", attempts),
+		logger.Error(err),
+	)
+
+	return result
+}
+
+func (w *Worker) post(ctx context.Context, d *model.WebhookDelivery, result *model.DeliveryResult) error {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Webhook-Id", strconv.FormatInt(d.ID, 10))
+	req.Header.Set("X-Webhook-Event", d.Event)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", Sign(d.Secret, timestamp, d.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		_ = resp.Body.Close()
+	}()
+
+	result.ResponseStatus = &resp.StatusCode
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// backoff returns the pause before the next attempt after the given number
+// of failed ones.
+func (w *Worker) backoff(failures int) time.Duration {
+	delay := w.cfg.Backoff
+	for i := 1; i < failures && delay < w.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, w.cfg.MaxBackoff)
+}
+
+// log returns the logger carried by ctx for the webhook package.
+func log(ctx context.Context) *slog.Logger {
+	return logger.For(ctx, "webhook")
+}
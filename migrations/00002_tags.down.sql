@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_bookmark_tags_tag_id;
+
+DROP TABLE IF EXISTS bookmark_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_bookmarks_folder_id;
+
+ALTER TABLE bookmarks DROP COLUMN IF EXISTS folder_id;
+
+DROP INDEX IF EXISTS idx_folders_parent_id;
+
+DROP TABLE IF EXISTS folders;
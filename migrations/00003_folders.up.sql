@@ -0,0 +1,16 @@
+CREATE TABLE folders (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    parent_id INTEGER REFERENCES folders (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_folders_parent_id
+ON folders (parent_id);
+
+ALTER TABLE bookmarks
+ADD COLUMN folder_id INTEGER REFERENCES folders (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_folder_id
+ON bookmarks (folder_id);
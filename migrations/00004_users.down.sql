@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_folders_user_id;
+
+ALTER TABLE bookmarks DROP CONSTRAINT IF EXISTS bookmarks_user_id_url_key;
+ALTER TABLE bookmarks ADD CONSTRAINT bookmarks_url_key UNIQUE (url);
+
+ALTER TABLE folders DROP COLUMN IF EXISTS user_id;
+ALTER TABLE bookmarks DROP COLUMN IF EXISTS user_id;
+
+DROP TABLE IF EXISTS users;
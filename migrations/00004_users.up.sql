@@ -0,0 +1,27 @@
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    username TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Data created before accounts existed is handed over to a "legacy" user.
+-- It has no usable password, set one manually to sign in as it.
+INSERT INTO users (username, password_hash)
+SELECT 'legacy', ''
+WHERE EXISTS (SELECT 1 FROM bookmarks) OR EXISTS (SELECT 1 FROM folders);
+
+ALTER TABLE bookmarks ADD COLUMN user_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE folders ADD COLUMN user_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+
+UPDATE bookmarks SET user_id = (SELECT id FROM users WHERE username = 'legacy');
+UPDATE folders SET user_id = (SELECT id FROM users WHERE username = 'legacy');
+
+ALTER TABLE bookmarks ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE folders ALTER COLUMN user_id SET NOT NULL;
+
+ALTER TABLE bookmarks DROP CONSTRAINT IF EXISTS bookmarks_url_key;
+ALTER TABLE bookmarks ADD CONSTRAINT bookmarks_user_id_url_key UNIQUE (user_id, url);
+
+CREATE INDEX IF NOT EXISTS idx_folders_user_id
+ON folders (user_id);
@@ -0,0 +1,5 @@
+ALTER TABLE bookmarks
+DROP COLUMN IF EXISTS metadata_fetched_at,
+DROP COLUMN IF EXISTS favicon_url,
+DROP COLUMN IF EXISTS image_url,
+DROP COLUMN IF EXISTS description;
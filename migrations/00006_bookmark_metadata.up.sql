@@ -0,0 +1,5 @@
+ALTER TABLE bookmarks
+ADD COLUMN description TEXT NOT NULL DEFAULT '',
+ADD COLUMN image_url TEXT NOT NULL DEFAULT '',
+ADD COLUMN favicon_url TEXT NOT NULL DEFAULT '',
+ADD COLUMN metadata_fetched_at TIMESTAMPTZ;
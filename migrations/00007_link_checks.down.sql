@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_bookmarks_last_checked_at;
+DROP INDEX IF EXISTS idx_bookmarks_link_status;
+
+ALTER TABLE bookmarks
+DROP COLUMN IF EXISTS consecutive_failures,
+DROP COLUMN IF EXISTS last_checked_at,
+DROP COLUMN IF EXISTS final_url,
+DROP COLUMN IF EXISTS http_status,
+DROP COLUMN IF EXISTS link_status;
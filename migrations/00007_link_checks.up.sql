@@ -0,0 +1,12 @@
+ALTER TABLE bookmarks
+ADD COLUMN link_status TEXT,
+ADD COLUMN http_status INTEGER,
+ADD COLUMN final_url TEXT NOT NULL DEFAULT '',
+ADD COLUMN last_checked_at TIMESTAMPTZ,
+ADD COLUMN consecutive_failures INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_link_status
+ON bookmarks (link_status);
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_last_checked_at
+ON bookmarks (last_checked_at NULLS FIRST);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_bookmarks_search_vector;
+
+DROP TRIGGER IF EXISTS trg_bookmarks_search_vector ON bookmarks;
+DROP FUNCTION IF EXISTS bookmarks_search_vector();
+
+ALTER TABLE bookmarks DROP COLUMN IF EXISTS search_vector;
+
+DROP TABLE IF EXISTS search_settings;
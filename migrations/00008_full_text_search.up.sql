@@ -0,0 +1,32 @@
+-- The text search configuration is chosen by the application at startup,
+-- see PostgresStorage.ConfigureSearch.
+CREATE TABLE search_settings (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    config REGCONFIG NOT NULL DEFAULT 'simple'
+);
+
+INSERT INTO search_settings DEFAULT VALUES;
+
+ALTER TABLE bookmarks ADD COLUMN search_vector TSVECTOR;
+
+CREATE FUNCTION bookmarks_search_vector() RETURNS TRIGGER AS $$
+DECLARE
+    cfg REGCONFIG := (SELECT config FROM search_settings);
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector(cfg, COALESCE(NEW.title, '')), 'A') ||
+        setweight(to_tsvector(cfg, COALESCE(NEW.description, '')), 'B') ||
+        setweight(to_tsvector(cfg, COALESCE(NEW.url, '')), 'C');
+
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_bookmarks_search_vector
+BEFORE INSERT OR UPDATE OF title, description, url ON bookmarks
+FOR EACH ROW EXECUTE FUNCTION bookmarks_search_vector();
+
+UPDATE bookmarks SET title = title;
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_search_vector
+ON bookmarks USING GIN (search_vector);
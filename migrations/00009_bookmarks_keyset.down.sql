@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_bookmarks_user_created;
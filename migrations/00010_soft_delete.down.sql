@@ -0,0 +1,7 @@
+DELETE FROM bookmarks WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_bookmarks_deleted_at;
+DROP INDEX IF EXISTS bookmarks_user_id_url_key;
+ALTER TABLE bookmarks ADD CONSTRAINT bookmarks_user_id_url_key UNIQUE (user_id, url);
+
+ALTER TABLE bookmarks DROP COLUMN IF EXISTS deleted_at;
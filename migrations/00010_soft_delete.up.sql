@@ -0,0 +1,9 @@
+ALTER TABLE bookmarks ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- A trashed bookmark must not block saving the same URL again.
+ALTER TABLE bookmarks DROP CONSTRAINT IF EXISTS bookmarks_user_id_url_key;
+CREATE UNIQUE INDEX IF NOT EXISTS bookmarks_user_id_url_key
+ON bookmarks (user_id, url) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_deleted_at
+ON bookmarks (deleted_at) WHERE deleted_at IS NOT NULL;
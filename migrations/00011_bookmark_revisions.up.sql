@@ -0,0 +1,23 @@
+CREATE TABLE bookmark_revisions (
+    id SERIAL PRIMARY KEY,
+    bookmark_id INTEGER NOT NULL REFERENCES bookmarks (id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (bookmark_id, revision)
+);
+
+-- Existing bookmarks start their history from their current state.
+INSERT INTO bookmark_revisions (bookmark_id, revision, action, title, url, tags, created_at)
+SELECT b.id, 1, 'created', b.title, b.url,
+    COALESCE((
+        SELECT array_agg(t.name ORDER BY t.name)
+        FROM bookmark_tags bt
+        JOIN tags t ON t.id = bt.tag_id
+        WHERE bt.bookmark_id = b.id
+    ), '{}'),
+    COALESCE(b.updated_at, NOW())
+FROM bookmarks b;
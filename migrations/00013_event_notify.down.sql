@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_outbox_events_user_id;
+
+DROP TRIGGER IF EXISTS trg_outbox_events_notify ON outbox_events;
+DROP FUNCTION IF EXISTS outbox_events_notify();
@@ -0,0 +1,16 @@
+-- Wakes up event stream listeners on every backend instance. The payload
+-- only carries ids, listeners read the events themselves from the outbox.
+CREATE FUNCTION outbox_events_notify() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('bookmark_events', json_build_object('id', NEW.id, 'user_id', NEW.user_id)::text);
+
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_outbox_events_notify
+AFTER INSERT ON outbox_events
+FOR EACH ROW EXECUTE FUNCTION outbox_events_notify();
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_user_id
+ON outbox_events (user_id, id);
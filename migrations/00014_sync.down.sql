@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS trg_bookmarks_sync_reset ON bookmarks;
+DROP FUNCTION IF EXISTS bookmarks_sync_reset();
+DROP TABLE IF EXISTS sync_resets;
+
+DROP INDEX IF EXISTS idx_bookmarks_user_change_seq;
+
+DROP TRIGGER IF EXISTS trg_bookmarks_change_seq ON bookmarks;
+DROP FUNCTION IF EXISTS bookmarks_change_seq();
+
+ALTER TABLE bookmarks DROP COLUMN IF EXISTS change_seq;
+DROP SEQUENCE IF EXISTS bookmark_change_seq;
@@ -0,0 +1,45 @@
+CREATE SEQUENCE bookmark_change_seq;
+
+ALTER TABLE bookmarks ADD COLUMN change_seq BIGINT NOT NULL DEFAULT nextval('bookmark_change_seq');
+
+-- Changes of a user are numbered in commit order: the advisory lock holds
+-- back a concurrent change until the one that took an earlier number has
+-- committed, so clients never skip a change by syncing in between.
+CREATE FUNCTION bookmarks_change_seq() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('bookmark_changes'), NEW.user_id);
+    NEW.change_seq := nextval('bookmark_change_seq');
+
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_bookmarks_change_seq
+BEFORE INSERT OR UPDATE OF title, url, description, image_url, favicon_url, folder_id, updated_at, deleted_at
+ON bookmarks
+FOR EACH ROW EXECUTE FUNCTION bookmarks_change_seq();
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user_change_seq
+ON bookmarks (user_id, change_seq);
+
+-- Purged bookmarks leave no tombstone. Clients that synced before the purge
+-- are told to start over.
+CREATE TABLE sync_resets (
+    user_id INTEGER PRIMARY KEY,
+    change_seq BIGINT NOT NULL
+);
+
+CREATE FUNCTION bookmarks_sync_reset() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO sync_resets (user_id, change_seq)
+    VALUES (OLD.user_id, OLD.change_seq)
+    ON CONFLICT (user_id) DO UPDATE
+    SET change_seq = GREATEST(sync_resets.change_seq, EXCLUDED.change_seq);
+
+    RETURN OLD;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_bookmarks_sync_reset
+AFTER DELETE ON bookmarks
+FOR EACH ROW EXECUTE FUNCTION bookmarks_sync_reset();
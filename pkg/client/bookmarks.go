@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/haadi-coder/bookmark-manager/internal/model"
+)
+
+// Bookmark is a bookmark as stored by the server.
+type Bookmark = model.Bookmark
+
+// Link statuses set by the server's dead-link checker.
+const (
+	LinkStatusOK         = model.LinkStatusOK
+	LinkStatusRedirected = model.LinkStatusRedirected
+	LinkStatusBroken     = model.LinkStatusBroken
+)
+
+// BookmarkInput creates or replaces a bookmark.
+type BookmarkInput struct {
+	URL  string   `json:"url"`
+	Tags []string `json:"tags,omitempty"`
+
+	// Title is fetched from the page in the background when it's empty.
+	Title string `json:"title,omitempty"`
+
+	// FolderID is only used on creation.
+	FolderID *int `json:"folder_id,omitempty"`
+}
+
+type ListOptions struct {
+	// PerPage defaults to 50 on the server, which caps it at 500.
+	PerPage int
+	Page    int
+	// Cursor is a next or previous cursor of an earlier page, it takes
+	// precedence over Page.
+	Cursor string
+	// SkipCount leaves the total out, which is cheaper for large libraries.
+	SkipCount bool
+
+	Search string
+	Tags   []string
+	// AnyTag matches bookmarks with any of Tags instead of all of them.
+	AnyTag     bool
+	FolderID   *int
+	LinkStatus string
+}
+
+func (o ListOptions) query() url.Values {
+	q := url.Values{}
+
+	if o.PerPage > 0 {
+		q.Set("per_page", strconv.Itoa(o.PerPage))
+	}
+	if o.Page > 0 {
+		q.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.Cursor != "" {
+		q.Set("cursor", o.Cursor)
+	}
+	if o.SkipCount {
+		q.Set("count", "false")
+	}
+	if o.Search != "" {
+		q.Set("search", o.Search)
+	}
+	for _, tag := range o.Tags {
+		q.Add("tag", tag)
+	}
+	if o.AnyTag {
+		q.Set("tag_mode", "any")
+	}
+	if o.FolderID != nil {
+		q.Set("folder_id", strconv.Itoa(*o.FolderID))
+	}
+	if o.LinkStatus != "" {
+		q.Set("status", o.LinkStatus)
+	}
+
+	return q
+}
+
+// Page is a single page of bookmarks.
+type Page struct {
+	Bookmarks []*Bookmark
+	// Total is nil when counting was skipped.
+	Total      *int
+	NextCursor string
+	PrevCursor string
+}
+
+// List returns a single page of bookmarks.
+func (c *Client) List(ctx context.Context, opts ListOptions) (*Page, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/api/v1/bookmarks", opts.query(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var page struct {
+		Data       []*Bookmark `json:"data"`
+		Total      *int        `json:"total"`
+		NextCursor string      `json:"next_cursor"`
+		PrevCursor string      `json:"prev_cursor"`
+	}
+	if err := decodeBody(resp, &page); err != nil {
+		return nil, err
+	}
+
+	return &Page{
+		Bookmarks:  page.Data,
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}, nil
+}
+
+// All iterates over every bookmark matching opts, fetching the pages as it
+// goes. Listings follow the next cursor, search results are paged by number.
+// Iteration stops after the first error.
+func (c *Client) All(ctx context.Context, opts ListOptions) iter.Seq2[*Bookmark, error] {
+	return func(yield func(*Bookmark, error) bool) {
+		opts.SkipCount = true
+
+		for {
+			page, err := c.List(ctx, opts)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, bookmark := range page.Bookmarks {
+				if !yield(bookmark, nil) {
+					return
+				}
+			}
+
+			switch {
+			case page.NextCursor != "":
+				opts.Cursor = page.NextCursor
+			case opts.Search != "" && len(page.Bookmarks) > 0 && len(page.Bookmarks) >= opts.PerPage:
+				opts.Page = max(opts.Page, 1) + 1
+			default:
+				return
+			}
+		}
+	}
+}
+
+// Create stores a new bookmark. It fails with ErrExists when the URL is
+// already bookmarked.
+func (c *Client) Create(ctx context.Context, input BookmarkInput) (*Bookmark, error) {
+	var bookmark Bookmark
+	if err := c.doJSON(ctx, http.MethodPost, "/api/v1/bookmarks", nil, input, &bookmark); err != nil {
+		return nil, err
+	}
+
+	return &bookmark, nil
+}
+
+// Edit replaces the URL, title and tags of a bookmark.
+func (c *Client) Edit(ctx context.Context, id int, input BookmarkInput) (*Bookmark, error) {
+	var bookmark Bookmark
+	if err := c.doJSON(ctx, http.MethodPatch, "/api/v1/bookmarks/"+strconv.Itoa(id), nil, input, &bookmark); err != nil {
+		return nil, err
+	}
+
+	return &bookmark, nil
+}
+
+// Delete moves a bookmark to the trash.
+func (c *Client) Delete(ctx context.Context, id int) error {
+	return c.doJSON(ctx, http.MethodDelete, "/api/v1/bookmarks/"+strconv.Itoa(id), nil, nil, nil)
+}
+
+// Exists looks up the bookmark of a URL, returning its id when found.
+func (c *Client) Exists(ctx context.Context, rawURL string) (int, bool, error) {
+	var result struct {
+		ID    int  `json:"id"`
+		Found bool `json:"found"`
+	}
+
+	query := url.Values{"url": {rawURL}}
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/bookmarks/exists", query, nil, &result); err != nil {
+		return 0, false, err
+	}
+
+	return result.ID, result.Found, nil
+}
+
+// Export returns all bookmarks as a Netscape HTML file, which the caller
+// must close. It needs a token with the export scope.
+func (c *Client) Export(ctx context.Context) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/api/v1/bookmarks/export/html", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
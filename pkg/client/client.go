@@ -0,0 +1,221 @@
+// Package client is a Go client for the bookmark-manager API.
+//
+//	c, err := client.New(client.Config{
+//		BaseURL: "http://localhost:8080",
+//		Token:   os.Getenv("BM_TOKEN"),
+//	})
+//	if err != nil {
+//		return err
+//	}
+//
+//	for bookmark, err := range c.All(ctx, client.ListOptions{Tags: []string{"go"}}) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(bookmark.Title)
+//	}
+//
+// Requests answered with 429 Too Many Requests are retried after the delay
+// given by the Retry-After header.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is how often a rate limited request is retried
+	// unless Config says otherwise.
+	DefaultMaxRetries = 3
+	// DefaultRetryAfter is the pause before a retry when the server sends
+	// no Retry-After header.
+	DefaultRetryAfter = time.Second
+
+	userAgent = "bookmark-manager-client/1.0"
+)
+
+type Config struct {
+	// BaseURL is where the server is reachable, as in http://localhost:8080.
+	BaseURL string
+
+	// Token is an API token. Username and Password are used instead when
+	// it's empty.
+	Token    string
+	Username string
+	Password string
+
+	// HTTPClient sends the requests, http.DefaultClient when nil.
+	HTTPClient *http.Client
+
+	// MaxRetries caps the retries of rate limited requests, zero means
+	// DefaultMaxRetries and a negative value disables retrying.
+	MaxRetries int
+	// MaxRetryAfter caps the pause before a retry, zero means no cap.
+	MaxRetryAfter time.Duration
+}
+
+// Client calls the bookmarks API. It's safe for concurrent use.
+type Client struct {
+	cfg     Config
+	baseURL *url.URL
+	http    *http.Client
+}
+
+func New(cfg Config) (*Client, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(cfg.BaseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base url: %w", err)
+	}
+	if baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("base url must be absolute, got: %q", cfg.BaseURL)
+	}
+
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		cfg:     cfg,
+		baseURL: baseURL,
+		http:    httpClient,
+	}, nil
+}
+
+// do sends a request to the API and returns the response, which the caller
+// must close. Error responses are turned into an *Error.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body any) (*http.Response, error) {
+	var payload []byte
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		payload = data
+	}
+
+	u := c.baseURL.JoinPath(path)
+	u.RawQuery = query.Encode()
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		c.authenticate(req)
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < c.cfg.MaxRetries {
+			delay := c.retryAfter(resp)
+			drain(resp.Body)
+
+			if err := sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			defer resp.Body.Close()
+
+			return nil, decodeError(resp)
+		}
+
+		return resp, nil
+	}
+}
+
+// doJSON sends a request and decodes the data of the response into out.
+func (c *Client) doJSON(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	resp, err := c.do(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+
+	if out == nil {
+		drain(resp.Body)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	envelope := struct {
+		Data any `json:"data"`
+	}{Data: out}
+
+	return decodeBody(resp, &envelope)
+}
+
+func decodeBody(resp *http.Response, out any) error {
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) authenticate(req *http.Request) {
+	switch {
+	case c.cfg.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	case c.cfg.Username != "":
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+}
+
+// retryAfter reads the pause the server asked for, in seconds or as a date.
+func (c *Client) retryAfter(resp *http.Response) time.Duration {
+	delay := DefaultRetryAfter
+
+	header := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		delay = max(time.Until(date), 0)
+	}
+
+	if c.cfg.MaxRetryAfter > 0 {
+		delay = min(delay, c.cfg.MaxRetryAfter)
+	}
+
+	return delay
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// drain reads what's left of a body, so the connection can be reused.
+func drain(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, 1<<16))
+	_ = body.Close()
+}
@@ -0,0 +1,418 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haadi-coder/bookmark-manager/internal/api"
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+	"github.com/haadi-coder/bookmark-manager/internal/lib/auth"
+	"github.com/haadi-coder/bookmark-manager/internal/storage/memory"
+	"github.com/haadi-coder/bookmark-manager/pkg/client"
+)
+
+type noopQueue struct{}
+
+func (noopQueue) Enqueue(bookmarkID int, url string) {}
+
+// testServer is the API over an empty memory storage with a single user.
+type testServer struct {
+	store *memory.Storage
+	// ctx carries the user, for seeding the storage.
+	ctx context.Context
+	url string
+	// client is authenticated with a token of the scopes the server was
+	// started with.
+	client *client.Client
+}
+
+func newServer(t *testing.T, scopes ...string) *testServer {
+	t.Helper()
+
+	store := memory.New()
+
+	user, err := store.CreateUser(context.Background(), "alice", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	ctx := auth.WithUser(context.Background(), user)
+
+	token, hash, err := auth.GenerateToken()
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, err := store.CreateToken(ctx, "test", hash, scopes); err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	srv := httptest.NewServer(api.NewServer(&api.ServerConfig{
+		RequestTimeout:  5 * time.Second,
+		TransferTimeout: 5 * time.Second,
+
+		BookmarkProvider: store,
+		BookmarkChecker:  store,
+		BookmarkDeleter:  store,
+		BookmarkEditor:   store,
+		BookmarkCreator:  store,
+		FolderProvider:   store,
+
+		UserProvider:       store,
+		TokenAuthenticator: store,
+
+		MetadataQueue: noopQueue{},
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.New(client.Config{BaseURL: srv.URL, Token: token})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	return &testServer{store: store, ctx: ctx, url: srv.URL, client: c}
+}
+
+// seed stores n bookmarks and returns their ids, newest first as listed.
+func (ts *testServer) seed(t *testing.T, n int) []int {
+	t.Helper()
+
+	ids := make([]int, 0, n)
+	for i := range n {
+		bm, err := ts.store.CreateBookmark(ts.ctx, fmt.Sprintf("Page %d", i), fmt.Sprintf("https://example.com/%d", i), nil, nil)
+		if err != nil {
+			t.Fatalf("CreateBookmark() error = %v", err)
+		}
+		ids = append(ids, bm.ID)
+	}
+	slices.Reverse(ids)
+
+	return ids
+}
+
+func TestList(t *testing.T) {
+	ts := newServer(t, auth.ScopeRead)
+	want := ts.seed(t, 5)
+	c := ts.client
+
+	first, err := c.List(context.Background(), client.ListOptions{PerPage: 2})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if first.Total == nil || *first.Total != 5 {
+		t.Errorf("List() total = %v, want 5", first.Total)
+	}
+	if first.PrevCursor != "" {
+		t.Errorf("List() first page prev cursor = %q, want none", first.PrevCursor)
+	}
+
+	var got []int
+	page := first
+	for {
+		for _, bm := range page.Bookmarks {
+			got = append(got, bm.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+
+		page, err = c.List(context.Background(), client.ListOptions{PerPage: 2, Cursor: page.NextCursor})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if page.PrevCursor == "" {
+			t.Errorf("List() later page has no prev cursor")
+		}
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("List() pages = %v, want %v", got, want)
+	}
+}
+
+func TestAll(t *testing.T) {
+	ts := newServer(t, auth.ScopeRead)
+	want := ts.seed(t, 5)
+	c := ts.client
+
+	var got []int
+	for bm, err := range c.All(context.Background(), client.ListOptions{PerPage: 2}) {
+		if err != nil {
+			t.Fatalf("All() error = %v", err)
+		}
+		got = append(got, bm.ID)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("All() = %v, want %v", got, want)
+	}
+}
+
+func TestAllStopsOnError(t *testing.T) {
+	c := newServer(t).client
+
+	var errs int
+	for bm, err := range c.All(context.Background(), client.ListOptions{}) {
+		if err == nil {
+			t.Fatalf("All() = bookmark %d, want an error without the read scope", bm.ID)
+		}
+		if !errors.Is(err, client.ErrForbidden) {
+			t.Errorf("All() error = %v, want ErrForbidden", err)
+		}
+		errs++
+	}
+
+	if errs != 1 {
+		t.Errorf("All() yielded %d errors, want 1", errs)
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	c := newServer(t, auth.ScopeRead, auth.ScopeWrite).client
+	ctx := context.Background()
+
+	created, err := c.Create(ctx, client.BookmarkInput{URL: "https://go.dev", Title: "Go", Tags: []string{"go"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.ID == 0 || created.Title != "Go" || !slices.Equal(created.Tags, []string{"go"}) {
+		t.Errorf("Create() = %+v, want the new bookmark", created)
+	}
+
+	id, found, err := c.Exists(ctx, "https://go.dev")
+	if err != nil || !found || id != created.ID {
+		t.Errorf("Exists() = %d, %v, %v, want %d, true, nil", id, found, err, created.ID)
+	}
+	if _, found, err := c.Exists(ctx, "https://example.com"); err != nil || found {
+		t.Errorf("Exists() of an unknown URL = %v, %v, want false, nil", found, err)
+	}
+
+	edited, err := c.Edit(ctx, created.ID, client.BookmarkInput{URL: "https://go.dev/doc", Title: "Go docs"})
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if edited.ID != created.ID || edited.Title != "Go docs" || edited.URL != "https://go.dev/doc" {
+		t.Errorf("Edit() = %+v, want the edited bookmark", edited)
+	}
+
+	if err := c.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, found, err := c.Exists(ctx, "https://go.dev/doc"); err != nil || found {
+		t.Errorf("Exists() of a deleted bookmark = %v, %v, want false, nil", found, err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	ts := newServer(t, auth.ScopeExport)
+
+	if _, err := ts.store.CreateBookmark(ts.ctx, "Go", "https://go.dev", nil, nil); err != nil {
+		t.Fatalf("CreateBookmark() error = %v", err)
+	}
+
+	body, err := ts.client.Export(context.Background())
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	defer body.Close()
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	if !strings.Contains(string(content), "https://go.dev") {
+		t.Errorf("Export() = %q, want the bookmark in it", content)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	c := newServer(t, auth.ScopeRead, auth.ScopeWrite).client
+	ctx := context.Background()
+
+	if _, err := c.Create(ctx, client.BookmarkInput{URL: "https://go.dev", Title: "Go"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		call   func() error
+		target error
+		status int
+		code   string
+	}{
+		{
+			name:   "not found",
+			call:   func() error { return c.Delete(ctx, 9999) },
+			target: client.ErrNotFound,
+			status: http.StatusNotFound,
+			code:   response.CodeBookmarkNotFound,
+		},
+		{
+			name: "exists",
+			call: func() error {
+				_, err := c.Create(ctx, client.BookmarkInput{URL: "https://go.dev", Title: "Go again"})
+				return err
+			},
+			target: client.ErrExists,
+			status: http.StatusConflict,
+			code:   response.CodeBookmarkExists,
+		},
+		{
+			name: "invalid",
+			call: func() error {
+				_, err := c.Create(ctx, client.BookmarkInput{URL: "not a url"})
+				return err
+			},
+			target: client.ErrInvalid,
+			status: http.StatusBadRequest,
+			code:   response.CodeValidationFailed,
+		},
+		{
+			name: "forbidden",
+			call: func() error {
+				_, err := c.Export(ctx)
+				return err
+			},
+			target: client.ErrForbidden,
+			status: http.StatusForbidden,
+			code:   response.CodeInsufficientScope,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+
+			var apiErr *client.Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want an *Error", err)
+			}
+			if apiErr.Status != tt.status || apiErr.Code != tt.code || apiErr.Detail == "" {
+				t.Errorf("error = %+v, want status %d and code %s with a detail", apiErr, tt.status, tt.code)
+			}
+			if !errors.Is(err, tt.target) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.target)
+			}
+		})
+	}
+
+	t.Run("fields", func(t *testing.T) {
+		_, err := c.Create(ctx, client.BookmarkInput{URL: "not a url"})
+
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) || len(apiErr.Fields) != 1 || apiErr.Fields[0].Field != "url" {
+			t.Errorf("error = %v, want the url field rejected", err)
+		}
+	})
+
+	t.Run("not found is not exists", func(t *testing.T) {
+		err := c.Delete(ctx, 9999)
+		if errors.Is(err, client.ErrExists) {
+			t.Errorf("errors.Is(%v, ErrExists) = true", err)
+		}
+	})
+}
+
+func TestUnauthorized(t *testing.T) {
+	ts := newServer(t, auth.ScopeRead)
+
+	c, err := client.New(client.Config{BaseURL: ts.url, Token: "bm_unknown"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = c.List(context.Background(), client.ListOptions{})
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("List() error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestRetryRateLimited(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"id":7,"found":true}}`))
+	}))
+	defer srv.Close()
+
+	c, err := client.New(client.Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	id, found, err := c.Exists(context.Background(), "https://go.dev")
+	if err != nil {
+		t.Fatalf("Exists() error = %v", err)
+	}
+	if id != 7 || !found {
+		t.Errorf("Exists() = %d, %v, want 7, true", id, found)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		// Retry-After is capped by MaxRetryAfter, the test doesn't wait a
+		// minute.
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c, err := client.New(client.Config{BaseURL: srv.URL, MaxRetries: 2, MaxRetryAfter: time.Millisecond})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, _, err = c.Exists(context.Background(), "https://go.dev")
+	if !errors.Is(err, client.ErrRateLimited) {
+		t.Errorf("Exists() error = %v, want ErrRateLimited", err)
+	}
+
+	var apiErr *client.Error
+	if errors.As(err, &apiErr) && apiErr.Detail != "Too Many Requests" {
+		t.Errorf("Exists() error detail = %q, want the plain text body", apiErr.Detail)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestRetryDisabled(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c, err := client.New(client.Config{BaseURL: srv.URL, MaxRetries: -1})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, _, err := c.Exists(context.Background(), "https://go.dev"); !errors.Is(err, client.ErrRateLimited) {
+		t.Errorf("Exists() error = %v, want ErrRateLimited", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/haadi-coder/bookmark-manager/internal/api/response"
+)
+
+// Errors mirroring the ones of the server's storage, matched with
+// errors.Is against the *Error returned by the client.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrExists       = errors.New("already exists")
+	ErrInvalid      = errors.New("invalid request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// FieldError is an invalid field of the request.
+type FieldError = response.FieldError
+
+// Error is an error response of the API.
+type Error struct {
+	// Status is the HTTP status of the response.
+	Status int
+	// Code identifies the problem, as in bookmark_not_found. It's empty for
+	// responses that aren't problem documents.
+	Code   string
+	Detail string
+	// Fields lists the invalid fields of rejected requests.
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("bookmark-manager: %d %s", e.Status, http.StatusText(e.Status))
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+
+	for _, f := range e.Fields {
+		msg += fmt.Sprintf(", %s %s", f.Field, f.Message)
+	}
+
+	return msg
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound && e.Code != response.CodeRouteNotFound
+	case ErrExists:
+		return e.Status == http.StatusConflict && strings.HasSuffix(e.Code, "_exists")
+	case ErrInvalid:
+		return e.Status == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.Status == http.StatusForbidden
+	case ErrRateLimited:
+		return e.Status == http.StatusTooManyRequests
+	}
+
+	return false
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{Status: resp.StatusCode}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == response.ProblemContentType {
+		var p response.Problem
+		if err := json.NewDecoder(resp.Body).Decode(&p); err == nil {
+			apiErr.Code = p.Code
+			apiErr.Detail = p.Detail
+			apiErr.Fields = p.Errors
+
+			return apiErr
+		}
+	}
+
+	// The rate limiter and proxies in front of the server answer in plain text.
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	apiErr.Detail = strings.TrimSpace(string(body))
+
+	return apiErr
+}